@@ -45,10 +45,17 @@ var (
 	hostname   = "127.0.0.1"
 	output     = "json"
 	port       = 9851
+	socket     string
 	oneCommand string
 	raw        bool
 	noprompt   bool
 	tty        bool
+	importKey  string
+	importFile string
+	exportKey  string
+	exportFile string
+	// the arguments that follow --import or --export
+	transferArgs []string
 )
 
 func showHelp() bool {
@@ -68,6 +75,11 @@ func showHelp() bool {
 	fmt.Fprintf(os.Stdout, " --json             Use JSON output formatting (default is JSON output)\n")
 	fmt.Fprintf(os.Stdout, " -h <hostname>      Server hostname (default: %s)\n", hostname)
 	fmt.Fprintf(os.Stdout, " -p <port>          Server port (default: %d)\n", port)
+	fmt.Fprintf(os.Stdout, " -s <socket>        Server socket (overrides hostname and port)\n")
+	fmt.Fprintf(os.Stdout, " --import <key> <file> [IDFIELD prop] [FIELDS field ...]\n")
+	fmt.Fprintf(os.Stdout, "                    Import a local GeoJSON, NDJSON or CSV file into a key\n")
+	fmt.Fprintf(os.Stdout, " --export <key> <file> [WHERE ...]\n")
+	fmt.Fprintf(os.Stdout, "                    Export a key to a local GeoJSON, NDJSON or CSV file\n")
 	fmt.Fprintf(os.Stdout, "\n")
 	return false
 }
@@ -125,9 +137,21 @@ func parseArgs() bool {
 				return badArg(arg)
 			}
 			port = int(n)
+		case "-s":
+			socket = readArg(arg)
+		case "--import":
+			importKey = readArg(arg)
+			importFile = readArg(arg)
+		case "--export":
+			exportKey = readArg(arg)
+			exportFile = readArg(arg)
 		}
 	}
 	oneCommand = strings.Join(args, " ")
+	if importKey != "" || exportKey != "" {
+		transferArgs = args
+		oneCommand = ""
+	}
 	return true
 }
 
@@ -159,11 +183,14 @@ func main() {
 		return
 	}
 
-	addr := fmt.Sprintf("%s:%d", hostname, port)
+	network, addr := "tcp", fmt.Sprintf("%s:%d", hostname, port)
+	if socket != "" {
+		network, addr = "unix", socket
+	}
 	var conn *client
 	connDial := func() {
 		var err error
-		conn, err = clientDial("tcp", addr)
+		conn, err = clientDial(network, addr)
 		if err != nil {
 			if _, ok := err.(net.Error); ok {
 				fmt.Fprintln(os.Stderr, refusedErrorString(addr))
@@ -180,6 +207,26 @@ func main() {
 		}
 	}
 	connDial()
+	if importKey != "" {
+		if conn == nil {
+			os.Exit(1)
+		}
+		if err := runImport(conn); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	if exportKey != "" {
+		if conn == nil {
+			os.Exit(1)
+		}
+		if err := runExport(conn); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 	monitor := false
 	livemode := false
 	aof := false
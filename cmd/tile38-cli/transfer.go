@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/geofile"
+)
+
+// transferBatchSize is the number of commands that are sent together, before
+// their replies are read, while importing or exporting.
+const transferBatchSize = 1000
+
+// maxTransferErrors is the number of per-line errors that are shown after an
+// import. Any errors beyond this are counted but not shown.
+const maxTransferErrors = 100
+
+// runImport reads a local file and sends its objects to the server as SET
+// commands, in batches. The file never has to be on the server.
+func runImport(conn *client) error {
+	format := geofile.Format(importFile)
+	if format == "" {
+		return errors.New("unknown file format")
+	}
+	opts := geofile.Options{Key: importKey}
+	for args := transferArgs; len(args) > 0; {
+		switch strings.ToLower(args[0]) {
+		case "idfield":
+			if len(args) < 2 || opts.IDField != "" {
+				return fmt.Errorf("invalid argument '%s'", args[0])
+			}
+			opts.IDField = args[1]
+			args = args[2:]
+		case "fields":
+			if len(args) < 2 {
+				return fmt.Errorf("invalid argument '%s'", args[0])
+			}
+			opts.Fields = append(opts.Fields, args[1:]...)
+			args = nil
+		default:
+			return fmt.Errorf("invalid argument '%s'", args[0])
+		}
+	}
+	f, err := os.Open(importFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := conn.Do("output resp"); err != nil {
+		return err
+	}
+	var imported, failed int
+	var errs []string
+	fail := func(line int, msg string) {
+		failed++
+		if len(errs) < maxTransferErrors {
+			errs = append(errs, fmt.Sprintf("line %d: %s", line, msg))
+		}
+	}
+	var batch []geofile.Record
+	flush := func() error {
+		var buf []byte
+		for _, rec := range batch {
+			buf = appendCommand(buf, rec.Args)
+		}
+		if _, err := conn.wr.Write(buf); err != nil {
+			return err
+		}
+		for _, rec := range batch {
+			msg, err := conn.readResp()
+			if err != nil {
+				return err
+			}
+			if msg[0] == '-' {
+				fail(rec.Line, strings.TrimPrefix(
+					strings.TrimSpace(string(msg[1:])), "ERR "))
+			} else {
+				imported++
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err = geofile.Read(f, format, &opts, func(rec geofile.Record) error {
+		if rec.Err != nil {
+			fail(rec.Line, rec.Err.Error())
+			return nil
+		}
+		batch = append(batch, rec)
+		if len(batch) == transferBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		data, _ := json.Marshal(errs)
+		if errs == nil {
+			data = []byte("[]")
+		}
+		fmt.Fprintf(os.Stdout, `{"ok":true,"imported":%d,"failed":%d,"errors":%s}`+"\n",
+			imported, failed, data)
+	} else {
+		fmt.Fprintln(os.Stdout, "imported "+strconv.Itoa(imported)+
+			", failed "+strconv.Itoa(failed))
+		for _, e := range errs {
+			fmt.Fprintln(os.Stdout, e)
+		}
+	}
+	return nil
+}
+
+// runExport pages through the objects of a key with SCAN and writes them to
+// a local file. Unlike the EXPORT command, the pages are not a snapshot of
+// the key at a single point in time.
+func runExport(conn *client) error {
+	format := geofile.Format(exportFile)
+	if format == "" {
+		return errors.New("unknown file format")
+	}
+	if _, err := conn.Do("output json"); err != nil {
+		return err
+	}
+	var items []geofile.Item
+	var fields []string
+	var cursor int64
+	for {
+		args := append([]string{"scan", exportKey}, transferArgs...)
+		args = append(args, "cursor", strconv.FormatInt(cursor, 10),
+			"limit", strconv.Itoa(transferBatchSize))
+		if _, err := conn.wr.Write(appendCommand(nil, args)); err != nil {
+			return err
+		}
+		msg, err := conn.readResp()
+		if err != nil {
+			return err
+		}
+		if msg[0] == '$' {
+			// the json is a bulk string
+			msg = msg[bytes.IndexByte(msg, '\n')+1:]
+		}
+		res := gjson.ParseBytes(msg)
+		if !res.Get("ok").Bool() {
+			return errors.New(res.Get("err").String())
+		}
+		if fields == nil {
+			fields = []string{}
+			res.Get("fields").ForEach(func(_, v gjson.Result) bool {
+				fields = append(fields, v.String())
+				return true
+			})
+		}
+		var perr error
+		res.Get("objects").ForEach(func(_, v gjson.Result) bool {
+			obj := v.Get("object")
+			if !obj.IsObject() {
+				// strings are not geographic
+				return true
+			}
+			o, err := geojson.Parse(obj.Raw, nil)
+			if err != nil {
+				perr = err
+				return false
+			}
+			item := geofile.Item{ID: v.Get("id").String(), Object: o}
+			v.Get("fields").ForEach(func(_, f gjson.Result) bool {
+				if len(item.Fields) < len(fields) {
+					item.Fields = append(item.Fields, f.Float())
+				}
+				return true
+			})
+			items = append(items, item)
+			return true
+		})
+		if perr != nil {
+			return perr
+		}
+		if cursor = res.Get("cursor").Int(); cursor == 0 {
+			break
+		}
+	}
+	f, err := os.Create(exportFile)
+	if err != nil {
+		return err
+	}
+	if err := geofile.Write(f, format, items, fields); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if output == "json" {
+		fmt.Fprintf(os.Stdout, `{"ok":true,"exported":%d}`+"\n", len(items))
+	} else {
+		fmt.Fprintln(os.Stdout, "exported "+strconv.Itoa(len(items)))
+	}
+	return nil
+}
+
+// appendCommand appends a command to a buffer as a RESP array.
+func appendCommand(buf []byte, args []string) []byte {
+	vals := make([]resp.Value, len(args))
+	for i, arg := range args {
+		vals[i] = resp.StringValue(arg)
+	}
+	data, _ := resp.ArrayValue(vals).MarshalRESP()
+	return append(buf, data...)
+}
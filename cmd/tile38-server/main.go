@@ -89,6 +89,7 @@ Basic Options:
   -h hostname : listening host
   -p port     : listening port (default: 9851)
   -d path     : data directory (default: data)
+  -s path     : unix socket path (default: disabled)
   -q          : no logging. totally silent output
   -v          : enable verbose logging
   -vv         : enable very verbose logging
@@ -101,6 +102,9 @@ Advanced Options:
   --http-transport yes/no : HTTP transport (default: yes)
   --protected-mode yes/no : protected mode (default: yes)
   --threads num           : number of network threads (default: num cores)
+  --rest-port port        : REST API port (default: disabled)
+  --grpc-port port        : gRPC API port (default: disabled)
+  --unixsocketperm perm   : unix socket permissions (default: 700)
   --nohup                 : do not exit on SIGHUP
 
 Developer Options:
@@ -240,6 +244,45 @@ Developer Options:
 			}
 			fmt.Fprintf(os.Stderr, "http-transport must be 'yes' or 'no'\n")
 			os.Exit(1)
+		case "--rest-port", "-rest-port":
+			i++
+			if i < len(os.Args) {
+				n, err := strconv.ParseUint(os.Args[i], 10, 16)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "rest-port must be a valid port\n")
+					os.Exit(1)
+				}
+				core.RESTPort = int(n)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "rest-port must be a valid port\n")
+			os.Exit(1)
+		case "--grpc-port", "-grpc-port":
+			i++
+			if i < len(os.Args) {
+				n, err := strconv.ParseUint(os.Args[i], 10, 16)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "grpc-port must be a valid port\n")
+					os.Exit(1)
+				}
+				core.GRPCPort = int(n)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "grpc-port must be a valid port\n")
+			os.Exit(1)
+		case "--unixsocketperm", "-unixsocketperm":
+			i++
+			if i < len(os.Args) {
+				n, err := strconv.ParseUint(os.Args[i], 8, 32)
+				if err != nil || n > 0777 {
+					fmt.Fprintf(os.Stderr, "unixsocketperm must be octal permission bits\n")
+					os.Exit(1)
+				}
+				core.UnixSocketPerm = os.FileMode(n)
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "unixsocketperm must be octal permission bits\n")
+			os.Exit(1)
 		case "--evio", "-evio":
 			i++
 			if i < len(os.Args) {
@@ -260,6 +303,7 @@ Developer Options:
 	flag.StringVar(&pidfile, "pidfile", "", "A file that contains the pid")
 	flag.StringVar(&host, "h", "", "The listening host.")
 	flag.StringVar(&dir, "d", "data", "The data directory.")
+	flag.StringVar(&core.UnixSocket, "s", "", "The unix socket path.")
 	flag.BoolVar(&verbose, "v", false, "Enable verbose logging.")
 	flag.BoolVar(&quiet, "q", false, "Quiet logging. Totally silent.")
 	flag.BoolVar(&veryVerbose, "vv", false, "Enable very verbose logging.")
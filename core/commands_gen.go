@@ -202,6 +202,20 @@ var commandsJSON = `{
             "name": "XX"
           }
 		]
+      },
+      {
+        "command": "IFNEWER",
+        "name": ["field"],
+        "type": ["string"],
+        "optional": true,
+        "multiple": true
+      },
+      {
+        "command": "IFGT",
+        "name": ["field", "value"],
+        "type": ["string", "double"],
+        "optional": true,
+        "multiple": true
       },
 	  {
 		"name": "value",
@@ -348,6 +362,13 @@ var commandsJSON = `{
         "type": [],
         "optional": true
       },
+      {
+        "command": "IF",
+        "name": ["field","op","value"],
+        "type": ["string","string","double"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "name": ["field","value"],
         "type": ["string","double"]
@@ -443,11 +464,191 @@ var commandsJSON = `{
       {
         "name": "key",
         "type": "string"
+      },
+      {
+        "command": "ASYNC",
+        "name": [],
+        "type": [],
+        "optional": true
       }
     ],
     "since": "1.0.0",
     "group": "keys"
   },
+  "CREATEINDEX": {
+    "summary": "Create an index over the values of a field in a key",
+    "complexity": "O(N) where N is the number of ids in the key",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "name": "field",
+        "type": "string"
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
+  "DROPINDEX": {
+    "summary": "Remove a field index from a key",
+    "complexity": "O(1)",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "name": "field",
+        "type": "string"
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
+  "IMPORT": {
+    "summary": "Load the objects of a GeoJSON, NDJSON or CSV file in the files directory into a key",
+    "complexity": "O(N) where N is the number of objects in the file",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "command": "FROM",
+        "name": "path",
+        "type": "string"
+      },
+      {
+        "command": "IDFIELD",
+        "name": "property",
+        "type": "string",
+        "optional": true
+      },
+      {
+        "command": "FIELDS",
+        "name": ["field"],
+        "type": ["string"],
+        "optional": true,
+        "variadic": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
+  "EXPORT": {
+    "summary": "Write the objects of a key to a GeoJSON, NDJSON or CSV file in the files directory",
+    "complexity": "O(N) where N is the number of ids in the key",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "command": "TO",
+        "name": "path",
+        "type": "string"
+      },
+      {
+        "command": "FORMAT",
+        "name": "format",
+        "type": "string"
+      },
+      {
+        "command": "MATCH",
+        "name": "pattern",
+        "type": "pattern",
+        "optional": true
+      },
+      {
+        "command": "WHERE",
+        "name": ["field","min","max"],
+        "type": ["string","double","double"],
+        "optional": true,
+        "multiple": true
+      },
+      {
+        "command": "WHEREIN",
+        "name": ["field","count","value"],
+        "type": ["string","integer","double"],
+        "optional": true,
+        "multiple": true,
+        "variadic": true
+      },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
+      {
+        "command": "WHEREEVAL",
+        "name": ["script","numargs","arg"],
+        "type": ["string","integer","string"],
+        "optional": true,
+        "multiple": true,
+        "variadic": true
+      },
+      {
+        "command": "WHEREEVALSHA",
+        "name": ["sha1","numargs","arg"],
+        "type": ["string","integer","string"],
+        "optional": true,
+        "multiple": true,
+        "variadic": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
+  "KEYCONFIG": {
+    "summary": "Set or get the configuration of a key",
+    "complexity": "O(1)",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "name": "action",
+        "enumargs": [
+          {
+            "name": "SET",
+            "arguments": [
+              {
+                "name": ["parameter", "value"],
+                "type": ["string", "string"],
+                "multiple": true
+              }
+            ]
+          },
+          {
+            "name": "GET"
+          }
+        ]
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
+  "HISTORY": {
+    "summary": "Get the previous objects of an id, for a key with history",
+    "complexity": "O(1)",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "name": "id",
+        "type": "string"
+      }
+    ],
+    "since": "1.23.0",
+    "group": "keys"
+  },
   "RENAME": {
     "summary": "Rename a key to be stored under a different name.",
     "complexity": "O(1)",
@@ -558,6 +759,13 @@ var commandsJSON = `{
         "multiple": true,
         "variadic": true
       },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "command": "WHEREEVAL",
         "name": ["script","numargs","arg"],
@@ -649,6 +857,13 @@ var commandsJSON = `{
         "multiple": true,
         "variadic": true
       },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "command": "WHEREEVAL",
         "name": ["script","numargs","arg"],
@@ -671,6 +886,12 @@ var commandsJSON = `{
         "type": [],
         "optional": true
       },
+      {
+        "command": "ORDER BY",
+        "name": "field",
+        "type": "string",
+        "optional": true
+      },
       {
         "name": "type",
         "optional": true,
@@ -758,6 +979,13 @@ var commandsJSON = `{
         "multiple": true,
         "variadic": true
       },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "command": "WHEREEVAL",
         "name": ["script","numargs","arg"],
@@ -918,6 +1146,13 @@ var commandsJSON = `{
         "multiple": true,
         "variadic": true
       },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "command": "WHEREEVAL",
         "name": ["script","numargs","arg"],
@@ -940,6 +1175,24 @@ var commandsJSON = `{
         "type": [],
         "optional": true
       },
+      {
+        "command": "ORDER BY",
+        "name": "field",
+        "type": "string",
+        "optional": true
+      },
+      {
+        "name": "order",
+        "optional": true,
+        "enumargs": [
+          {
+            "name": "ASC"
+          },
+          {
+            "name": "DESC"
+          }
+        ]
+      },
       {
         "command": "FENCE",
         "name": [],
@@ -1087,6 +1340,12 @@ var commandsJSON = `{
             ]
           }
         ]
+      },
+      {
+        "command": "BUFFER",
+        "name": "meters",
+        "type": "double",
+        "optional": true
       }
     ],
     "since": "1.0.0",
@@ -1139,6 +1398,13 @@ var commandsJSON = `{
         "multiple": true,
         "variadic": true
       },
+      {
+        "command": "WHEREJSON",
+        "name": ["path","op","value"],
+        "type": ["string","string","string"],
+        "optional": true,
+        "multiple": true
+      },
       {
         "command": "WHEREEVAL",
         "name": ["script","numargs","arg"],
@@ -1167,6 +1433,24 @@ var commandsJSON = `{
         "type": [],
         "optional": true
       },
+      {
+        "command": "ORDER BY",
+        "name": "field",
+        "type": "string",
+        "optional": true
+      },
+      {
+        "name": "order",
+        "optional": true,
+        "enumargs": [
+          {
+            "name": "ASC"
+          },
+          {
+            "name": "DESC"
+          }
+        ]
+      },
       {
         "command": "FENCE",
         "name": [],
@@ -1314,6 +1598,12 @@ var commandsJSON = `{
             ]
           }
         ]
+      },
+      {
+        "command": "BUFFER",
+        "name": "meters",
+        "type": "double",
+        "optional": true
       }
     ],
     "since": "1.0.0",
@@ -1377,7 +1667,14 @@ var commandsJSON = `{
   "FLUSHDB": {
     "summary":"Removes all keys",
     "complexity": "O(1)",
-    "arguments": [],
+    "arguments": [
+      {
+        "command": "ASYNC",
+        "name": [],
+        "type": [],
+        "optional": true
+      }
+    ],
     "since": "1.0.0",
     "group": "server"
   },
@@ -1447,6 +1744,31 @@ var commandsJSON = `{
     ],
     "group": "connection"
   },
+  "HELLO": {
+    "summary": "Handshakes with the server and switches to the RESP2 or RESP3 protocol",
+    "arguments": [
+      {
+        "name": "protover",
+        "type": "integer",
+        "optional": true
+      },
+      {
+        "command": "AUTH",
+        "name": ["username", "password"],
+        "type": ["string", "string"],
+        "optional": true,
+        "multiple": false
+      },
+      {
+        "command": "SETNAME",
+        "name": ["clientname"],
+        "type": ["string"],
+        "optional": true,
+        "multiple": false
+      }
+    ],
+    "group": "connection"
+  },
   "OUTPUT": {
     "summary": "Gets or sets the output format for the current connection.",
     "arguments": [
@@ -1510,6 +1832,30 @@ var commandsJSON = `{
         "optional": true,
         "multiple": false
       },
+      {
+        "name": "transform",
+        "optional": true,
+        "enumargs": [
+          {
+            "name": "FUNCTION",
+            "arguments": [
+              {
+                "name": "function",
+                "type": "string"
+              }
+            ]
+          },
+          {
+            "name": "SCRIPT",
+            "arguments": [
+              {
+                "name": "sha1",
+                "type": "string"
+              }
+            ]
+          }
+        ]
+      },
       {
         "enum": ["NEARBY", "WITHIN", "INTERSECTS"]
       },
@@ -1595,6 +1941,30 @@ var commandsJSON = `{
         "optional": true,
         "multiple": false
       },
+      {
+        "name": "transform",
+        "optional": true,
+        "enumargs": [
+          {
+            "name": "FUNCTION",
+            "arguments": [
+              {
+                "name": "function",
+                "type": "string"
+              }
+            ]
+          },
+          {
+            "name": "SCRIPT",
+            "arguments": [
+              {
+                "name": "sha1",
+                "type": "string"
+              }
+            ]
+          }
+        ]
+      },
       {
         "enum": ["NEARBY", "WITHIN", "INTERSECTS"]
       },
@@ -1773,6 +2143,56 @@ var commandsJSON = `{
     ],
     "group": "keys"
   },
+  "MULTI": {
+    "summary": "Marks the start of a transaction block",
+    "complexity": "O(1)",
+    "arguments": [],
+    "since": "1.23.0",
+    "group": "transactions"
+  },
+  "EXEC": {
+    "summary": "Executes all commands issued after MULTI",
+    "complexity": "Depends on the queued commands",
+    "arguments": [],
+    "since": "1.23.0",
+    "group": "transactions"
+  },
+  "DISCARD": {
+    "summary": "Discards all commands issued after MULTI",
+    "complexity": "O(N) where N is the number of queued commands",
+    "arguments": [],
+    "since": "1.23.0",
+    "group": "transactions"
+  },
+  "WATCH": {
+    "summary": "Watches objects to determine execution of the MULTI/EXEC block",
+    "complexity": "O(1) for every object",
+    "arguments": [
+      {
+        "name": "key",
+        "type": "string"
+      },
+      {
+        "name": "id",
+        "type": "string"
+      },
+      {
+        "name": ["key", "id"],
+        "type": ["string", "string"],
+        "optional": true,
+        "multiple": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "transactions"
+  },
+  "UNWATCH": {
+    "summary": "Forgets about all watched objects",
+    "complexity": "O(1)",
+    "arguments": [],
+    "since": "1.23.0",
+    "group": "transactions"
+  },
   "EVAL":{
     "summary": "Evaluates a Lua script",
     "complexity": "Depends on the evaluated script",
@@ -1972,6 +2392,151 @@ var commandsJSON = `{
     "since": "1.10.0",
     "group": "scripting"
   },
+  "FUNCTION LOAD":{
+    "summary": "Loads a library of Lua functions that is persisted in the AOF",
+    "complexity": "O(N) where N is the number of bytes in the library",
+    "arguments": [
+      {
+        "command": "REPLACE",
+        "name": [],
+        "type": [],
+        "optional": true
+      },
+      {
+        "name": "code",
+        "type": "string"
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FUNCTION LIST":{
+    "summary": "Lists the libraries and their functions",
+    "complexity": "O(N) where N is the number of functions",
+    "arguments": [
+      {
+        "command": "LIBRARYNAME",
+        "name": ["pattern"],
+        "type": ["pattern"],
+        "optional": true
+      },
+      {
+        "command": "WITHCODE",
+        "name": [],
+        "type": [],
+        "optional": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FUNCTION DELETE":{
+    "summary": "Deletes a library and its functions",
+    "complexity": "O(1)",
+    "arguments": [
+      {
+        "name": "library",
+        "type": "string"
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FUNCTION FLUSH":{
+    "summary": "Deletes all libraries",
+    "complexity": "O(N) where N is the number of functions",
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FUNCTION DUMP":{
+    "summary": "Returns a payload with all libraries",
+    "complexity": "O(N) where N is the number of libraries",
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FUNCTION RESTORE":{
+    "summary": "Restores the libraries from a payload of FUNCTION DUMP",
+    "complexity": "O(N) where N is the number of libraries",
+    "arguments": [
+      {
+        "name": "payload",
+        "type": "string"
+      },
+      {
+        "name": "policy",
+        "optional": true,
+        "enumargs": [
+          {
+            "name": "FLUSH"
+          },
+          {
+            "name": "APPEND"
+          },
+          {
+            "name": "REPLACE"
+          }
+        ]
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FCALL":{
+    "summary": "Calls a function of a loaded library",
+    "complexity": "Depends on the function that is executed",
+    "arguments": [
+      {
+        "name": "function",
+        "type": "string"
+      },
+      {
+        "name": "numkeys",
+        "type": "integer"
+      },
+      {
+        "name": "key",
+        "type": "string",
+        "optional": true,
+        "multiple": true
+      },
+      {
+        "name": "arg",
+        "type": "string",
+        "optional": true,
+        "multiple": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
+  "FCALL_RO":{
+    "summary": "Calls a read-only function of a loaded library",
+    "complexity": "Depends on the function that is executed",
+    "arguments": [
+      {
+        "name": "function",
+        "type": "string"
+      },
+      {
+        "name": "numkeys",
+        "type": "integer"
+      },
+      {
+        "name": "key",
+        "type": "string",
+        "optional": true,
+        "multiple": true
+      },
+      {
+        "name": "arg",
+        "type": "string",
+        "optional": true,
+        "multiple": true
+      }
+    ],
+    "since": "1.23.0",
+    "group": "scripting"
+  },
   "TEST":{
     "summary": "Performs spatial test",
     "complexity": "One test per command, complexity depends on the test",
@@ -2089,6 +2654,12 @@ var commandsJSON = `{
           }
         ]
       },
+      {
+        "command": "BUFFER",
+        "name": "meters",
+        "type": "double",
+        "optional": true
+      },
       {
         "name": "test",
         "enumargs": [
@@ -2218,6 +2789,12 @@ var commandsJSON = `{
             ]
           }
         ]
+      },
+      {
+        "command": "BUFFER",
+        "name": "meters",
+        "type": "double",
+        "optional": true
       }
     ],
     "since": "1.16.0",
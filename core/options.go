@@ -1,5 +1,7 @@
 package core
 
+import "os"
+
 // DevMode puts application in to dev mode
 var DevMode = false
 
@@ -20,3 +22,16 @@ var QueueFileName = ""
 
 // NumThreads is the number of network threads to use.
 var NumThreads int
+
+// RESTPort is the port of the REST API. Zero disables the REST API.
+var RESTPort int
+
+// GRPCPort is the port of the gRPC API. Zero disables the gRPC API.
+var GRPCPort int
+
+// UnixSocket is the path of the Unix domain socket to listen on, in addition
+// to the TCP port. Empty disables the socket.
+var UnixSocket = ""
+
+// UnixSocketPerm is the file mode of the Unix domain socket.
+var UnixSocketPerm os.FileMode = 0700
@@ -30,5 +30,6 @@ require (
 	golang.org/x/crypto v0.0.0-20201221181555-eec23a3978ad
 	golang.org/x/net v0.0.0-20210119194325-5f4716e94777
 	google.golang.org/grpc v1.35.0
+	google.golang.org/protobuf v1.25.0
 	layeh.com/gopher-json v0.0.0-20201124131017-552bb3c4c3bf
 )
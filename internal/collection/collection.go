@@ -2,6 +2,8 @@ package collection
 
 import (
 	"runtime"
+	"sync/atomic"
+	"time"
 
 	"github.com/tidwall/btree"
 	"github.com/tidwall/geoindex"
@@ -23,8 +25,13 @@ type Cursor interface {
 }
 
 type itemT struct {
-	id  string
-	obj geojson.Object
+	id     string
+	obj    geojson.Object
+	access int64 // last read or write, unix nanoseconds
+}
+
+func (item *itemT) touch() {
+	atomic.StoreInt64(&item.access, time.Now().UnixNano())
 }
 
 func byID(a, b interface{}) bool {
@@ -46,16 +53,18 @@ func byValue(a, b interface{}) bool {
 
 // Collection represents a collection of geojson objects.
 type Collection struct {
-	items       *btree.BTree    // items sorted by keys
-	index       *geoindex.Index // items geospatially indexed
-	values      *btree.BTree    // items sorted by value+key
-	fieldMap    map[string]int
-	fieldArr    []string
-	fieldValues map[string][]float64
-	weight      int
-	points      int
-	objects     int // geometry count
-	nobjects    int // non-geometry count
+	items        *btree.BTree    // items sorted by keys
+	index        *geoindex.Index // items geospatially indexed
+	values       *btree.BTree    // items sorted by value+key
+	fieldMap     map[string]int
+	fieldArr     []string
+	fieldValues  map[string][]float64
+	fieldIndexes map[string]*btree.BTree // field values sorted by value+key
+	weight       int
+	points       int
+	objects      int    // geometry count
+	nobjects     int    // non-geometry count
+	evictNext    string // where the next eviction sample starts
 }
 
 var counter uint64
@@ -161,12 +170,13 @@ func (c *Collection) Set(
 ) (
 	oldObject geojson.Object, oldFields []float64, newFields []float64,
 ) {
-	newItem := &itemT{id: id, obj: obj}
+	newItem := &itemT{id: id, obj: obj, access: time.Now().UnixNano()}
 
 	// add the new item to main btree and remove the old one if needed
 	oldItem := c.items.Set(newItem)
 	if oldItem != nil {
 		oldItem := oldItem.(*itemT)
+		c.fieldIndexDelete(oldItem, c.getFieldValues(id))
 		// the old item was removed, now let's remove it from the rtree/btree.
 		if objIsSpatial(oldItem.obj) {
 			c.indexDelete(oldItem)
@@ -217,6 +227,7 @@ func (c *Collection) Set(
 		}
 		newFields = c.getFieldValues(id)
 	}
+	c.fieldIndexInsert(newItem, newFields)
 	return oldObject, oldFields, newFields
 }
 
@@ -243,6 +254,7 @@ func (c *Collection) Delete(id string) (
 	c.points -= oldItem.obj.NumPoints()
 
 	fields = c.getFieldValues(id)
+	c.fieldIndexDelete(oldItem, fields)
 	c.deleteFieldValues(id)
 	return oldItem.obj, fields, true
 }
@@ -257,9 +269,60 @@ func (c *Collection) Get(id string) (
 		return nil, nil, false
 	}
 	item := itemV.(*itemT)
+	item.touch()
 	return item.obj, c.getFieldValues(id), true
 }
 
+// Access returns when an object was last read with Get or written, in unix
+// nanoseconds.
+// If the object does not exist then the 'ok' return value will be false.
+func (c *Collection) Access(id string) (access int64, ok bool) {
+	itemV := c.items.Get(&itemT{id: id})
+	if itemV == nil {
+		return 0, false
+	}
+	return atomic.LoadInt64(&itemV.(*itemT).access), true
+}
+
+// EvictionSample returns the least recently accessed object of a sample of
+// up to n objects. The objects are sampled in id order, starting where the
+// previous sample ended, so that the whole collection is sampled over time.
+// If the collection is empty then the 'ok' return value will be false.
+func (c *Collection) EvictionSample(n int) (
+	id string, access int64, ok bool,
+) {
+	var count int
+	var last string
+	iter := func(itemV interface{}) bool {
+		item := itemV.(*itemT)
+		itemAccess := atomic.LoadInt64(&item.access)
+		if !ok || itemAccess < access {
+			id, access, ok = item.id, itemAccess, true
+		}
+		last = item.id
+		count++
+		return count < n
+	}
+	start := c.evictNext
+	if start != "" {
+		c.items.Ascend(&itemT{id: start}, iter)
+	}
+	if count < n {
+		// wrap around to the first object
+		c.items.Ascend(nil, func(itemV interface{}) bool {
+			if start != "" && itemV.(*itemT).id >= start {
+				return false
+			}
+			return iter(itemV)
+		})
+	}
+	if ok {
+		// the smallest id that is greater than the last one
+		c.evictNext = last + "\x00"
+	}
+	return id, access, ok
+}
+
 // SetField set a field value for an object and returns that object.
 // If the object does not exist then the 'ok' return value will be false.
 func (c *Collection) SetField(id, field string, value float64) (
@@ -270,7 +333,10 @@ func (c *Collection) SetField(id, field string, value float64) (
 		return nil, nil, false, false
 	}
 	item := itemV.(*itemT)
+	item.touch()
+	c.fieldIndexDelete(item, c.getFieldValues(id))
 	updated = c.setField(item, field, value)
+	c.fieldIndexInsert(item, c.getFieldValues(id))
 	return item.obj, c.getFieldValues(id), updated, true
 }
 
@@ -283,11 +349,14 @@ func (c *Collection) SetFields(
 		return nil, nil, 0, false
 	}
 	item := itemV.(*itemT)
+	item.touch()
+	c.fieldIndexDelete(item, c.getFieldValues(id))
 	for idx, field := range inFields {
 		if c.setField(item, field, inValues[idx]) {
 			updatedCount++
 		}
 	}
+	c.fieldIndexInsert(item, c.getFieldValues(id))
 	return item.obj, c.getFieldValues(id), updatedCount, true
 }
 
@@ -396,6 +396,39 @@ func TestCollectionWeight(t *testing.T) {
 	expect(t, c.TotalWeight() == 0)
 }
 
+func TestCollectionEvictionSample(t *testing.T) {
+	c := New()
+	_, _, ok := c.EvictionSample(5)
+	expect(t, !ok)
+	for i := 0; i < 10; i++ {
+		c.Set(strconv.Itoa(i), String("v"), nil, nil)
+	}
+	// make "3" and then "7" the least recently accessed objects
+	for i := 0; i < 10; i++ {
+		item := c.items.Get(&itemT{id: strconv.Itoa(i)}).(*itemT)
+		item.access = int64(100 + i)
+	}
+	c.items.Get(&itemT{id: "3"}).(*itemT).access = 1
+	c.items.Get(&itemT{id: "7"}).(*itemT).access = 2
+
+	// the samples continue where the previous one ended
+	id, access, ok := c.EvictionSample(5)
+	expect(t, ok && id == "3" && access == 1)
+	id, access, ok = c.EvictionSample(5)
+	expect(t, ok && id == "7" && access == 2)
+	id, _, ok = c.EvictionSample(5)
+	expect(t, ok && id == "3")
+
+	// reading an object makes it the most recently accessed
+	c.Get("3")
+	access, ok = c.Access("3")
+	expect(t, ok && access > 200)
+	id, _, ok = c.EvictionSample(20)
+	expect(t, ok && id == "7")
+	_, ok = c.Access("missing")
+	expect(t, !ok)
+}
+
 func TestSpatialSearch(t *testing.T) {
 	json := `
 		{"type":"FeatureCollection","features":[
@@ -583,6 +616,50 @@ func TestCollectionSparse(t *testing.T) {
 
 }
 
+func TestCollectionIndex(t *testing.T) {
+	c := New()
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("%03d", i)
+		c.Set(id, PO(float64(i), float64(i)), []string{"speed"},
+			[]float64{float64(i % 10)})
+	}
+	expect(t, c.CreateIndex("speed"))
+	expect(t, !c.CreateIndex("speed"))
+	expect(t, c.CreateIndex("battery"))
+	expect(t, reflect.DeepEqual(c.Indexes(), []string{"battery", "speed"}))
+	rangeIDs := func(field string, min, max float64) []string {
+		var ids []string
+		c.IndexRange(field, min, max, nil,
+			func(id string, obj geojson.Object, fields []float64) bool {
+				ids = append(ids, id)
+				return true
+			},
+		)
+		return ids
+	}
+	expect(t, len(rangeIDs("speed", 3, 4)) == 20)
+	expect(t, len(rangeIDs("battery", 0, 0)) == 100)
+
+	// updates move the object within the index
+	c.SetField("003", "speed", 100)
+	c.SetFields("013", []string{"speed", "battery"}, []float64{100, 50})
+	c.Set("023", PO(1, 1), []string{"speed"}, []float64{100})
+	c.Delete("033")
+	expect(t, reflect.DeepEqual(rangeIDs("speed", 100, 100),
+		[]string{"003", "013", "023"}))
+	expect(t, len(rangeIDs("speed", 3, 3)) == 6)
+	expect(t, reflect.DeepEqual(rangeIDs("battery", 1, 100), []string{"013"}))
+
+	// a replaced object keeps its fields
+	c.Set("013", PO(2, 2), nil, nil)
+	expect(t, reflect.DeepEqual(rangeIDs("battery", 1, 100), []string{"013"}))
+
+	expect(t, c.DropIndex("battery"))
+	expect(t, !c.DropIndex("battery"))
+	expect(t, !c.HasIndex("battery"))
+	expect(t, c.HasIndex("speed"))
+}
+
 func testCollectionVerifyContents(t *testing.T, c *Collection, objs map[string]geojson.Object) {
 	for id, o2 := range objs {
 		o1, _, ok := c.Get(id)
@@ -0,0 +1,125 @@
+package collection
+
+import (
+	"sort"
+
+	"github.com/tidwall/btree"
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/tile38/internal/deadline"
+)
+
+// fieldItemT is an entry in a field index. Every object in the collection
+// has an entry in each index, objects without the field are indexed as zero.
+type fieldItemT struct {
+	value float64
+	item  *itemT
+}
+
+func byFieldValue(a, b interface{}) bool {
+	ia, ib := a.(*fieldItemT), b.(*fieldItemT)
+	if ia.value < ib.value {
+		return true
+	}
+	if ia.value > ib.value {
+		return false
+	}
+	return ia.item.id < ib.item.id
+}
+
+func (c *Collection) fieldValue(field string, fields []float64) float64 {
+	if idx, ok := c.fieldMap[field]; ok && idx < len(fields) {
+		return fields[idx]
+	}
+	return 0
+}
+
+// fieldIndexInsert adds the item to every field index.
+func (c *Collection) fieldIndexInsert(item *itemT, fields []float64) {
+	for field, tr := range c.fieldIndexes {
+		tr.Set(&fieldItemT{value: c.fieldValue(field, fields), item: item})
+	}
+}
+
+// fieldIndexDelete removes the item from every field index. The fields must
+// be the values that the item was indexed with.
+func (c *Collection) fieldIndexDelete(item *itemT, fields []float64) {
+	for field, tr := range c.fieldIndexes {
+		tr.Delete(&fieldItemT{value: c.fieldValue(field, fields), item: item})
+	}
+}
+
+// CreateIndex builds an index over the values of a field. Returns false if
+// the field is already indexed.
+func (c *Collection) CreateIndex(field string) bool {
+	if _, ok := c.fieldIndexes[field]; ok {
+		return false
+	}
+	tr := btree.New(byFieldValue)
+	c.items.Ascend(nil, func(v interface{}) bool {
+		item := v.(*itemT)
+		tr.Set(&fieldItemT{
+			value: c.fieldValue(field, c.getFieldValues(item.id)),
+			item:  item,
+		})
+		return true
+	})
+	if c.fieldIndexes == nil {
+		c.fieldIndexes = make(map[string]*btree.BTree)
+	}
+	c.fieldIndexes[field] = tr
+	return true
+}
+
+// DropIndex removes a field index. Returns false if the field is not
+// indexed.
+func (c *Collection) DropIndex(field string) bool {
+	if _, ok := c.fieldIndexes[field]; !ok {
+		return false
+	}
+	delete(c.fieldIndexes, field)
+	return true
+}
+
+// HasIndex returns true if the field is indexed.
+func (c *Collection) HasIndex(field string) bool {
+	_, ok := c.fieldIndexes[field]
+	return ok
+}
+
+// Indexes returns the names of the indexed fields, sorted.
+func (c *Collection) Indexes() []string {
+	fields := make([]string, 0, len(c.fieldIndexes))
+	for field := range c.fieldIndexes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// IndexRange iterates over the objects with an indexed field value between
+// min and max, inclusive, in value order. The field must be indexed.
+func (c *Collection) IndexRange(
+	field string, min, max float64,
+	deadline *deadline.Deadline,
+	iterator func(id string, obj geojson.Object, fields []float64) bool,
+) bool {
+	var keepon = true
+	var count uint64
+	tr := c.fieldIndexes[field]
+	if tr == nil {
+		return keepon
+	}
+	pivot := &fieldItemT{value: min, item: &itemT{}}
+	tr.Ascend(pivot, func(v interface{}) bool {
+		fitm := v.(*fieldItemT)
+		if fitm.value > max {
+			return false
+		}
+		count++
+		nextStep(count, nil, deadline)
+		keepon = iterator(fitm.item.id, fitm.item.obj,
+			c.getFieldValues(fitm.item.id))
+		return keepon
+	})
+	return keepon
+}
@@ -1,11 +1,16 @@
 package deadline
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
-// Deadline allows for commands to expire when they run too long
+// Deadline allows for commands to expire when they run too long, or to be
+// canceled. The zero value has no time limit and only ends when canceled.
 type Deadline struct {
 	unixNano int64
 	hit      bool
+	canceled int32
 }
 
 // New returns a new deadline object
@@ -16,7 +21,14 @@ func New(dl time.Time) *Deadline {
 // Check the deadline and panic when reached
 //go:noinline
 func (dl *Deadline) Check() {
-	if dl == nil || dl.unixNano == 0 {
+	if dl == nil {
+		return
+	}
+	if !dl.hit && atomic.LoadInt32(&dl.canceled) != 0 {
+		dl.hit = true
+		panic("deadline")
+	}
+	if dl.unixNano == 0 {
 		return
 	}
 	if !dl.hit && time.Now().UnixNano() > dl.unixNano {
@@ -30,6 +42,16 @@ func (dl *Deadline) Hit() bool {
 	return dl.hit
 }
 
+// Cancel makes the next Check panic. It's safe to call from any goroutine.
+func (dl *Deadline) Cancel() {
+	atomic.StoreInt32(&dl.canceled, 1)
+}
+
+// Canceled returns true if the deadline has been canceled
+func (dl *Deadline) Canceled() bool {
+	return atomic.LoadInt32(&dl.canceled) != 0
+}
+
 // GetDeadlineTime returns the time object for the deadline, and an
 // "empty" boolean
 func (dl *Deadline) GetDeadlineTime() time.Time {
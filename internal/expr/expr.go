@@ -0,0 +1,213 @@
+// Package expr implements the predicate expressions that are used to filter
+// objects with WHERE.
+//
+// An expression is compiled once into a tree of closures and is then
+// evaluated against each candidate object. Identifiers are resolved by the
+// caller through an Env.
+//
+//	speed > 10 && (id == 'truck1' || battery < 20)
+//	!startsWith(id, 'bus') and heading in (0, 90, 180, 270)
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of a Value.
+type Kind int
+
+// Value kinds
+const (
+	Number Kind = iota
+	String
+	Bool
+)
+
+// Value is the result of evaluating an expression.
+type Value struct {
+	Kind Kind
+	Num  float64
+	Str  string
+	Bool bool
+}
+
+// NumberValue returns a number value.
+func NumberValue(n float64) Value { return Value{Kind: Number, Num: n} }
+
+// StringValue returns a string value.
+func StringValue(s string) Value { return Value{Kind: String, Str: s} }
+
+// BoolValue returns a boolean value.
+func BoolValue(b bool) Value { return Value{Kind: Bool, Bool: b} }
+
+// Truthy returns true for true, for non-zero numbers and for non-empty
+// strings.
+func (v Value) Truthy() bool {
+	switch v.Kind {
+	case Number:
+		return v.Num != 0
+	case String:
+		return v.Str != ""
+	default:
+		return v.Bool
+	}
+}
+
+// String returns the value as a string. Numbers are formatted as they are
+// in command output.
+func (v Value) String() string {
+	switch v.Kind {
+	case Number:
+		return strconv.FormatFloat(v.Num, 'f', -1, 64)
+	case String:
+		return v.Str
+	default:
+		return strconv.FormatBool(v.Bool)
+	}
+}
+
+// Env resolves the identifiers of an expression while it's evaluated.
+type Env interface {
+	Ident(name string) Value
+}
+
+type evalFunc func(env Env) Value
+
+// Expr is a compiled expression.
+type Expr struct {
+	src  string
+	eval evalFunc
+}
+
+// Compile parses an expression.
+func Compile(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	eval, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected()
+	}
+	return &Expr{src: src, eval: eval}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression.
+func (e *Expr) Eval(env Env) Value {
+	return e.eval(env)
+}
+
+// Match evaluates the expression and returns true if the result is truthy.
+func (e *Expr) Match(env Env) bool {
+	return e.eval(env).Truthy()
+}
+
+// IsExpr returns true when a WHERE argument looks like an expression rather
+// than a field name.
+func IsExpr(s string) bool {
+	return strings.ContainsAny(s, " \t<>=!&|()'\"")
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokStr
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	num  float64
+	pos  int
+}
+
+func isIdentChar(ch byte, first bool) bool {
+	if ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') {
+		return true
+	}
+	return !first && (ch == '.' || (ch >= '0' && ch <= '9'))
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		ch := src[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n':
+			i++
+		case (ch >= '0' && ch <= '9') || (ch == '.' && i+1 < len(src) &&
+			src[i+1] >= '0' && src[i+1] <= '9'):
+			s := i
+			for i < len(src) && (src[i] == '.' || src[i] == 'e' ||
+				src[i] == 'E' || (src[i] >= '0' && src[i] <= '9') ||
+				((src[i] == '+' || src[i] == '-') &&
+					(src[i-1] == 'e' || src[i-1] == 'E'))) {
+				i++
+			}
+			n, err := strconv.ParseFloat(src[s:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d",
+					src[s:i], s+1)
+			}
+			toks = append(toks, token{kind: tokNum, text: src[s:i], num: n,
+				pos: s})
+		case ch == '\'' || ch == '"':
+			s := i
+			var str []byte
+			i++
+			for ; i < len(src) && src[i] != ch; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				str = append(str, src[i])
+			}
+			if i == len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d",
+					s+1)
+			}
+			i++
+			toks = append(toks, token{kind: tokStr, text: string(str), pos: s})
+		case isIdentChar(ch, true):
+			s := i
+			for i < len(src) && isIdentChar(src[i], false) {
+				i++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[s:i], pos: s})
+		default:
+			s := i
+			op := src[i : i+1]
+			if i+1 < len(src) {
+				switch src[i : i+2] {
+				case "&&", "||", "==", "!=", "<=", ">=":
+					op = src[i : i+2]
+				}
+			}
+			switch op {
+			case "&&", "||", "==", "!=", "<=", ">=",
+				"=", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")", ",":
+			default:
+				return nil, fmt.Errorf("unexpected '%s' at position %d",
+					op, s+1)
+			}
+			i += len(op)
+			toks = append(toks, token{kind: tokOp, text: op, pos: s})
+		}
+	}
+	toks = append(toks, token{kind: tokEOF, pos: len(src)})
+	return toks, nil
+}
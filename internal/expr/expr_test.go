@@ -0,0 +1,112 @@
+package expr
+
+import (
+	"testing"
+)
+
+type testEnv map[string]Value
+
+func (env testEnv) Ident(name string) Value {
+	if v, ok := env[name]; ok {
+		return v
+	}
+	return NumberValue(0)
+}
+
+func TestExpr(t *testing.T) {
+	env := testEnv{
+		"id":      StringValue("truck12"),
+		"speed":   NumberValue(42),
+		"battery": NumberValue(15),
+		"heading": NumberValue(90),
+	}
+	tests := []struct {
+		src    string
+		expect bool
+	}{
+		{"speed > 10", true},
+		{"speed > 10 && battery < 10", false},
+		{"speed > 10 && (id == 'bus1' || battery < 20)", true},
+		{"speed >= 42 and battery <= 15", true},
+		{"speed = 42", true},
+		{"speed != 42", false},
+		{"!(speed > 50)", true},
+		{"not speed > 50 or false", true},
+		{"heading in (0, 90, 180, 270)", true},
+		{"heading not in (0, 180)", true},
+		{"id in ('truck1', 'truck12')", true},
+		{"startsWith(id, 'truck') && !endsWith(id, '1')", true},
+		{"contains(upper(id), 'UCK')", true},
+		{"match(id, 'truck*')", true},
+		{"len(id) == 7", true},
+		{"speed * 2 - battery % 10 == 79", true},
+		{"-speed < 0", true},
+		{"abs(0 - speed) == speed", true},
+		{"missing == 0", true},
+		{"id == 12", false},
+		{"id != 12", true},
+		{"id > 'truck'", true},
+		{"id + '!' == \"truck12!\"", true},
+		{"speed", true},
+		{"missing", false},
+		{"1.5e1 == battery", true},
+	}
+	for _, test := range tests {
+		e, err := Compile(test.src)
+		if err != nil {
+			t.Fatalf("%s: %v", test.src, err)
+		}
+		if e.Match(env) != test.expect {
+			t.Fatalf("%s: expected %v", test.src, test.expect)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"", "unexpected end of expression"},
+		{"speed >", "unexpected end of expression"},
+		{"(speed > 10", "unexpected end of expression"},
+		{"speed > 10)", "unexpected ')' at position 11"},
+		{"speed # 10", "unexpected '#' at position 7"},
+		{"id == 'truck", "unterminated string at position 7"},
+		{"nope(id)", "unknown function 'nope' at position 1"},
+		{"lower(id, id)", "function 'lower' expects 1 argument(s)"},
+		{"speed not 10", "unexpected '10' at position 11"},
+		{"and", "unexpected 'and' at position 1"},
+	}
+	for _, test := range tests {
+		_, err := Compile(test.src)
+		if err == nil || err.Error() != test.err {
+			t.Fatalf("%s: expected error %q, got %v", test.src, test.err, err)
+		}
+	}
+}
+
+func TestIsExpr(t *testing.T) {
+	if IsExpr("speed") || IsExpr("properties.speed") {
+		t.Fatal("expected field name")
+	}
+	if !IsExpr("speed > 10") || !IsExpr("speed>10") || !IsExpr("!moving") {
+		t.Fatal("expected expression")
+	}
+}
+
+func BenchmarkExpr(b *testing.B) {
+	env := testEnv{
+		"id":      StringValue("truck12"),
+		"speed":   NumberValue(42),
+		"battery": NumberValue(15),
+	}
+	e, err := Compile("speed > 10 && (id == 'bus1' || battery < 20)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Match(env)
+	}
+}
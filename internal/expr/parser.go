@@ -0,0 +1,427 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tidwall/tile38/internal/glob"
+)
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// is returns true if the next token is the operator or the case-insensitive
+// keyword.
+func (p *parser) is(op, keyword string) bool {
+	tok := p.peek()
+	switch tok.kind {
+	case tokOp:
+		return op != "" && tok.text == op
+	case tokIdent:
+		return keyword != "" && strings.EqualFold(tok.text, keyword)
+	}
+	return false
+}
+
+func (p *parser) unexpected() error {
+	tok := p.peek()
+	if tok.kind == tokEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	text := tok.text
+	if tok.kind == tokStr {
+		text = "'" + text + "'"
+	}
+	return fmt.Errorf("unexpected '%s' at position %d", text, tok.pos+1)
+}
+
+func (p *parser) expect(op string) error {
+	if !p.is(op, "") {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+// or = and { ("||" | "or") and }
+func (p *parser) parseOr() (evalFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("||", "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env Env) Value {
+			return BoolValue(l(env).Truthy() || right(env).Truthy())
+		}
+	}
+	return left, nil
+}
+
+// and = not { ("&&" | "and") not }
+func (p *parser) parseAnd() (evalFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("&&", "and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env Env) Value {
+			return BoolValue(l(env).Truthy() && right(env).Truthy())
+		}
+	}
+	return left, nil
+}
+
+// not = ("!" | "not") not | cmp
+func (p *parser) parseNot() (evalFunc, error) {
+	if p.is("!", "not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(env Env) Value {
+			return BoolValue(!operand(env).Truthy())
+		}, nil
+	}
+	return p.parseCmp()
+}
+
+// cmp = sum [ op sum | ["not"] "in" "(" sum { "," sum } ")" ]
+func (p *parser) parseCmp() (evalFunc, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind == tokOp {
+		var cmp func(c int, ok bool) bool
+		switch tok.text {
+		case "==", "=":
+			cmp = func(c int, ok bool) bool { return ok && c == 0 }
+		case "!=":
+			cmp = func(c int, ok bool) bool { return !ok || c != 0 }
+		case "<":
+			cmp = func(c int, ok bool) bool { return ok && c < 0 }
+		case "<=":
+			cmp = func(c int, ok bool) bool { return ok && c <= 0 }
+		case ">":
+			cmp = func(c int, ok bool) bool { return ok && c > 0 }
+		case ">=":
+			cmp = func(c int, ok bool) bool { return ok && c >= 0 }
+		}
+		if cmp == nil {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return func(env Env) Value {
+			return BoolValue(cmp(compare(left(env), right(env))))
+		}, nil
+	}
+	negate := false
+	if p.is("", "not") {
+		negate = true
+		p.next()
+		if !p.is("", "in") {
+			return nil, p.unexpected()
+		}
+	}
+	if !p.is("", "in") {
+		return left, nil
+	}
+	p.next()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var list []evalFunc
+	for {
+		item, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+		if !p.is(",", "") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return func(env Env) Value {
+		v := left(env)
+		for _, item := range list {
+			if c, ok := compare(v, item(env)); ok && c == 0 {
+				return BoolValue(!negate)
+			}
+		}
+		return BoolValue(negate)
+	}, nil
+}
+
+// sum = term { ("+" | "-") term }
+func (p *parser) parseSum() (evalFunc, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("+", "") || p.is("-", "") {
+		op := p.next().text
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(env Env) Value {
+				a, b := l(env), right(env)
+				if a.Kind == String || b.Kind == String {
+					return StringValue(a.String() + b.String())
+				}
+				return NumberValue(toNumber(a) + toNumber(b))
+			}
+		} else {
+			left = func(env Env) Value {
+				return NumberValue(toNumber(l(env)) - toNumber(right(env)))
+			}
+		}
+	}
+	return left, nil
+}
+
+// term = unary { ("*" | "/" | "%") unary }
+func (p *parser) parseTerm() (evalFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("*", "") || p.is("/", "") || p.is("%", "") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		switch op {
+		case "*":
+			left = func(env Env) Value {
+				return NumberValue(toNumber(l(env)) * toNumber(right(env)))
+			}
+		case "/":
+			left = func(env Env) Value {
+				return NumberValue(toNumber(l(env)) / toNumber(right(env)))
+			}
+		default:
+			left = func(env Env) Value {
+				return NumberValue(math.Mod(toNumber(l(env)),
+					toNumber(right(env))))
+			}
+		}
+	}
+	return left, nil
+}
+
+// unary = "-" unary | primary
+func (p *parser) parseUnary() (evalFunc, error) {
+	if p.is("-", "") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env Env) Value {
+			return NumberValue(-toNumber(operand(env)))
+		}, nil
+	}
+	return p.parsePrimary()
+}
+
+// primary = number | string | "true" | "false" | ident | call | "(" or ")"
+func (p *parser) parsePrimary() (evalFunc, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNum:
+		p.next()
+		v := NumberValue(tok.num)
+		return func(env Env) Value { return v }, nil
+	case tokStr:
+		p.next()
+		v := StringValue(tok.text)
+		return func(env Env) Value { return v }, nil
+	case tokIdent:
+		p.next()
+		if p.is("(", "") {
+			return p.parseCall(tok)
+		}
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return func(env Env) Value { return BoolValue(true) }, nil
+		case "false":
+			return func(env Env) Value { return BoolValue(false) }, nil
+		case "and", "or", "not", "in":
+			p.pos--
+			return nil, p.unexpected()
+		}
+		name := tok.text
+		return func(env Env) Value { return env.Ident(name) }, nil
+	case tokOp:
+		if tok.text == "(" {
+			p.next()
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.unexpected()
+}
+
+type function struct {
+	nargs int
+	call  func(args []Value) Value
+}
+
+var functions = map[string]function{
+	"lower": {1, func(args []Value) Value {
+		return StringValue(strings.ToLower(args[0].String()))
+	}},
+	"upper": {1, func(args []Value) Value {
+		return StringValue(strings.ToUpper(args[0].String()))
+	}},
+	"len": {1, func(args []Value) Value {
+		return NumberValue(float64(len(args[0].String())))
+	}},
+	"contains": {2, func(args []Value) Value {
+		return BoolValue(strings.Contains(args[0].String(), args[1].String()))
+	}},
+	"startswith": {2, func(args []Value) Value {
+		return BoolValue(strings.HasPrefix(args[0].String(), args[1].String()))
+	}},
+	"endswith": {2, func(args []Value) Value {
+		return BoolValue(strings.HasSuffix(args[0].String(), args[1].String()))
+	}},
+	"match": {2, func(args []Value) Value {
+		ok, _ := glob.Match(args[1].String(), args[0].String())
+		return BoolValue(ok)
+	}},
+	"abs": {1, func(args []Value) Value {
+		return NumberValue(math.Abs(toNumber(args[0])))
+	}},
+}
+
+// call = ident "(" [ or { "," or } ] ")"
+func (p *parser) parseCall(name token) (evalFunc, error) {
+	fn, ok := functions[strings.ToLower(name.text)]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at position %d",
+			name.text, name.pos+1)
+	}
+	p.next() // "("
+	var args []evalFunc
+	if !p.is(")", "") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.is(",", "") {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(args) != fn.nargs {
+		return nil, fmt.Errorf("function '%s' expects %d argument(s)",
+			name.text, fn.nargs)
+	}
+	return func(env Env) Value {
+		vals := make([]Value, len(args))
+		for i, arg := range args {
+			vals[i] = arg(env)
+		}
+		return fn.call(vals)
+	}, nil
+}
+
+func toNumber(v Value) float64 {
+	switch v.Kind {
+	case Number:
+		return v.Num
+	case Bool:
+		if v.Bool {
+			return 1
+		}
+		return 0
+	default:
+		return math.NaN()
+	}
+}
+
+// compare returns the ordering of two values. The ok return value is false
+// when the values are of different kinds, or either is NaN, and cannot be
+// compared.
+func compare(a, b Value) (c int, ok bool) {
+	if a.Kind != b.Kind {
+		return 0, false
+	}
+	switch a.Kind {
+	case Number:
+		switch {
+		case a.Num < b.Num:
+			return -1, true
+		case a.Num > b.Num:
+			return 1, true
+		case a.Num == b.Num:
+			return 0, true
+		}
+		return 0, false
+	case String:
+		return strings.Compare(a.Str, b.Str), true
+	default:
+		if a.Bool == b.Bool {
+			return 0, true
+		}
+		if !a.Bool {
+			return -1, true
+		}
+		return 1, true
+	}
+}
@@ -0,0 +1,58 @@
+package geofile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tidwall/geojson"
+)
+
+func TestFormat(t *testing.T) {
+	for path, format := range map[string]string{
+		"a.geojson": GeoJSON, "a.JSON": GeoJSON, "a.ndjson": NDJSON,
+		"a.jsonl": NDJSON, "a.csv": CSV, "a.kml": "", "a": "",
+	} {
+		if Format(path) != format {
+			t.Fatalf("%s: expected '%s', got '%s'", path, format, Format(path))
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var items []Item
+	for _, js := range []string{
+		`{"type":"Point","coordinates":[-115,33]}`,
+		`{"type":"LineString","coordinates":[[-115,33],[-116,34]]}`,
+	} {
+		o, err := geojson.Parse(js, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, Item{ID: string(rune('a' + len(items))),
+			Object: o, Fields: []float64{float64(len(items) + 1)}})
+	}
+	for _, format := range []string{GeoJSON, NDJSON, CSV} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, items, []string{"speed"}); err != nil {
+			t.Fatal(err)
+		}
+		var cmds []string
+		opts := &Options{Key: "fleet", Fields: []string{"speed"}}
+		err := Read(&buf, format, opts, func(rec Record) error {
+			if rec.Err != nil {
+				t.Fatalf("%s: line %d: %v", format, rec.Line, rec.Err)
+			}
+			cmds = append(cmds, strings.Join(rec.Args, " "))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cmds) != 2 ||
+			!strings.HasPrefix(cmds[0], "set fleet a FIELD speed 1 ") ||
+			!strings.HasPrefix(cmds[1], "set fleet b FIELD speed 2 ") {
+			t.Fatalf("%s: unexpected %q", format, cmds)
+		}
+	}
+}
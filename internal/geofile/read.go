@@ -0,0 +1,456 @@
+// Package geofile reads and writes the objects of a key as GeoJSON, NDJSON
+// or CSV files. It's shared by the IMPORT and EXPORT commands of the server
+// and by the --import and --export options of tile38-cli.
+package geofile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// The file formats
+const (
+	GeoJSON = "geojson" // a FeatureCollection
+	NDJSON  = "ndjson"  // one GeoJSON object per line
+	CSV     = "csv"     // one object per row
+)
+
+// Format returns the file format of a path from its extension, or an empty
+// string when the extension is unknown.
+func Format(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".geojson", ".json":
+		return GeoJSON
+	case ".ndjson", ".geojsonl", ".jsonl":
+		return NDJSON
+	case ".csv":
+		return CSV
+	}
+	return ""
+}
+
+// Options are the options for reading a file.
+type Options struct {
+	Key     string   // the key of the SET commands
+	IDField string   // the property holding the id, the default is "id"
+	Fields  []string // the properties that become fields
+}
+
+// Record is an object of a file, as the arguments of a SET command, or the
+// error of the line that couldn't be read.
+type Record struct {
+	Line int
+	Args []string
+	Err  error
+}
+
+// Read reads the objects of a file, one at a time, and emits a Record for
+// each of them. Errors of a single object are emitted as the Err of the
+// record, while any other error stops the reading and is returned.
+func Read(rd io.Reader, format string, opts *Options,
+	emit func(rec Record) error,
+) error {
+	brd := bufio.NewReader(rd)
+	switch format {
+	case GeoJSON:
+		return readGeoJSON(brd, opts, emit)
+	case NDJSON:
+		return readNDJSON(brd, opts, emit)
+	case CSV:
+		return readCSV(brd, opts, emit)
+	}
+	return errors.New("unknown file format")
+}
+
+// feature converts a GeoJSON object into the arguments of a SET
+// command. The id is the "id" member of the object, or the IDFIELD
+// property, and the FIELDS are taken from the properties.
+func feature(opts *Options, line int, data []byte) Record {
+	rec := Record{Line: line}
+	var id gjson.Result
+	if opts.IDField != "" {
+		id = gjson.GetBytes(data, "properties."+escapePath(opts.IDField))
+	} else {
+		id = gjson.GetBytes(data, "id")
+	}
+	if id.String() == "" {
+		rec.Err = errors.New("missing id")
+		return rec
+	}
+	rec.Args = []string{"set", opts.Key, id.String()}
+	for _, field := range opts.Fields {
+		v := gjson.GetBytes(data, "properties."+escapePath(field))
+		if !v.Exists() {
+			continue
+		}
+		n, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil {
+			rec.Err = fmt.Errorf("invalid field '%s'", field)
+			return rec
+		}
+		rec.Args = append(rec.Args, "FIELD", field,
+			strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	rec.Args = append(rec.Args, "OBJECT", string(data))
+	return rec
+}
+
+// escapePath escapes the characters of a property name that have special
+// meaning in a gjson path.
+func escapePath(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '.', '*', '?', '|', '#', '@', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// readNDJSON reads one GeoJSON object per line.
+func readNDJSON(rd *bufio.Reader, opts *Options,
+	emit func(rec Record) error,
+) error {
+	for line := 1; ; line++ {
+		data, err := rd.ReadBytes('\n')
+		if len(data) > 0 {
+			data = bytes.TrimSpace(data)
+			if len(data) > 0 {
+				if err := emit(feature(opts, line, data)); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readGeoJSON reads the features of a FeatureCollection, one at a
+// time, without loading the whole document.
+func readGeoJSON(rd *bufio.Reader, opts *Options,
+	emit func(rec Record) error,
+) error {
+	js := &jsonStream{rd: rd}
+	c, err := js.skipSpace()
+	if err != nil {
+		return err
+	}
+	if c != '{' {
+		return errors.New("expected a FeatureCollection")
+	}
+	for {
+		c, err := js.skipSpace()
+		if err != nil {
+			return err
+		}
+		if c == '}' {
+			return errors.New("missing features")
+		}
+		if c == ',' {
+			continue
+		}
+		key, err := js.readValue(c)
+		if err != nil {
+			return err
+		}
+		if c, err = js.skipSpace(); err != nil {
+			return err
+		}
+		if c != ':' {
+			return js.unexpected(c)
+		}
+		if c, err = js.skipSpace(); err != nil {
+			return err
+		}
+		if gjson.ParseBytes(key).String() != "features" {
+			if _, err := js.readValue(c); err != nil {
+				return err
+			}
+			continue
+		}
+		if c != '[' {
+			return js.unexpected(c)
+		}
+		for {
+			if c, err = js.skipSpace(); err != nil {
+				return err
+			}
+			if c == ']' {
+				return nil
+			}
+			if c == ',' {
+				continue
+			}
+			line := js.line + 1
+			data, err := js.readValue(c)
+			if err != nil {
+				return err
+			}
+			if err := emit(feature(opts, line, data)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// jsonStream reads the raw bytes of JSON values from a stream.
+type jsonStream struct {
+	rd   *bufio.Reader
+	line int // number of newlines read
+}
+
+func (js *jsonStream) readByte() (byte, error) {
+	c, err := js.rd.ReadByte()
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if c == '\n' {
+		js.line++
+	}
+	return c, err
+}
+
+func (js *jsonStream) skipSpace() (byte, error) {
+	for {
+		c, err := js.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if c > ' ' {
+			return c, nil
+		}
+	}
+}
+
+func (js *jsonStream) unexpected(c byte) error {
+	return fmt.Errorf("unexpected '%c' on line %d", c, js.line+1)
+}
+
+// readValue reads the value that starts with c.
+func (js *jsonStream) readValue(c byte) ([]byte, error) {
+	data := []byte{c}
+	var depth int
+	var err error
+	switch c {
+	case '{', '[':
+		depth = 1
+	case '"':
+		return js.readString(data)
+	case '}', ']', ',', ':':
+		return nil, js.unexpected(c)
+	default:
+		for {
+			next, err := js.rd.Peek(1)
+			if err != nil || next[0] <= ' ' || next[0] == ',' ||
+				next[0] == ']' || next[0] == '}' {
+				return data, nil
+			}
+			c, _ = js.readByte()
+			data = append(data, c)
+		}
+	}
+	for depth > 0 {
+		if c, err = js.readByte(); err != nil {
+			return nil, err
+		}
+		data = append(data, c)
+		switch c {
+		case '"':
+			if data, err = js.readString(data); err != nil {
+				return nil, err
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		}
+	}
+	return data, nil
+}
+
+// readString reads the rest of a string whose opening quote is already in
+// data.
+func (js *jsonStream) readString(data []byte) ([]byte, error) {
+	for {
+		c, err := js.readByte()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, c)
+		switch c {
+		case '\\':
+			if c, err = js.readByte(); err != nil {
+				return nil, err
+			}
+			data = append(data, c)
+		case '"':
+			return data, nil
+		}
+	}
+}
+
+// readCSV reads rows that have a header. The id comes from the "id"
+// column, or the IDFIELD column, and the position from the lat and lon
+// columns or from a geometry column holding GeoJSON. The FIELDS columns
+// become fields and any other columns become properties of the object.
+func readCSV(rd *bufio.Reader, opts *Options,
+	emit func(rec Record) error,
+) error {
+	cr := csv.NewReader(rd)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	idfield := opts.IDField
+	if idfield == "" {
+		idfield = "id"
+	}
+	idcol, latcol, loncol, geomcol := -1, -1, -1, -1
+	fieldcols := make(map[int]bool)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		header[i] = name
+		switch strings.ToLower(name) {
+		case "lat", "latitude":
+			latcol = i
+			continue
+		case "lon", "lng", "long", "longitude":
+			loncol = i
+			continue
+		case "geometry", "geojson":
+			geomcol = i
+			continue
+		}
+		if name == idfield {
+			idcol = i
+			continue
+		}
+		for _, field := range opts.Fields {
+			if name == field {
+				fieldcols[i] = true
+			}
+		}
+	}
+	if idcol == -1 {
+		return fmt.Errorf("missing '%s' column", idfield)
+	}
+	if geomcol == -1 && (latcol == -1 || loncol == -1) {
+		return errors.New("missing lat and lon columns")
+	}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if err := emit(Record{Line: line, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := emit(csvRow(opts, line, header, row, idcol, latcol, loncol,
+			geomcol, fieldcols)); err != nil {
+			return err
+		}
+	}
+}
+
+func csvRow(opts *Options, line int, header, row []string,
+	idcol, latcol, loncol, geomcol int, fieldcols map[int]bool,
+) Record {
+	rec := Record{Line: line}
+	col := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	id := col(idcol)
+	if id == "" {
+		rec.Err = errors.New("missing id")
+		return rec
+	}
+	rec.Args = []string{"set", opts.Key, id}
+	var props []string
+	for i, name := range header {
+		v := col(i)
+		if v == "" || i == idcol || i == latcol || i == loncol || i == geomcol {
+			continue
+		}
+		if fieldcols[i] {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				rec.Err = fmt.Errorf("invalid field '%s'", name)
+				return rec
+			}
+			rec.Args = append(rec.Args, "FIELD", name,
+				strconv.FormatFloat(n, 'f', -1, 64))
+			continue
+		}
+		if n, err := strconv.ParseFloat(v, 64); err != nil ||
+			math.IsNaN(n) || math.IsInf(n, 0) {
+			v = jsonString(v)
+		} else {
+			v = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		props = append(props, jsonString(name)+":"+v)
+	}
+	var geom string
+	if geomcol != -1 && col(geomcol) != "" {
+		geom = col(geomcol)
+	} else {
+		lat, err := strconv.ParseFloat(col(latcol), 64)
+		if err != nil {
+			rec.Err = fmt.Errorf("invalid argument '%s'", col(latcol))
+			return rec
+		}
+		lon, err := strconv.ParseFloat(col(loncol), 64)
+		if err != nil {
+			rec.Err = fmt.Errorf("invalid argument '%s'", col(loncol))
+			return rec
+		}
+		if len(props) == 0 {
+			rec.Args = append(rec.Args, "POINT",
+				strconv.FormatFloat(lat, 'f', -1, 64),
+				strconv.FormatFloat(lon, 'f', -1, 64))
+			return rec
+		}
+		geom = `{"type":"Point","coordinates":[` +
+			strconv.FormatFloat(lon, 'f', -1, 64) + "," +
+			strconv.FormatFloat(lat, 'f', -1, 64) + "]}"
+	}
+	if len(props) > 0 {
+		geom = `{"type":"Feature","geometry":` + geom + `,"properties":{` +
+			strings.Join(props, ",") + "}}"
+	}
+	rec.Args = append(rec.Args, "OBJECT", geom)
+	return rec
+}
+
+// jsonString returns s as a JSON string.
+func jsonString(s string) string {
+	d, _ := json.Marshal(s)
+	return string(d)
+}
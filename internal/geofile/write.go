@@ -0,0 +1,110 @@
+package geofile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Item is an object that is written to a file.
+type Item struct {
+	ID     string
+	Object geojson.Object
+	Fields []float64 // the values of the fields, in the order of the names
+}
+
+// Write writes the items to a file in the layout that Read reads. The
+// fields are the names of the Fields of the items, and the non-zero fields
+// are written as properties.
+func Write(w io.Writer, format string, items []Item, fields []string) error {
+	wr := bufio.NewWriter(w)
+	switch format {
+	case GeoJSON:
+		wr.WriteString(`{"type":"FeatureCollection","features":[`)
+		for i, item := range items {
+			if i > 0 {
+				wr.WriteByte(',')
+			}
+			wr.WriteString("\n")
+			wr.WriteString(featureJSON(item, fields))
+		}
+		wr.WriteString("\n]}\n")
+	case NDJSON:
+		for _, item := range items {
+			wr.WriteString(featureJSON(item, fields))
+			wr.WriteByte('\n')
+		}
+	case CSV:
+		if err := writeCSV(wr, items, fields); err != nil {
+			return err
+		}
+	}
+	return wr.Flush()
+}
+
+// featureJSON returns the object as a GeoJSON Feature with the id and with
+// the non-zero fields as properties.
+func featureJSON(item Item, farr []string) string {
+	feature := item.Object.JSON()
+	if gjson.Get(feature, "type").String() != "Feature" {
+		feature = `{"type":"Feature","geometry":` + feature +
+			`,"properties":{}}`
+	}
+	feature, _ = sjson.Set(feature, "id", item.ID)
+	for i, field := range farr {
+		if i < len(item.Fields) && item.Fields[i] != 0 {
+			feature, _ = sjson.SetRaw(feature, "properties."+escapePath(field),
+				strconv.FormatFloat(item.Fields[i], 'f', -1, 64))
+		}
+	}
+	return feature
+}
+
+// writeCSV writes one row per object in the same layout that IMPORT
+// reads. Points are written as lat and lon, and all other objects as
+// GeoJSON in the geojson column.
+func writeCSV(wr *bufio.Writer, items []Item, farr []string,
+) error {
+	cw := csv.NewWriter(wr)
+	header := append([]string{"id", "lat", "lon"}, farr...)
+	header = append(header, "geojson")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, len(header))
+	for _, item := range items {
+		for i := range row {
+			row[i] = ""
+		}
+		row[0] = item.ID
+		switch o := item.Object.(type) {
+		case *geojson.SimplePoint:
+			row[1] = strconv.FormatFloat(o.Base().Y, 'f', -1, 64)
+			row[2] = strconv.FormatFloat(o.Base().X, 'f', -1, 64)
+		case *geojson.Point:
+			if o.Z() == 0 {
+				row[1] = strconv.FormatFloat(o.Base().Y, 'f', -1, 64)
+				row[2] = strconv.FormatFloat(o.Base().X, 'f', -1, 64)
+				break
+			}
+			row[len(row)-1] = o.JSON()
+		default:
+			row[len(row)-1] = o.JSON()
+		}
+		for i := range farr {
+			if i < len(item.Fields) && item.Fields[i] != 0 {
+				row[3+i] = strconv.FormatFloat(item.Fields[i], 'f', -1, 64)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -164,7 +164,13 @@ func (s *Server) writeAOF(args []string, d *commandDetails) error {
 		// just ignore writes if the command did not update
 		return nil
 	}
+	s.appendAOF(args)
+	return s.notifyWrite(d)
+}
 
+// appendAOF appends a command to the aof buffer, without notifying anyone.
+// A batch of commands is followed by a single notifyWrite.
+func (s *Server) appendAOF(args []string) {
 	if s.shrinking {
 		nargs := make([]string, len(args))
 		copy(nargs, args)
@@ -180,7 +186,11 @@ func (s *Server) writeAOF(args []string, d *commandDetails) error {
 		}
 		s.aofsz += len(s.aofbuf) - n
 	}
+}
 
+// notifyWrite tells the followers, the hooks, the live geofences and the
+// tracking clients about the writes of a command.
+func (s *Server) notifyWrite(d *commandDetails) error {
 	// notify aof live connections that we have new data
 	s.fcond.L.Lock()
 	s.fcond.Broadcast()
@@ -188,6 +198,12 @@ func (s *Server) writeAOF(args []string, d *commandDetails) error {
 
 	// process geofences
 	if d != nil {
+		// keyspace notifications
+		s.notifyKeyspaceEvent(d)
+
+		// client side caching
+		s.invalidateTracking(d)
+
 		// webhook geofences
 		if s.config.followHost() == "" {
 			// for leader only
@@ -326,10 +342,14 @@ func (s *Server) queueHooks(d *commandDetails) error {
 		sortMsgs(wmsgs)
 	}
 
-	// Publish all channel messages if any exist
-	if len(cmsgs) > 0 {
-		for _, m := range cmsgs {
-			s.Publish(gjson.Get(m, "hook").String(), m)
+	// Publish all channel messages if any exist. The messages of channels
+	// with a function or a script are published by the hook once it has run.
+	for _, m := range cmsgs {
+		name := gjson.Get(m, "hook").String()
+		if hook := s.hooks[name]; hook != nil && hook.transforms() {
+			hook.queue(m)
+		} else {
+			s.publishChan(name, m)
 		}
 	}
 
@@ -361,6 +381,12 @@ func (s *Server) queueHooks(d *commandDetails) error {
 	return nil
 }
 
+// publishChan publishes a message of a channel. The recent messages are also
+// kept, which lets SSE subscribers resume from a Last-Event-ID.
+func (s *Server) publishChan(name, msg string) {
+	s.publish(name, s.chanlog.add(name, msg), msg)
+}
+
 // sortMsgs sorts passed notification messages by their detect and hook fields
 func sortMsgs(msgs []string) {
 	sort.SliceStable(msgs, func(i, j int) bool {
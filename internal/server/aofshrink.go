@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/tidwall/geojson"
+	"github.com/tidwall/redcon"
 	"github.com/tidwall/rhh"
 	"github.com/tidwall/tile38/core"
 	"github.com/tidwall/tile38/internal/collection"
@@ -101,6 +102,13 @@ func (server *Server) aofshrink() {
 					}
 					var now = time.Now().UnixNano() // used for expiration
 					var count = 0                   // the object count
+					kc := server.keyConfigs[keys[0]]
+					if kc != nil && nextid == "" {
+						// the objects are indexed like before
+						aofbuf = appendKeyConfig(aofbuf, kc, keys[0], true)
+						aofbuf = appendKeyHistory(aofbuf,
+							server.histories[keys[0]], keys[0], col)
+					}
 					col.ScanGreaterOrEqual(nextid, false, nil, nil,
 						func(id string, obj geojson.Object, fields []float64) bool {
 							if count == maxids {
@@ -159,6 +167,29 @@ func (server *Server) aofshrink() {
 							return true
 						},
 					)
+					if idsdone {
+						// the field indexes are rebuilt after all of the
+						// objects for the key have been loaded.
+						for _, field := range col.Indexes() {
+							values = values[:0]
+							values = append(values, "createindex", keys[0], field)
+
+							// append the values to the aof buffer
+							aofbuf = append(aofbuf, '*')
+							aofbuf = append(aofbuf, strconv.FormatInt(int64(len(values)), 10)...)
+							aofbuf = append(aofbuf, '\r', '\n')
+							for _, value := range values {
+								aofbuf = append(aofbuf, '$')
+								aofbuf = append(aofbuf, strconv.FormatInt(int64(len(value)), 10)...)
+								aofbuf = append(aofbuf, '\r', '\n')
+								aofbuf = append(aofbuf, value...)
+								aofbuf = append(aofbuf, '\r', '\n')
+							}
+						}
+						if kc != nil {
+							aofbuf = appendKeyConfig(aofbuf, kc, keys[0], false)
+						}
+					}
 
 				}()
 				if len(aofbuf) > maxchunk {
@@ -170,6 +201,38 @@ func (server *Server) aofshrink() {
 			}
 		}
 
+		// load the configs of keys without objects
+		func() {
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			var kkeys []string
+			for key := range server.keyConfigs {
+				if server.getCol(key) == nil {
+					kkeys = append(kkeys, key)
+				}
+			}
+			sort.Strings(kkeys)
+			for _, key := range kkeys {
+				kc := server.keyConfigs[key]
+				aofbuf = appendKeyConfig(aofbuf, kc, key, true)
+				aofbuf = appendKeyHistory(aofbuf, server.histories[key], key,
+					nil)
+				aofbuf = appendKeyConfig(aofbuf, kc, key, false)
+			}
+		}()
+
+		// load functions
+		func() {
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			for _, lib := range server.luafuncs.sorted() {
+				aofbuf = redcon.AppendArray(aofbuf, 3)
+				aofbuf = redcon.AppendBulkString(aofbuf, "function")
+				aofbuf = redcon.AppendBulkString(aofbuf, "load")
+				aofbuf = redcon.AppendBulkString(aofbuf, lib.code)
+			}
+		}()
+
 		// load hooks
 		// first load the names of the hooks
 		var hnames []string
@@ -204,6 +267,12 @@ func (server *Server) aofshrink() {
 				for _, meta := range hook.Metas {
 					values = append(values, "meta", meta.Name, meta.Value)
 				}
+				if hook.Function != "" {
+					values = append(values, "function", hook.Function)
+				}
+				if hook.Script != "" {
+					values = append(values, "script", hook.Script)
+				}
 				if !hook.expires.IsZero() {
 					ex := float64(hook.expires.Sub(time.Now())) /
 						float64(time.Second)
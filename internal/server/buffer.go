@@ -0,0 +1,346 @@
+package server
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+)
+
+// parseBuffer reads an optional "BUFFER meters" that follows an area and,
+// when present, replaces the area with its buffer.
+func (server *Server) parseBuffer(vs []string, o geojson.Object) (
+	[]string, geojson.Object, error,
+) {
+	nvs, tok, ok := tokenval(vs)
+	if !ok || strings.ToLower(tok) != "buffer" {
+		return vs, o, nil
+	}
+	var smeters string
+	if nvs, smeters, ok = tokenval(nvs); !ok || smeters == "" {
+		return vs, o, errInvalidNumberOfArguments
+	}
+	meters, err := strconv.ParseFloat(smeters, 64)
+	if err != nil || meters < 0 || math.IsInf(meters, 0) {
+		return vs, o, errInvalidArgument(smeters)
+	}
+	opts := &geometry.IndexOptions{
+		Kind:      server.geomParseOpts.IndexGeometryKind,
+		MinPoints: server.geomParseOpts.IndexGeometry,
+	}
+	return nvs, bufferObject(o, meters, opts), nil
+}
+
+// bufferObject returns the area that is within meters of the object.
+// Points and circles become circles. Lines and polygons become a polygon
+// that follows the outline of the object at the distance, with rounded
+// ends and outer corners. Holes in polygons shrink by the distance and
+// disappear once they close up. When the outline would cross itself, such
+// as for a winding route with a wide buffer, the area is returned as a
+// multipolygon of the buffers of each segment instead.
+func bufferObject(o geojson.Object, meters float64,
+	opts *geometry.IndexOptions,
+) geojson.Object {
+	if meters == 0 {
+		return o
+	}
+	switch o := o.(type) {
+	case *geojson.Point, *geojson.SimplePoint:
+		return geojson.NewCircle(o.Center(), meters, defaultCircleSteps)
+	case *geojson.Circle:
+		return geojson.NewCircle(o.Center(), o.Meters()+meters,
+			defaultCircleSteps)
+	case *geojson.Feature:
+		return bufferObject(o.Base(), meters, opts)
+	}
+	polys := bufferPolys(o, meters, opts)
+	if len(polys) == 1 {
+		return geojson.NewPolygon(polys[0])
+	}
+	return geojson.NewMultiPolygon(polys)
+}
+
+// bufferPolys returns the polygons that make up the buffer of an object.
+func bufferPolys(o geojson.Object, meters float64,
+	opts *geometry.IndexOptions,
+) []*geometry.Poly {
+	switch o := o.(type) {
+	case *geojson.Point, *geojson.SimplePoint, *geojson.Circle:
+		c := bufferObject(o, meters, opts).(*geojson.Circle)
+		return []*geometry.Poly{c.Primative().(*geojson.Polygon).Base()}
+	case *geojson.Feature:
+		return bufferPolys(o.Base(), meters, opts)
+	case *geojson.Rect:
+		r := o.Base()
+		ring := []geometry.Point{
+			r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y},
+		}
+		return bufferPolygon(ring, nil, meters, opts)
+	case *geojson.LineString:
+		return bufferLine(seriesPoints(o.Base()), meters, opts)
+	case *geojson.Polygon:
+		poly := o.Base()
+		var holes [][]geometry.Point
+		for _, hole := range poly.Holes {
+			holes = append(holes, seriesPoints(hole))
+		}
+		return bufferPolygon(seriesPoints(poly.Exterior), holes, meters, opts)
+	case geojson.Collection:
+		var polys []*geometry.Poly
+		for _, child := range o.Children() {
+			polys = append(polys, bufferPolys(child, meters, opts)...)
+		}
+		return polys
+	}
+	return nil
+}
+
+// seriesPoints returns the points of a line or ring without consecutive
+// duplicates and without the closing point of a ring.
+func seriesPoints(series geometry.Series) []geometry.Point {
+	var points []geometry.Point
+	for i := 0; i < series.NumPoints(); i++ {
+		p := series.PointAt(i)
+		if len(points) == 0 || points[len(points)-1] != p {
+			points = append(points, p)
+		}
+	}
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	return points
+}
+
+// bufferLine buffers a path by walking around it as if it were a ring that
+// goes out along the path and comes back the same way. The half turns at
+// each end become the round end caps.
+func bufferLine(path []geometry.Point, meters float64,
+	opts *geometry.IndexOptions,
+) []*geometry.Poly {
+	if len(path) == 0 {
+		return nil
+	}
+	if len(path) == 1 {
+		return bufferPolys(geojson.NewPoint(path[0]), meters, opts)
+	}
+	ring := make([]geometry.Point, 0, len(path)*2-2)
+	ring = append(ring, path...)
+	for i := len(path) - 2; i > 0; i-- {
+		ring = append(ring, path[i])
+	}
+	outline := offsetRing(ring, meters)
+	if !selfIntersects(outline) {
+		return []*geometry.Poly{geometry.NewPoly(outline, nil, opts)}
+	}
+	return bufferSegments(path, false, meters, opts)
+}
+
+// bufferPolygon buffers a polygon by moving the exterior ring outwards and
+// the holes inwards.
+func bufferPolygon(exterior []geometry.Point, holes [][]geometry.Point,
+	meters float64, opts *geometry.IndexOptions,
+) []*geometry.Poly {
+	if len(exterior) < 3 {
+		return bufferLine(exterior, meters, opts)
+	}
+	// The offset is always to the left of the direction of travel, which
+	// is the outside of a clockwise ring.
+	if ringArea(exterior) > 0 {
+		exterior = reversePoints(exterior)
+	}
+	outline := offsetRing(exterior, meters)
+	if selfIntersects(outline) {
+		// Fall back to the polygon itself plus the buffer of its edges.
+		polys := []*geometry.Poly{geometry.NewPoly(closeRing(exterior), nil,
+			opts)}
+		return append(polys, bufferSegments(exterior, true, meters, opts)...)
+	}
+	var inners [][]geometry.Point
+	for _, hole := range holes {
+		if len(hole) < 3 {
+			continue
+		}
+		if ringArea(hole) < 0 {
+			hole = reversePoints(hole)
+		}
+		inner := offsetRing(hole, meters)
+		if ringArea(inner) > 0 && !selfIntersects(inner) &&
+			ringWithinRing(inner, hole) {
+			inners = append(inners, inner)
+		}
+	}
+	return []*geometry.Poly{geometry.NewPoly(outline, inners, opts)}
+}
+
+// bufferSegments returns the buffer of each segment of a path as a
+// separate polygon.
+func bufferSegments(path []geometry.Point, closed bool, meters float64,
+	opts *geometry.IndexOptions,
+) []*geometry.Poly {
+	n := len(path) - 1
+	if closed {
+		n++
+	}
+	polys := make([]*geometry.Poly, 0, n)
+	for i := 0; i < n; i++ {
+		seg := []geometry.Point{path[i], path[(i+1)%len(path)]}
+		polys = append(polys, geometry.NewPoly(offsetRing(seg, meters), nil,
+			opts))
+	}
+	return polys
+}
+
+// offsetRing returns the closed ring that is meters to the left of each
+// segment of ring. Outer corners are rounded and inner corners are cut at
+// the point where the offset segments cross.
+func offsetRing(ring []geometry.Point, meters float64) []geometry.Point {
+	n := len(ring)
+	type offsetSeg struct {
+		a, b         geometry.Point
+		bearA, bearB float64
+	}
+	segs := make([]offsetSeg, n)
+	for i := 0; i < n; i++ {
+		a, b := ring[i], ring[(i+1)%n]
+		bearA := geo.BearingTo(a.Y, a.X, b.Y, b.X)
+		bearB := math.Mod(geo.BearingTo(b.Y, b.X, a.Y, a.X)+180, 360)
+		segs[i] = offsetSeg{
+			a:     destination(a, meters, bearA-90),
+			b:     destination(b, meters, bearB-90),
+			bearA: bearA,
+			bearB: bearB,
+		}
+	}
+	var points []geometry.Point
+	for i := 0; i < n; i++ {
+		in, out := segs[(i+n-1)%n], segs[i]
+		vertex := ring[i]
+		turn := math.Mod(out.bearA-in.bearB+540, 360) - 180
+		if turn <= -180+1e-9 {
+			// Turning back along the same path, as at the ends of a line.
+			turn = 180
+		}
+		if turn >= 0 {
+			// Outer corner. Sweep around the vertex from the end of the
+			// incoming segment to the start of the outgoing segment.
+			points = append(points, in.b)
+			steps := int(math.Ceil(turn / 360 * defaultCircleSteps))
+			for j := 1; j < steps; j++ {
+				bear := in.bearB - 90 + turn*float64(j)/float64(steps)
+				points = append(points, destination(vertex, meters, bear))
+			}
+			points = append(points, out.a)
+		} else if p, ok := segmentIntersection(in.a, in.b, out.a, out.b); ok {
+			points = append(points, p)
+		} else {
+			points = append(points, in.b, out.a)
+		}
+	}
+	return closeRing(points)
+}
+
+func destination(p geometry.Point, meters, bearing float64) geometry.Point {
+	lat, lon := geo.DestinationPoint(p.Y, p.X, meters, bearing)
+	return geometry.Point{X: lon, Y: lat}
+}
+
+// segmentIntersection returns the point where segments a1-a2 and b1-b2
+// cross.
+func segmentIntersection(a1, a2, b1, b2 geometry.Point) (geometry.Point,
+	bool) {
+	dax, day := a2.X-a1.X, a2.Y-a1.Y
+	dbx, dby := b2.X-b1.X, b2.Y-b1.Y
+	den := dax*dby - day*dbx
+	if den == 0 {
+		return geometry.Point{}, false
+	}
+	t := ((b1.X-a1.X)*dby - (b1.Y-a1.Y)*dbx) / den
+	u := ((b1.X-a1.X)*day - (b1.Y-a1.Y)*dax) / den
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return geometry.Point{}, false
+	}
+	return geometry.Point{X: a1.X + t*dax, Y: a1.Y + t*day}, true
+}
+
+// selfIntersects returns true when any two non-adjacent segments of a closed
+// ring cross. Segments are swept from west to east so that only those with
+// overlapping longitudes are compared.
+func selfIntersects(ring []geometry.Point) bool {
+	n := len(ring) - 1
+	if n < 4 {
+		return false
+	}
+	segs := make([]int, n)
+	for i := range segs {
+		segs[i] = i
+	}
+	seg := func(i int) geometry.Segment {
+		return geometry.Segment{A: ring[i], B: ring[i+1]}
+	}
+	sort.Slice(segs, func(i, j int) bool {
+		return seg(segs[i]).Rect().Min.X < seg(segs[j]).Rect().Min.X
+	})
+	for i, si := range segs {
+		a := seg(si)
+		ar := a.Rect()
+		for _, sj := range segs[i+1:] {
+			b := seg(sj)
+			br := b.Rect()
+			if br.Min.X > ar.Max.X {
+				break
+			}
+			if si == sj+1 || sj == si+1 || (si == 0 && sj == n-1) ||
+				(sj == 0 && si == n-1) {
+				continue
+			}
+			if br.Min.Y > ar.Max.Y || br.Max.Y < ar.Min.Y {
+				continue
+			}
+			if a.IntersectsSegment(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ringArea returns the signed planar area of a ring, which is positive
+// when the ring is counter-clockwise.
+func ringArea(ring []geometry.Point) float64 {
+	var area float64
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// ringWithinRing returns true when every point of inner is inside outer.
+func ringWithinRing(inner, outer []geometry.Point) bool {
+	poly := geometry.NewPoly(closeRing(outer), nil, nil)
+	for _, p := range inner {
+		if !poly.ContainsPoint(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func closeRing(ring []geometry.Point) []geometry.Point {
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		ring = append(ring[:len(ring):len(ring)], ring[0])
+	}
+	return ring
+}
+
+func reversePoints(points []geometry.Point) []geometry.Point {
+	rev := make([]geometry.Point, len(points))
+	for i, p := range points {
+		rev[len(points)-1-i] = p
+	}
+	return rev
+}
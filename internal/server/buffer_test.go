@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+)
+
+func TestBuffer(t *testing.T) {
+	parse := func(s string) geojson.Object {
+		o, err := geojson.Parse(s, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	pt := func(x, y float64) geojson.Object {
+		return geojson.NewPoint(geometry.Point{X: x, Y: y})
+	}
+	tests := []struct {
+		obj    string
+		meters float64
+		in     []geojson.Object
+		out    []geojson.Object
+	}{
+		{
+			// straight route, ~1.1 km long
+			obj:    `{"type":"LineString","coordinates":[[0,0],[0.01,0]]}`,
+			meters: 200,
+			in:     []geojson.Object{pt(0.005, 0.001), pt(0.005, -0.001), pt(0.0115, 0), pt(-0.0015, 0.0005)},
+			out:    []geojson.Object{pt(0.005, 0.003), pt(0.013, 0), pt(-0.0015, 0.0015)},
+		},
+		{
+			// route with a right angle turn
+			obj:    `{"type":"LineString","coordinates":[[0,0],[0.01,0],[0.01,0.01]]}`,
+			meters: 200,
+			in:     []geojson.Object{pt(0.011, -0.001), pt(0.0085, 0.0015), pt(0.0115, 0.005)},
+			out:    []geojson.Object{pt(0.005, 0.005), pt(0.0125, -0.0025)},
+		},
+		{
+			// winding route with a buffer wider than its turns
+			obj:    `{"type":"LineString","coordinates":[[0,0],[0.01,0],[0.01,0.001],[0,0.001],[0,0.002],[0.01,0.002]]}`,
+			meters: 500,
+			in:     []geojson.Object{pt(0.005, 0.0005), pt(0.005, -0.004), pt(0.005, 0.006)},
+			out:    []geojson.Object{pt(0.005, 0.008), pt(0.018, 0.001)},
+		},
+		{
+			// square with a large hole
+			obj: `{"type":"Polygon","coordinates":[
+				[[0,0],[0.1,0],[0.1,0.1],[0,0.1],[0,0]],
+				[[0.02,0.02],[0.08,0.02],[0.08,0.08],[0.02,0.08],[0.02,0.02]]]}`,
+			meters: 1000,
+			in:     []geojson.Object{pt(-0.005, 0.05), pt(0.05, 0.01), pt(0.025, 0.05)},
+			out:    []geojson.Object{pt(-0.015, 0.05), pt(0.05, 0.05)},
+		},
+		{
+			obj:    `{"type":"Point","coordinates":[0,0]}`,
+			meters: 100,
+			in:     []geojson.Object{pt(0.0005, 0)},
+			out:    []geojson.Object{pt(0.0015, 0)},
+		},
+	}
+	for i, test := range tests {
+		area := bufferObject(parse(test.obj), test.meters, nil)
+		for _, p := range test.in {
+			if !p.Within(area) {
+				t.Fatalf("test %d: expected %s within buffer", i, p)
+			}
+		}
+		for _, p := range test.out {
+			if p.Within(area) {
+				t.Fatalf("test %d: expected %s outside of buffer", i, p)
+			}
+		}
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/core"
 )
 
 // Client is an remote connection into to Tile38
@@ -18,6 +21,7 @@ type Client struct {
 	id         int            // unique id
 	replPort   int            // the known replication port for follower connections
 	authd      bool           // client has been authenticated
+	resp3      bool           // client switched to RESP3 with HELLO 3
 	outputType Type           // Null, JSON, or RESP
 	remoteAddr string         // original remote address
 	in         InputStream    // input stream
@@ -27,6 +31,29 @@ type Client struct {
 	goLiveErr error    // error type used for going line
 	goLiveMsg *Message // last message for go live
 
+	multi    bool       // client is in MULTI
+	multiErr bool       // a command could not be queued
+	queued   []*Message // commands queued by MULTI
+	watches  []watchT   // objects watched by WATCH
+
+	tracking   bool       // CLIENT TRACKING is on
+	trackBcast bool       // tracking in BCAST mode
+	netConn    net.Conn   // connection, for push messages
+	wmu        sync.Mutex // guards writes to netConn
+	pmu        sync.Mutex // guards pushes
+	pushes     []byte     // pending push messages
+	pushing    bool       // pushes are being written
+
+	// objects read while tracking, key -> ids, guarded by the server trackmu
+	trackedIDs map[string]map[string]bool
+
+	timeout  float64   // default search timeout in seconds, CLIENT SETTIMEOUT
+	pending  []byte    // input read while watching for a disconnect, guarded by mu
+	cmd      string    // running command
+	cmdStart time.Time // when the running command started
+
+	limits clientLimits // rate limit counters and overrides
+
 	mu     sync.Mutex         // guard
 	conn   io.ReadWriteCloser // out-of-loop connection.
 	name   string             // optional defined name
@@ -40,6 +67,13 @@ func (client *Client) Write(b []byte) (n int, err error) {
 	return len(b), nil
 }
 
+// resetMulti ends the MULTI state of the client.
+func (client *Client) resetMulti() {
+	client.multi = false
+	client.multiErr = false
+	client.queued = nil
+}
+
 type byID []*Client
 
 func (arr byID) Len() int {
@@ -61,7 +95,13 @@ func (s *Server) cmdClient(msg *Message, client *Client) (resp.Value, error) {
 	switch strings.ToLower(msg.Args[1]) {
 	default:
 		return NOMessage, errors.New("Syntax error, try CLIENT " +
-			"(LIST | KILL | GETNAME | SETNAME)")
+			"(LIST | KILL | GETNAME | SETNAME | TRACKING | SETTIMEOUT | LIMIT)")
+	case "limit":
+		return s.cmdClientLimit(msg)
+	case "tracking":
+		return s.cmdClientTracking(msg, client)
+	case "settimeout":
+		return s.cmdClientSetTimeout(msg, client)
 	case "list":
 		if len(msg.Args) != 2 {
 			return NOMessage, errInvalidNumberOfArguments
@@ -77,13 +117,21 @@ func (s *Server) cmdClient(msg *Message, client *Client) (resp.Value, error) {
 		var buf []byte
 		for _, client := range list {
 			client.mu.Lock()
+			var cmdElapsed time.Duration
+			if client.cmd != "" {
+				cmdElapsed = now.Sub(client.cmdStart)
+			}
 			buf = append(buf,
-				fmt.Sprintf("id=%d addr=%s name=%s age=%d idle=%d\n",
+				fmt.Sprintf("id=%d addr=%s name=%s age=%d idle=%d "+
+					"timeout=%s cmd=%s cmd-elapsed=%s\n",
 					client.id,
 					client.remoteAddr,
 					client.name,
 					now.Sub(client.opened)/time.Second,
 					now.Sub(client.last)/time.Second,
+					strconv.FormatFloat(client.timeout, 'f', -1, 64),
+					client.cmd,
+					cmdElapsed,
 				)...,
 			)
 			client.mu.Unlock()
@@ -230,6 +278,106 @@ func (s *Server) cmdClient(msg *Message, client *Client) (resp.Value, error) {
 	return NOMessage, errors.New("invalid output type")
 }
 
+// cmdHello handles the HELLO command.
+//
+// HELLO [protover [AUTH username password] [SETNAME clientname]]
+//
+// The protocol version is 2 or 3, and 3 is only supported for RESP
+// connections. The username is ignored, there is only the requirepass.
+func (s *Server) cmdHello(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	proto := 2
+	if client.resp3 {
+		proto = 3
+	}
+	var protover, password, name string
+	var auth, setname, ok bool
+	if len(vs) > 0 {
+		vs, protover, _ = tokenval(vs)
+		n, err := strconv.ParseUint(protover, 10, 8)
+		if err != nil {
+			return NOMessage, errInvalidArgument(protover)
+		}
+		if n != 2 && (n != 3 || msg.ConnType != RESP) {
+			if msg.OutputType == RESP {
+				return resp.ErrorValue(
+					errors.New("NOPROTO unsupported protocol version")), nil
+			}
+			return NOMessage, errors.New("unsupported protocol version")
+		}
+		proto = int(n)
+	}
+	for len(vs) > 0 {
+		switch strings.ToLower(vs[0]) {
+		case "auth":
+			if vs, _, ok = tokenval(vs[1:]); !ok {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			if vs, password, ok = tokenval(vs); !ok {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			auth = true
+		case "setname":
+			if vs, name, ok = tokenval(vs[1:]); !ok {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			for i := 0; i < len(name); i++ {
+				if name[i] < '!' || name[i] > '~' {
+					errstr := "Client names cannot contain spaces, newlines or special characters."
+					return NOMessage, errors.New(errstr)
+				}
+			}
+			setname = true
+		default:
+			return NOMessage, errInvalidArgument(vs[0])
+		}
+	}
+	if pass := s.config.requirePass(); pass != "" {
+		if auth {
+			if pass != strings.TrimSpace(password) {
+				return NOMessage, errors.New("invalid password")
+			}
+			client.authd = true
+		} else if !client.authd && msg.Auth != pass {
+			return NOMessage, errors.New("authentication required")
+		}
+	}
+	if setname {
+		client.mu.Lock()
+		client.name = name
+		client.mu.Unlock()
+	}
+	client.resp3 = proto == 3
+	msg.useRESP3(client.resp3)
+	role := "master"
+	if s.config.followHost() != "" {
+		role = "slave"
+	}
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true` +
+			`,"server":"tile38"` +
+			`,"version":` + jsonString(core.Version) +
+			`,"proto":` + strconv.Itoa(proto) +
+			`,"id":` + strconv.Itoa(client.id) +
+			`,"mode":"standalone"` +
+			`,"role":"` + role + `"` +
+			`,"elapsed":"` + time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return respMap(msg, []resp.Value{
+			resp.StringValue("server"), resp.StringValue("tile38"),
+			resp.StringValue("version"), resp.StringValue(core.Version),
+			resp.StringValue("proto"), resp.IntegerValue(proto),
+			resp.StringValue("id"), resp.IntegerValue(client.id),
+			resp.StringValue("mode"), resp.StringValue("standalone"),
+			resp.StringValue("role"), resp.StringValue(role),
+			resp.StringValue("modules"), resp.ArrayValue(nil),
+		}), nil
+	}
+	return NOMessage, nil
+}
+
 /*
 func (c *Controller) cmdClientList(msg *Message) (string, error) {
 
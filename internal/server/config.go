@@ -16,8 +16,9 @@ import (
 )
 
 const (
-	defaultKeepAlive     = 300 // seconds
-	defaultProtectedMode = "yes"
+	defaultKeepAlive       = 300 // seconds
+	defaultProtectedMode   = "yes"
+	defaultMaxMemoryPolicy = "noeviction"
 )
 
 // Config keys
@@ -34,9 +35,18 @@ const (
 	MaxMemory     = "maxmemory"
 	AutoGC        = "autogc"
 	KeepAlive     = "keepalive"
+
+	NotifyKeyspaceEvents = "notify-keyspace-events"
+	DefaultTimeout       = "default-timeout"
+	MaxMemoryPolicy      = "maxmemory-policy"
+
+	ClientCommandsPerSec = "client-commands-per-sec"
+	ClientBytesPerSec    = "client-bytes-per-sec"
+	MaxHeavyQueries      = "max-heavy-queries"
 )
 
-var validProperties = []string{RequirePass, LeaderAuth, ProtectedMode, MaxMemory, AutoGC, KeepAlive}
+var validProperties = []string{RequirePass, LeaderAuth, ProtectedMode, MaxMemory, MaxMemoryPolicy, AutoGC, KeepAlive, NotifyKeyspaceEvents, DefaultTimeout,
+	ClientCommandsPerSec, ClientBytesPerSec, MaxHeavyQueries}
 
 // Config is a tile38 config
 type Config struct {
@@ -63,6 +73,21 @@ type Config struct {
 	_autoGC         uint64
 	_keepAliveP     string
 	_keepAlive      int64
+
+	_notifyKeyspaceEventsP string
+	_notifyKeyspaceEvents  int
+	_defaultTimeoutP       string
+	_defaultTimeout        float64 // seconds
+	_maxMemoryPolicyP      string
+	_maxMemoryPolicy       string
+
+	// rate limits, zero is unlimited
+	_clientCommandsPerSecP string
+	_clientCommandsPerSec  int64
+	_clientBytesPerSecP    string
+	_clientBytesPerSec     int64
+	_maxHeavyQueriesP      string
+	_maxHeavyQueries       int64
 }
 
 func loadConfig(path string) (*Config, error) {
@@ -91,6 +116,14 @@ func loadConfig(path string) (*Config, error) {
 		_maxMemoryP:     gjson.Get(json, MaxMemory).String(),
 		_autoGCP:        gjson.Get(json, AutoGC).String(),
 		_keepAliveP:     gjson.Get(json, KeepAlive).String(),
+
+		_notifyKeyspaceEventsP: gjson.Get(json, NotifyKeyspaceEvents).String(),
+		_defaultTimeoutP:       gjson.Get(json, DefaultTimeout).String(),
+		_maxMemoryPolicyP:      gjson.Get(json, MaxMemoryPolicy).String(),
+
+		_clientCommandsPerSecP: gjson.Get(json, ClientCommandsPerSec).String(),
+		_clientBytesPerSecP:    gjson.Get(json, ClientBytesPerSec).String(),
+		_maxHeavyQueriesP:      gjson.Get(json, MaxHeavyQueries).String(),
 	}
 	// load properties
 	if err := config.setProperty(RequirePass, config._requirePassP, true); err != nil {
@@ -105,12 +138,30 @@ func loadConfig(path string) (*Config, error) {
 	if err := config.setProperty(MaxMemory, config._maxMemoryP, true); err != nil {
 		return nil, err
 	}
+	if err := config.setProperty(MaxMemoryPolicy, config._maxMemoryPolicyP, true); err != nil {
+		return nil, err
+	}
 	if err := config.setProperty(AutoGC, config._autoGCP, true); err != nil {
 		return nil, err
 	}
 	if err := config.setProperty(KeepAlive, config._keepAliveP, true); err != nil {
 		return nil, err
 	}
+	if err := config.setProperty(NotifyKeyspaceEvents, config._notifyKeyspaceEventsP, true); err != nil {
+		return nil, err
+	}
+	if err := config.setProperty(DefaultTimeout, config._defaultTimeoutP, true); err != nil {
+		return nil, err
+	}
+	if err := config.setProperty(ClientCommandsPerSec, config._clientCommandsPerSecP, true); err != nil {
+		return nil, err
+	}
+	if err := config.setProperty(ClientBytesPerSec, config._clientBytesPerSecP, true); err != nil {
+		return nil, err
+	}
+	if err := config.setProperty(MaxHeavyQueries, config._maxHeavyQueriesP, true); err != nil {
+		return nil, err
+	}
 	config.write(false)
 	return config, nil
 }
@@ -129,6 +180,11 @@ func (config *Config) write(writeProperties bool) {
 			config._protectedModeP = config._protectedMode
 		}
 		config._maxMemoryP = formatMemSize(config._maxMemory)
+		if config._maxMemoryPolicy == defaultMaxMemoryPolicy {
+			config._maxMemoryPolicyP = ""
+		} else {
+			config._maxMemoryPolicyP = config._maxMemoryPolicy
+		}
 		if config._autoGC == 0 {
 			config._autoGCP = ""
 		} else {
@@ -139,6 +195,15 @@ func (config *Config) write(writeProperties bool) {
 		} else {
 			config._keepAliveP = strconv.FormatUint(uint64(config._keepAlive), 10)
 		}
+		config._notifyKeyspaceEventsP = formatNotifyKeyspaceEvents(config._notifyKeyspaceEvents)
+		if config._defaultTimeout == 0 {
+			config._defaultTimeoutP = ""
+		} else {
+			config._defaultTimeoutP = strconv.FormatFloat(config._defaultTimeout, 'f', -1, 64)
+		}
+		config._clientCommandsPerSecP = formatLimit(config._clientCommandsPerSec)
+		config._clientBytesPerSecP = formatLimit(config._clientBytesPerSec)
+		config._maxHeavyQueriesP = formatLimit(config._maxHeavyQueries)
 	}
 
 	m := make(map[string]interface{})
@@ -172,12 +237,30 @@ func (config *Config) write(writeProperties bool) {
 	if config._maxMemoryP != "" {
 		m[MaxMemory] = config._maxMemoryP
 	}
+	if config._maxMemoryPolicyP != "" {
+		m[MaxMemoryPolicy] = config._maxMemoryPolicyP
+	}
 	if config._autoGCP != "" {
 		m[AutoGC] = config._autoGCP
 	}
 	if config._keepAliveP != "" {
 		m[KeepAlive] = config._keepAliveP
 	}
+	if config._notifyKeyspaceEventsP != "" {
+		m[NotifyKeyspaceEvents] = config._notifyKeyspaceEventsP
+	}
+	if config._defaultTimeoutP != "" {
+		m[DefaultTimeout] = config._defaultTimeoutP
+	}
+	if config._clientCommandsPerSecP != "" {
+		m[ClientCommandsPerSec] = config._clientCommandsPerSecP
+	}
+	if config._clientBytesPerSecP != "" {
+		m[ClientBytesPerSec] = config._clientBytesPerSecP
+	}
+	if config._maxHeavyQueriesP != "" {
+		m[MaxHeavyQueries] = config._maxHeavyQueriesP
+	}
 	data, err := json.MarshalIndent(m, "", "\t")
 	if err != nil {
 		panic(err)
@@ -262,6 +345,19 @@ func (config *Config) setProperty(name, value string, fromLoad bool) error {
 			return fmt.Errorf("Invalid argument '%s' for CONFIG SET '%s'", value, name)
 		}
 		config._maxMemory = sz
+	case MaxMemoryPolicy:
+		switch strings.ToLower(value) {
+		case "":
+			if fromLoad {
+				config._maxMemoryPolicy = defaultMaxMemoryPolicy
+			} else {
+				invalid = true
+			}
+		case evictNone, evictVolatileTTL, evictAllKeysLRU, evictVolatileLRU:
+			config._maxMemoryPolicy = strings.ToLower(value)
+		default:
+			invalid = true
+		}
 	case ProtectedMode:
 		switch strings.ToLower(value) {
 		case "":
@@ -286,6 +382,45 @@ func (config *Config) setProperty(name, value string, fromLoad bool) error {
 				config._keepAlive = int64(keepalive)
 			}
 		}
+	case NotifyKeyspaceEvents:
+		flags, ok := parseNotifyKeyspaceEvents(value)
+		if !ok {
+			invalid = true
+		} else {
+			config._notifyKeyspaceEvents = flags
+		}
+	case DefaultTimeout:
+		if value == "" {
+			config._defaultTimeout = 0
+		} else {
+			timeout, err := strconv.ParseFloat(value, 64)
+			if err != nil || timeout < 0 {
+				invalid = true
+			} else {
+				config._defaultTimeout = timeout
+			}
+		}
+	case ClientCommandsPerSec:
+		limit, ok := parseLimit(value)
+		if !ok {
+			invalid = true
+		} else {
+			config._clientCommandsPerSec = limit
+		}
+	case ClientBytesPerSec:
+		limit, ok := parseLimit(value)
+		if !ok {
+			invalid = true
+		} else {
+			config._clientBytesPerSec = limit
+		}
+	case MaxHeavyQueries:
+		limit, ok := parseLimit(value)
+		if !ok {
+			invalid = true
+		} else {
+			config._maxHeavyQueries = limit
+		}
 	}
 
 	if invalid {
@@ -321,8 +456,20 @@ func (config *Config) getProperty(name string) string {
 		return config._protectedMode
 	case MaxMemory:
 		return formatMemSize(config._maxMemory)
+	case MaxMemoryPolicy:
+		return config._maxMemoryPolicy
 	case KeepAlive:
 		return strconv.FormatUint(uint64(config._keepAlive), 10)
+	case NotifyKeyspaceEvents:
+		return formatNotifyKeyspaceEvents(config._notifyKeyspaceEvents)
+	case DefaultTimeout:
+		return strconv.FormatFloat(config._defaultTimeout, 'f', -1, 64)
+	case ClientCommandsPerSec:
+		return strconv.FormatInt(config._clientCommandsPerSec, 10)
+	case ClientBytesPerSec:
+		return strconv.FormatInt(config._clientBytesPerSec, 10)
+	case MaxHeavyQueries:
+		return strconv.FormatInt(config._maxHeavyQueries, 10)
 	}
 }
 
@@ -347,8 +494,7 @@ func (s *Server) cmdConfigGet(msg *Message) (res resp.Value, err error) {
 		}
 		res = resp.StringValue(`{"ok":true,"properties":` + string(data) + `,"elapsed":"` + time.Now().Sub(start).String() + "\"}")
 	case RESP:
-		vals := respValuesSimpleMap(m)
-		res = resp.ArrayValue(vals)
+		res = respSimpleMap(msg, m)
 	}
 	return
 }
@@ -446,6 +592,12 @@ func (config *Config) maxMemory() int {
 	config.mu.RUnlock()
 	return int(v)
 }
+func (config *Config) maxMemoryPolicy() string {
+	config.mu.RLock()
+	v := config._maxMemoryPolicy
+	config.mu.RUnlock()
+	return v
+}
 func (config *Config) autoGC() uint64 {
 	config.mu.RLock()
 	v := config._autoGC
@@ -458,6 +610,36 @@ func (config *Config) keepAlive() int64 {
 	config.mu.RUnlock()
 	return v
 }
+func (config *Config) defaultTimeout() float64 {
+	config.mu.RLock()
+	v := config._defaultTimeout
+	config.mu.RUnlock()
+	return v
+}
+func (config *Config) clientCommandsPerSec() int64 {
+	config.mu.RLock()
+	v := config._clientCommandsPerSec
+	config.mu.RUnlock()
+	return v
+}
+func (config *Config) clientBytesPerSec() int64 {
+	config.mu.RLock()
+	v := config._clientBytesPerSec
+	config.mu.RUnlock()
+	return v
+}
+func (config *Config) maxHeavyQueries() int64 {
+	config.mu.RLock()
+	v := config._maxHeavyQueries
+	config.mu.RUnlock()
+	return v
+}
+func (config *Config) notifyKeyspaceEvents() int {
+	config.mu.RLock()
+	v := config._notifyKeyspaceEvents
+	config.mu.RUnlock()
+	return v
+}
 func (config *Config) setFollowHost(v string) {
 	config.mu.Lock()
 	config._followHost = v
@@ -76,12 +76,12 @@ func (server *Server) cmdBounds(msg *Message) (resp.Value, error) {
 	} else {
 		vals = append(vals, resp.ArrayValue([]resp.Value{
 			resp.ArrayValue([]resp.Value{
-				resp.FloatValue(minX),
-				resp.FloatValue(minY),
+				respDouble(msg, minX),
+				respDouble(msg, minY),
 			}),
 			resp.ArrayValue([]resp.Value{
-				resp.FloatValue(maxX),
-				resp.FloatValue(maxY),
+				respDouble(msg, maxX),
+				respDouble(msg, maxY),
 			}),
 		}))
 	}
@@ -191,14 +191,14 @@ func (server *Server) cmdGet(msg *Message) (resp.Value, error) {
 			}
 			if z != 0 {
 				vals = append(vals, resp.ArrayValue([]resp.Value{
-					resp.StringValue(strconv.FormatFloat(point.Y, 'f', -1, 64)),
-					resp.StringValue(strconv.FormatFloat(point.X, 'f', -1, 64)),
-					resp.StringValue(strconv.FormatFloat(z, 'f', -1, 64)),
+					respDouble(msg, point.Y),
+					respDouble(msg, point.X),
+					respDouble(msg, z),
 				}))
 			} else {
 				vals = append(vals, resp.ArrayValue([]resp.Value{
-					resp.StringValue(strconv.FormatFloat(point.Y, 'f', -1, 64)),
-					resp.StringValue(strconv.FormatFloat(point.X, 'f', -1, 64)),
+					respDouble(msg, point.Y),
+					respDouble(msg, point.X),
 				}))
 			}
 		}
@@ -228,12 +228,12 @@ func (server *Server) cmdGet(msg *Message) (resp.Value, error) {
 			bbox := o.Rect()
 			vals = append(vals, resp.ArrayValue([]resp.Value{
 				resp.ArrayValue([]resp.Value{
-					resp.FloatValue(bbox.Min.Y),
-					resp.FloatValue(bbox.Min.X),
+					respDouble(msg, bbox.Min.Y),
+					respDouble(msg, bbox.Min.X),
 				}),
 				resp.ArrayValue([]resp.Value{
-					resp.FloatValue(bbox.Max.Y),
-					resp.FloatValue(bbox.Max.X),
+					respDouble(msg, bbox.Max.Y),
+					respDouble(msg, bbox.Max.X),
 				}),
 			}))
 		}
@@ -256,14 +256,14 @@ func (server *Server) cmdGet(msg *Message) (resp.Value, error) {
 					}
 					buf.WriteString(jsonString(fv.field) + ":" + strconv.FormatFloat(fv.value, 'f', -1, 64))
 				} else {
-					fvals = append(fvals, resp.StringValue(fv.field), resp.StringValue(strconv.FormatFloat(fv.value, 'f', -1, 64)))
+					fvals = append(fvals, resp.StringValue(fv.field), respDouble(msg, fv.value))
 				}
 				i++
 			}
 			if msg.OutputType == JSON {
 				buf.WriteString(`}`)
 			} else {
-				vals = append(vals, resp.ArrayValue(fvals))
+				vals = append(vals, respMap(msg, fvals))
 			}
 		}
 	}
@@ -304,6 +304,7 @@ func (server *Server) cmdDel(msg *Message) (res resp.Value, d commandDetails, er
 	if col != nil {
 		d.obj, d.fields, ok = col.Delete(d.id)
 		if ok {
+			server.keepHistory(d.key, d.id, d.obj)
 			if col.Count() == 0 {
 				server.deleteCol(d.key)
 			}
@@ -374,6 +375,7 @@ func (server *Server) cmdPdel(msg *Message) (res resp.Value, d commandDetails, e
 				atLeastOneNotDeleted = true
 			} else {
 				d.children[i] = dc
+				server.keepHistory(d.key, dc.id, dc.obj)
 			}
 			server.clearIDExpires(d.key, dc.id)
 		}
@@ -415,13 +417,26 @@ func (server *Server) cmdDrop(msg *Message) (res resp.Value, d commandDetails, e
 		err = errInvalidNumberOfArguments
 		return
 	}
+	var async bool
+	if len(vs) > 0 && strings.ToLower(vs[0]) == "async" {
+		async = true
+		vs = vs[1:]
+	}
 	if len(vs) != 0 {
 		err = errInvalidNumberOfArguments
 		return
 	}
 	col := server.getCol(d.key)
+	_, hasConfig := server.keyConfigs[d.key]
+	server.deleteKeyConfig(d.key)
 	if col != nil {
 		server.deleteCol(d.key)
+		if async {
+			server.lazyfree([]*collection.Collection{col})
+		}
+		d.updated = true
+	} else if hasConfig {
+		// only the configuration of the key is dropped
 		d.updated = true
 	} else {
 		d.key = "" // ignore the details
@@ -485,6 +500,7 @@ func (server *Server) cmdRename(msg *Message, nx bool) (res resp.Value, d comman
 		server.deleteCol(d.key)
 		server.setCol(d.newKey, col)
 		server.moveKeyExpires(d.key, d.newKey)
+		server.moveKeyConfig(d.key, d.newKey)
 	}
 	d.timestamp = time.Now()
 	switch msg.OutputType {
@@ -505,12 +521,27 @@ func (server *Server) cmdRename(msg *Message, nx bool) (res resp.Value, d comman
 func (server *Server) cmdFlushDB(msg *Message) (res resp.Value, d commandDetails, err error) {
 	start := time.Now()
 	vs := msg.Args[1:]
+	var async bool
+	if len(vs) > 0 && strings.ToLower(vs[0]) == "async" {
+		async = true
+		vs = vs[1:]
+	}
 	if len(vs) != 0 {
 		err = errInvalidNumberOfArguments
 		return
 	}
+	if async {
+		var cols []*collection.Collection
+		server.cols.Ascend(nil, func(v interface{}) bool {
+			cols = append(cols, v.(*collectionKeyContainer).col)
+			return true
+		})
+		server.lazyfree(cols)
+	}
 	server.cols = btree.New(byCollectionKey)
 	server.expires = rhh.New(0)
+	server.keyConfigs = make(map[string]*keyConfig)
+	server.histories = make(map[string]map[string][]geojson.Object)
 	server.hooks = make(map[string]*Hook)
 	server.hooksOut = make(map[string]*Hook)
 	server.hookTree = rtree.RTree{}
@@ -527,13 +558,71 @@ func (server *Server) cmdFlushDB(msg *Message) (res resp.Value, d commandDetails
 	return
 }
 
+// fieldCond is a condition on the stored value of a field that must hold
+// for a conditional write to happen.
+type fieldCond struct {
+	field string
+	op    string
+	value float64
+}
+
+// match returns true when the stored field value satisfies the condition.
+func (c fieldCond) match(value float64) bool {
+	switch c.op {
+	case ">":
+		return value > c.value
+	case ">=":
+		return value >= c.value
+	case "<":
+		return value < c.value
+	case "<=":
+		return value <= c.value
+	case "==":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	}
+	return false
+}
+
+// matchConds returns true when all the conditions hold for the fields of an
+// existing object.
+func matchConds(col *collection.Collection, fields []float64,
+	conds []fieldCond,
+) bool {
+	fmap := col.FieldMap()
+	for _, c := range conds {
+		var value float64
+		if idx, ok := fmap[c.field]; ok && idx < len(fields) {
+			value = fields[idx]
+		}
+		if !c.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// skippedMessage is the reply for a conditional write that did not happen.
+func skippedMessage(msg *Message, start time.Time) resp.Value {
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"skipped":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}")
+	case RESP:
+		return resp.SimpleStringValue("SKIPPED")
+	}
+	return NOMessage
+}
+
 func (server *Server) parseSetArgs(vs []string) (
 	d commandDetails, fields []string, values []float64,
-	xx, nx bool,
+	xx, nx bool, conds []fieldCond,
 	expires *float64, etype []byte, evs []string, err error,
 ) {
 	var ok bool
 	var typ []byte
+	var newer []string
 	if vs, d.key, ok = tokenval(vs); !ok || d.key == "" {
 		err = errInvalidNumberOfArguments
 		return
@@ -613,8 +702,52 @@ func (server *Server) parseSetArgs(vs []string) (
 			nx = true
 			continue
 		}
+		if lcb(arg, "ifnewer") {
+			vs = nvs
+			var name string
+			if vs, name, ok = tokenval(vs); !ok || name == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			newer = append(newer, name)
+			continue
+		}
+		if lcb(arg, "ifgt") {
+			vs = nvs
+			var name, svalue string
+			var value float64
+			if vs, name, ok = tokenval(vs); !ok || name == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			if vs, svalue, ok = tokenval(vs); !ok || svalue == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			value, err = strconv.ParseFloat(svalue, 64)
+			if err != nil {
+				err = errInvalidArgument(svalue)
+				return
+			}
+			conds = append(conds, fieldCond{field: name, op: "<", value: value})
+			continue
+		}
 		break
 	}
+	for _, name := range newer {
+		// the incoming value of the field must be newer than the stored one
+		idx := -1
+		for i, field := range fields {
+			if field == name {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			err = errInvalidArgument(name)
+			return
+		}
+		conds = append(conds, fieldCond{field: name, op: "<", value: values[idx]})
+	}
 	if vs, typ, ok = tokenvalbytes(vs); !ok || len(typ) == 0 {
 		err = errInvalidNumberOfArguments
 		return
@@ -736,7 +869,7 @@ func (server *Server) parseSetArgs(vs []string) (
 			err = errInvalidNumberOfArguments
 			return
 		}
-		d.obj, err = geojson.Parse(object, &server.geomParseOpts)
+		d.obj, err = geojson.Parse(object, server.keyParseOpts(d.key))
 		if err != nil {
 			return
 		}
@@ -758,29 +891,48 @@ func (server *Server) cmdSet(msg *Message, resetExpires bool) (res resp.Value, d
 	var fields []string
 	var values []float64
 	var xx, nx bool
+	var conds []fieldCond
 	var ex *float64
-	d, fields, values, xx, nx, ex, _, _, err = server.parseSetArgs(vs)
+	d, fields, values, xx, nx, conds, ex, _, _, err = server.parseSetArgs(vs)
 	if err != nil {
 		return
 	}
+	var createcol bool
 	col := server.getCol(d.key)
 	if col == nil {
 		if xx {
 			goto notok
 		}
+		// the collection is added once the object can be set
 		col = collection.New()
-		server.setCol(d.key, col)
+		createcol = true
 	}
-	if xx || nx {
-		_, _, ok := col.Get(d.id)
+	if xx || nx || len(conds) > 0 {
+		_, ofields, ok := col.Get(d.id)
 		if (nx && ok) || (xx && !ok) {
 			goto notok
 		}
+		if ok && !matchConds(col, ofields, conds) {
+			res = skippedMessage(msg, start)
+			return
+		}
+	}
+	if err = server.checkMaxObjects(d.key, d.id, col); err != nil {
+		return
+	}
+	if createcol {
+		server.setCol(d.key, col)
 	}
 	if resetExpires {
 		server.clearIDExpires(d.key, d.id)
 	}
+	if ex == nil {
+		if ttl := server.keyDefaultTTL(d.key); ttl > 0 {
+			ex = &ttl
+		}
+	}
 	d.oldObj, d.oldFields, d.fields = col.Set(d.id, d.obj, fields, values)
+	server.keepHistory(d.key, d.id, d.oldObj)
 	d.command = "set"
 	d.updated = true // perhaps we should do a diff on the previous object?
 	d.timestamp = time.Now()
@@ -820,7 +972,8 @@ notok:
 }
 
 func (server *Server) parseFSetArgs(vs []string) (
-	d commandDetails, fields []string, values []float64, xx bool, err error,
+	d commandDetails, fields []string, values []float64, xx bool,
+	conds []fieldCond, err error,
 ) {
 	var ok bool
 	if vs, d.key, ok = tokenval(vs); !ok || d.key == "" {
@@ -841,6 +994,35 @@ func (server *Server) parseFSetArgs(vs []string) (
 			xx = true
 			continue
 		}
+		if lc(name, "if") {
+			var c fieldCond
+			var svalue string
+			if vs, c.field, ok = tokenval(vs); !ok || c.field == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			if vs, c.op, ok = tokenval(vs); !ok || c.op == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			switch c.op {
+			case ">", ">=", "<", "<=", "==", "!=":
+			default:
+				err = errInvalidArgument(c.op)
+				return
+			}
+			if vs, svalue, ok = tokenval(vs); !ok || svalue == "" {
+				err = errInvalidNumberOfArguments
+				return
+			}
+			c.value, err = strconv.ParseFloat(svalue, 64)
+			if err != nil {
+				err = errInvalidArgument(svalue)
+				return
+			}
+			conds = append(conds, c)
+			continue
+		}
 		if isReservedFieldName(name) {
 			err = errInvalidArgument(name)
 			return
@@ -872,14 +1054,24 @@ func (server *Server) cmdFset(msg *Message) (res resp.Value, d commandDetails, e
 	var fields []string
 	var values []float64
 	var xx bool
+	var conds []fieldCond
 	var updateCount int
-	d, fields, values, xx, err = server.parseFSetArgs(vs)
-
+	d, fields, values, xx, conds, err = server.parseFSetArgs(vs)
+	if err != nil {
+		return
+	}
 	col := server.getCol(d.key)
 	if col == nil {
 		err = errKeyNotFound
 		return
 	}
+	if len(conds) > 0 {
+		_, ofields, ok := col.Get(d.id)
+		if ok && !matchConds(col, ofields, conds) {
+			res = skippedMessage(msg, start)
+			return
+		}
+	}
 	var ok bool
 	d.obj, d.fields, updateCount, ok = col.SetFields(d.id, fields, values)
 	if !(ok || xx) {
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/tidwall/rhh"
+	"github.com/tidwall/tile38/internal/collection"
+	"github.com/tidwall/tile38/internal/log"
+)
+
+// The maxmemory-policy values
+const (
+	evictNone        = "noeviction"   // writes fail with errOOM
+	evictVolatileTTL = "volatile-ttl" // objects with the nearest expiry
+	evictAllKeysLRU  = "allkeys-lru"  // least recently accessed objects
+	evictVolatileLRU = "volatile-lru" // least recently accessed objects with a TTL
+)
+
+// evictSamples is the number of objects that are sampled for each eviction.
+// Like Redis, the policies are approximated by evicting the best object of a
+// small sample.
+const evictSamples = 5
+
+// evict evicts objects, following the maxmemory-policy, until their weight
+// reaches the target number of bytes. It returns the number of evicted
+// objects, which is zero when there is nothing left to evict. A follower
+// never evicts.
+func (s *Server) evict(policy string, target int) (evicted int) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.followHost() != "" {
+		return 0
+	}
+	var freed int
+	for freed < target {
+		key, id, ok := s.evictionCandidate(rng, policy)
+		if !ok {
+			break
+		}
+		col := s.getCol(key)
+		if col == nil {
+			// a stale expires entry
+			s.clearIDExpires(key, id)
+			continue
+		}
+		weight := col.TotalWeight()
+		msg := &Message{}
+		msg.Args = []string{"del", key, id}
+		fmap := col.FieldMap()
+		_, d, err := s.cmdDel(msg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !d.updated {
+			continue
+		}
+		freed += weight - col.TotalWeight()
+		// the geofences see the delete as an "evict" event, which is like
+		// an "expire" event.
+		d.command = "evict"
+		d.fmap = fmap
+		if err := s.writeAOF(msg.Args, &d); err != nil {
+			log.Fatal(err)
+		}
+		s.statsEvicted.add(1)
+		evicted++
+		if evicted%bgExpireSegmentSize == 0 {
+			// recycle the lock
+			s.mu.Unlock()
+			s.mu.Lock()
+		}
+	}
+	return evicted
+}
+
+// evictionCandidate returns the object that the policy evicts next.
+func (s *Server) evictionCandidate(rng *rand.Rand, policy string) (
+	key, id string, ok bool,
+) {
+	var best int64
+	switch policy {
+	case evictVolatileTTL, evictVolatileLRU:
+		if s.expires.Len() == 0 {
+			return "", "", false
+		}
+		for i := 0; i < evictSamples; i++ {
+			skey, idm, sok := s.expires.GetPos(rng.Uint64())
+			if !sok {
+				continue
+			}
+			sid, atv, sok := idm.(*rhh.Map).GetPos(rng.Uint64())
+			if !sok {
+				continue
+			}
+			score := atv.(int64)
+			if policy == evictVolatileLRU {
+				col := s.getCol(skey)
+				if col == nil {
+					return skey, sid, true
+				}
+				if score, sok = col.Access(sid); !sok {
+					return skey, sid, true
+				}
+			}
+			if !ok || score < best {
+				key, id, best, ok = skey, sid, score, true
+			}
+		}
+	case evictAllKeysLRU:
+		var count int
+		var last string
+		iter := func(ckey string, col *collection.Collection) bool {
+			if sid, access, sok := col.EvictionSample(evictSamples); sok {
+				if !ok || access < best {
+					key, id, best, ok = ckey, sid, access, true
+				}
+			}
+			last = ckey
+			count++
+			return count < evictSamples
+		}
+		// the collections are sampled like the objects of a collection
+		start := s.evictNext
+		if start != "" {
+			s.scanGreaterOrEqual(start, iter)
+		}
+		if count < evictSamples {
+			s.scanGreaterOrEqual("", func(ckey string,
+				col *collection.Collection) bool {
+				if start != "" && ckey >= start {
+					return false
+				}
+				return iter(ckey, col)
+			})
+		}
+		if count > 0 {
+			s.evictNext = last + "\x00"
+		}
+	}
+	return key, id, ok
+}
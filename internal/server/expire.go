@@ -85,10 +85,17 @@ func (s *Server) expirePurgeSweep(rng *rand.Rand) (purged int) {
 					// expired, purge from database
 					msg := &Message{}
 					msg.Args = []string{"del", key, id}
+					var fmap map[string]int
+					if col := s.getCol(key); col != nil {
+						fmap = col.FieldMap()
+					}
 					_, d, err := s.cmdDel(msg)
 					if err != nil {
 						log.Fatal(err)
 					}
+					// the geofences see the delete as an "expire" event
+					d.command = "expire"
+					d.fmap = fmap
 					if err := s.writeAOF(msg.Args, &d); err != nil {
 						log.Fatal(err)
 					}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/collection"
+	"github.com/tidwall/tile38/internal/geofile"
+	"github.com/tidwall/tile38/internal/log"
+)
+
+// filesDir is the directory, inside of the data directory, that holds the
+// files of IMPORT and EXPORT.
+const filesDir = "files"
+
+var errInvalidPath = errors.New("path must be relative to the files directory")
+
+// filePath returns the path of an IMPORT or EXPORT file. The path must be
+// relative to the files directory and it must stay inside of it, also after
+// following symlinks, so that a client can't read or write any other file.
+func (server *Server) filePath(path string) (string, error) {
+	if path == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", errInvalidPath
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if elem == ".." {
+			return "", errInvalidPath
+		}
+	}
+	root := filepath.Join(server.dir, filesDir)
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Join(root, path)
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errInvalidPath
+	}
+	path = filepath.Join(dir, filepath.Base(path))
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return "", errInvalidPath
+	}
+	return path, nil
+}
+
+// EXPORT key TO path FORMAT geojson|ndjson|csv [WHERE ...]
+func (server *Server) cmdExport(msg *Message) (res resp.Value, err error) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	var key, path, format, tok string
+	var ok bool
+	if vs, key, ok = tokenval(vs); !ok || key == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, tok, ok = tokenval(vs); !ok || strings.ToLower(tok) != "to" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, path, ok = tokenval(vs); !ok || path == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, tok, ok = tokenval(vs); !ok || strings.ToLower(tok) != "format" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, format, ok = tokenval(vs); !ok || format == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	format = strings.ToLower(format)
+	switch format {
+	case geofile.GeoJSON, geofile.NDJSON, geofile.CSV:
+	default:
+		return NOMessage, errInvalidArgument(format)
+	}
+	var t liveFenceSwitches
+	vs, t.searchScanBaseTokens, err = server.parseSearchScanBaseTokens("export",
+		t.searchScanBaseTokens, append([]string{key}, vs...))
+	if t.usingLua() {
+		defer t.Close()
+		defer func() {
+			if r := recover(); r != nil {
+				res = NOMessage
+				err = errors.New(r.(string))
+				return
+			}
+		}()
+	}
+	if err != nil {
+		return NOMessage, err
+	}
+	if len(vs) != 0 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if t.fence || t.cursor != 0 || t.ulimit || t.usparse || t.orderBy != "" {
+		return NOMessage,
+			errors.New("only WHERE and MATCH filters are allowed for EXPORT")
+	}
+	path, err = server.filePath(path)
+	if err != nil {
+		return NOMessage, err
+	}
+	items, farr, err := server.exportSnapshot(msg, t.searchScanBaseTokens)
+	if err != nil {
+		return NOMessage, err
+	}
+	log.Infof("export: %s %d objects to %s", key, len(items), path)
+	if err := writeExport(path, format, items, farr); err != nil {
+		return NOMessage, err
+	}
+	switch msg.OutputType {
+	case JSON:
+		res = resp.StringValue(`{"ok":true,"exported":` +
+			strconv.Itoa(len(items)) + `,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}")
+	case RESP:
+		res = resp.IntegerValue(len(items))
+	}
+	return res, nil
+}
+
+// exportSnapshot collects the matching objects while holding the read lock,
+// so that the export has the contents of the collection at a single point
+// in time. Objects are immutable and are shared with the collection, but
+// the field values are copied. String values are not geographic and are
+// skipped.
+func (server *Server) exportSnapshot(msg *Message, t searchScanBaseTokens) (
+	items []geofile.Item, farr []string, err error,
+) {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	sw, err := server.newScanWriter(nil, msg, t.key, outputObjects, 0,
+		t.glob, false, 0, 0, t.wheres, t.whereins, t.whereevals, t.whereexprs,
+		t.wherejsons, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sw.col == nil {
+		return nil, nil, errKeyNotFound
+	}
+	sw.col.Scan(false, nil, nil,
+		func(id string, o geojson.Object, fields []float64) bool {
+			if _, ok := o.(collection.String); ok {
+				return true
+			}
+			if server.hasExpired(t.key, id) {
+				return true
+			}
+			ok, keepGoing, _ := sw.testObject(id, o, fields, false)
+			if ok {
+				items = append(items, geofile.Item{
+					ID:     id,
+					Object: o,
+					Fields: append([]float64(nil), fields...),
+				})
+			}
+			return keepGoing
+		},
+	)
+	return items, append([]string(nil), sw.farr...), nil
+}
+
+// writeExport writes the items to a new temporary file that replaces the
+// file at path once it's complete.
+func writeExport(path, format string, items []geofile.Item, farr []string,
+) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := geofile.Write(f, format, items, farr); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -83,9 +83,12 @@ func fenceMatch(
 			return nil
 		}
 	}
-	if details.command == "del" {
+	expired := details.command == "expire" || details.command == "evict"
+	if details.command == "del" || (expired &&
+		fence != nil && fence.roam.on) {
 		return []string{
-			`{"command":"del"` + hookJSONString(hookName, metas) +
+			`{"command":` + jsonString(details.command) +
+				hookJSONString(hookName, metas) +
 				`,"key":` + jsonString(details.key) +
 				`,"id":` + jsonString(details.id) +
 				`,"time":` + jsonTimeFormat(details.timestamp) + `}`,
@@ -104,6 +107,11 @@ func fenceMatch(
 				return nil
 			}
 			detect = "roam"
+		} else if expired {
+			// the object is gone, so it leaves the fence it was in
+			if fenceMatchObject(fence, details.obj) {
+				detect = "exit"
+			}
 		} else {
 			// not using roaming
 			match1 := fenceMatchObject(fence, details.oldObj)
@@ -206,6 +214,12 @@ func fenceMatch(
 	} else if detect == "cross" {
 		group = bsonID()
 		delete(fence.groups, groupkey)
+	} else if expired {
+		group, ok = fence.groups[groupkey]
+		if !ok {
+			group = bsonID()
+		}
+		delete(fence.groups, groupkey)
 	} else {
 		group, ok = fence.groups[groupkey]
 		if !ok {
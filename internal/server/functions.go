@@ -0,0 +1,691 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/glob"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// libraryLoadTimeout is the time that the code of a library has to register
+// its functions.
+const libraryLoadTimeout = time.Second * 5
+
+var errFunctionNotFound = errors.New("function not found")
+var errLibraryNotFound = errors.New("library not found")
+
+// luaLibrary is a named Lua library loaded by FUNCTION LOAD.
+type luaLibrary struct {
+	name  string
+	code  string
+	sha   string
+	proto *lua.FunctionProto
+	funcs []luaFunction // sorted by name
+}
+
+// luaFunction is a function registered by a library.
+type luaFunction struct {
+	name     string
+	noWrites bool
+}
+
+func (lib *luaLibrary) function(name string) (luaFunction, bool) {
+	i := sort.Search(len(lib.funcs), func(i int) bool {
+		return lib.funcs[i].name >= name
+	})
+	if i < len(lib.funcs) && lib.funcs[i].name == name {
+		return lib.funcs[i], true
+	}
+	return luaFunction{}, false
+}
+
+// lFunctionMap holds the loaded libraries. It's guarded by the server mutex.
+type lFunctionMap struct {
+	libs  map[string]*luaLibrary // library name
+	funcs map[string]*luaLibrary // function name
+}
+
+func newFunctionMap() *lFunctionMap {
+	return &lFunctionMap{
+		libs:  make(map[string]*luaLibrary),
+		funcs: make(map[string]*luaLibrary),
+	}
+}
+
+func (fm *lFunctionMap) copy() *lFunctionMap {
+	nfm := newFunctionMap()
+	for name, lib := range fm.libs {
+		nfm.libs[name] = lib
+	}
+	for name, lib := range fm.funcs {
+		nfm.funcs[name] = lib
+	}
+	return nfm
+}
+
+func (fm *lFunctionMap) delete(name string) bool {
+	lib := fm.libs[name]
+	if lib == nil {
+		return false
+	}
+	for _, f := range lib.funcs {
+		delete(fm.funcs, f.name)
+	}
+	delete(fm.libs, name)
+	return true
+}
+
+// add adds a library, replacing the library with the same name when replace
+// is set. Function names must be unique across libraries.
+func (fm *lFunctionMap) add(lib *luaLibrary, replace bool) error {
+	if fm.libs[lib.name] != nil && !replace {
+		return fmt.Errorf("library '%s' already exists", lib.name)
+	}
+	for _, f := range lib.funcs {
+		if other := fm.funcs[f.name]; other != nil && other.name != lib.name {
+			return fmt.Errorf("function '%s' already exists", f.name)
+		}
+	}
+	fm.delete(lib.name)
+	fm.libs[lib.name] = lib
+	for _, f := range lib.funcs {
+		fm.funcs[f.name] = lib
+	}
+	return nil
+}
+
+// sorted returns the libraries ordered by name.
+func (fm *lFunctionMap) sorted() []*luaLibrary {
+	libs := make([]*luaLibrary, 0, len(fm.libs))
+	for _, lib := range fm.libs {
+		libs = append(libs, lib)
+	}
+	sort.Slice(libs, func(i, j int) bool {
+		return libs[i].name < libs[j].name
+	})
+	return libs
+}
+
+func validFunctionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+// registerFunction is tile38.register_function, which is available to the
+// code of a library while it's loaded. It takes either a name and a
+// callback, or a table with the function_name, callback and optional flags
+// fields. The only flag is "no-writes".
+func registerFunction(ls *lua.LState) int {
+	reg, ok := ls.G.Registry.RawGetString("tile38_register").(*lua.LTable)
+	if !ok {
+		ls.RaiseError("register_function can only be called when loading a library")
+		return 0
+	}
+	var name lua.LValue
+	var callback lua.LValue
+	var flags lua.LValue = lua.LNil
+	if tbl, ok := ls.Get(1).(*lua.LTable); ok {
+		name = tbl.RawGetString("function_name")
+		callback = tbl.RawGetString("callback")
+		flags = tbl.RawGetString("flags")
+	} else {
+		name = ls.Get(1)
+		callback = ls.Get(2)
+	}
+	if name.Type() != lua.LTString || !validFunctionName(name.String()) {
+		ls.RaiseError("invalid function name")
+		return 0
+	}
+	if callback.Type() != lua.LTFunction {
+		ls.RaiseError("invalid callback for function '%s'", name.String())
+		return 0
+	}
+	if reg.RawGetString(name.String()) != lua.LNil {
+		ls.RaiseError("function '%s' already registered", name.String())
+		return 0
+	}
+	var noWrites bool
+	switch flags := flags.(type) {
+	case *lua.LNilType:
+	case *lua.LTable:
+		flags.ForEach(func(_, v lua.LValue) {
+			if v.Type() == lua.LTString && v.String() == "no-writes" {
+				noWrites = true
+			} else {
+				ls.RaiseError("unknown flag '%s'", v.String())
+			}
+		})
+	default:
+		ls.RaiseError("invalid flags for function '%s'", name.String())
+		return 0
+	}
+	entry := ls.CreateTable(0, 2)
+	entry.RawSetString("callback", callback)
+	entry.RawSetString("no_writes", lua.LBool(noWrites))
+	reg.RawSetString(name.String(), entry)
+	return 0
+}
+
+// runLibrary runs the code of a library and returns the table of the
+// functions that it registered.
+func runLibrary(ls *lua.LState, lib *luaLibrary) (*lua.LTable, error) {
+	reg := ls.NewTable()
+	ls.G.Registry.RawSetString("tile38_register", reg)
+	defer ls.G.Registry.RawSetString("tile38_register", lua.LNil)
+	ctx, cancel := context.WithTimeout(context.Background(), libraryLoadTimeout)
+	defer cancel()
+	ls.SetContext(ctx)
+	defer ls.RemoveContext()
+	ls.Push(&lua.LFunction{
+		Env:      ls.Env,
+		Proto:    lib.proto,
+		Upvalues: make([]*lua.Upvalue, 0),
+	})
+	if err := ls.PCall(0, 0, nil); err != nil {
+		return nil, makeSafeErr(err)
+	}
+	return reg, nil
+}
+
+// compileLibrary compiles the code of a library and runs it to find the
+// functions that it registers. The code must start with a "#!lua name=<lib>"
+// line.
+func (s *Server) compileLibrary(code string) (*luaLibrary, error) {
+	var header, body string
+	if i := strings.IndexByte(code, '\n'); i == -1 {
+		header = code
+	} else {
+		// keep the newline so that the line numbers stay the same
+		header, body = code[:i], code[i:]
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 0 || fields[0] != "#!lua" {
+		return nil, errors.New("missing library metadata")
+	}
+	lib := &luaLibrary{code: code, sha: Sha1Sum(code)}
+	for _, field := range fields[1:] {
+		if !strings.HasPrefix(field, "name=") {
+			return nil, fmt.Errorf("invalid metadata value '%s'", field)
+		}
+		lib.name = field[len("name="):]
+	}
+	if !validFunctionName(lib.name) {
+		return nil, errors.New("library name must be set with 'name=<name>'")
+	}
+	ls, err := s.luapool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.luapool.Put(ls)
+	fn, err := ls.Load(strings.NewReader(body), "lib_"+lib.name)
+	if err != nil {
+		return nil, makeSafeErr(err)
+	}
+	lib.proto = fn.Proto
+	reg, err := runLibrary(ls, lib)
+	if err != nil {
+		return nil, err
+	}
+	reg.ForEach(func(k, v lua.LValue) {
+		lib.funcs = append(lib.funcs, luaFunction{
+			name:     k.String(),
+			noWrites: v.(*lua.LTable).RawGetString("no_writes") == lua.LTrue,
+		})
+	})
+	if len(lib.funcs) == 0 {
+		return nil, errors.New("no functions registered")
+	}
+	sort.Slice(lib.funcs, func(i, j int) bool {
+		return lib.funcs[i].name < lib.funcs[j].name
+	})
+	return lib, nil
+}
+
+// isFunctionWrite returns true for the FUNCTION subcommands that change the
+// libraries.
+func isFunctionWrite(msg *Message) bool {
+	if len(msg.Args) < 2 {
+		return false
+	}
+	switch strings.ToLower(msg.Args[1]) {
+	case "load", "delete", "flush", "restore":
+		return true
+	}
+	return false
+}
+
+// FUNCTION LOAD|LIST|DELETE|FLUSH|DUMP|RESTORE ...
+func (s *Server) cmdFunction(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	if len(msg.Args) < 2 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	switch strings.ToLower(msg.Args[1]) {
+	case "load":
+		return s.cmdFunctionLoad(msg)
+	case "delete":
+		return s.cmdFunctionDelete(msg)
+	case "flush":
+		return s.cmdFunctionFlush(msg)
+	case "restore":
+		return s.cmdFunctionRestore(msg)
+	case "list":
+		res, err = s.cmdFunctionList(msg)
+		return res, d, err
+	case "dump":
+		res, err = s.cmdFunctionDump(msg)
+		return res, d, err
+	}
+	return NOMessage, d, errInvalidArgument(msg.Args[1])
+}
+
+// FUNCTION LOAD [REPLACE] code
+func (s *Server) cmdFunctionLoad(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	vs := msg.Args[2:]
+	var replace bool
+	if len(vs) == 2 && strings.ToLower(vs[0]) == "replace" {
+		replace = true
+		vs = vs[1:]
+	}
+	if len(vs) != 1 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	lib, err := s.compileLibrary(vs[0])
+	if err != nil {
+		return NOMessage, d, err
+	}
+	fm := s.luafuncs.copy()
+	if err := fm.add(lib, replace); err != nil {
+		return NOMessage, d, err
+	}
+	if err := s.checkHookFunctions(fm); err != nil {
+		return NOMessage, d, err
+	}
+	s.luafuncs = fm
+	d.updated = true
+	d.timestamp = time.Now()
+	switch msg.OutputType {
+	case JSON:
+		res = resp.StringValue(`{"ok":true,"library":` + jsonString(lib.name) +
+			`,"elapsed":"` + time.Now().Sub(start).String() + "\"}")
+	case RESP:
+		res = resp.StringValue(lib.name)
+	}
+	return res, d, nil
+}
+
+// FUNCTION DELETE library
+func (s *Server) cmdFunctionDelete(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	if len(msg.Args) != 3 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	fm := s.luafuncs.copy()
+	if !fm.delete(msg.Args[2]) {
+		return NOMessage, d, errLibraryNotFound
+	}
+	if err := s.checkHookFunctions(fm); err != nil {
+		return NOMessage, d, err
+	}
+	s.luafuncs = fm
+	d.updated = true
+	d.timestamp = time.Now()
+	return OKMessage(msg, start), d, nil
+}
+
+// FUNCTION FLUSH
+func (s *Server) cmdFunctionFlush(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	if len(msg.Args) != 2 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	fm := newFunctionMap()
+	if err := s.checkHookFunctions(fm); err != nil {
+		return NOMessage, d, err
+	}
+	s.luafuncs = fm
+	d.updated = true
+	d.timestamp = time.Now()
+	return OKMessage(msg, start), d, nil
+}
+
+// FUNCTION LIST [LIBRARYNAME pattern] [WITHCODE]
+func (s *Server) cmdFunctionList(msg *Message) (res resp.Value, err error) {
+	start := time.Now()
+	vs := msg.Args[2:]
+	pattern := "*"
+	var withCode bool
+	for len(vs) > 0 {
+		switch strings.ToLower(vs[0]) {
+		case "libraryname":
+			if len(vs) < 2 {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			pattern = vs[1]
+			vs = vs[2:]
+		case "withcode":
+			withCode = true
+			vs = vs[1:]
+		default:
+			return NOMessage, errInvalidArgument(vs[0])
+		}
+	}
+	var libs []*luaLibrary
+	for _, lib := range s.luafuncs.sorted() {
+		if match, _ := glob.Match(pattern, lib.name); match {
+			libs = append(libs, lib)
+		}
+	}
+	flags := func(f luaFunction) []string {
+		if f.noWrites {
+			return []string{"no-writes"}
+		}
+		return []string{}
+	}
+	switch msg.OutputType {
+	case JSON:
+		var buf bytes.Buffer
+		buf.WriteString(`{"ok":true,"libraries":[`)
+		for i, lib := range libs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"library_name":` + jsonString(lib.name))
+			buf.WriteString(`,"functions":[`)
+			for i, f := range lib.funcs {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				b, _ := json.Marshal(flags(f))
+				buf.WriteString(`{"name":` + jsonString(f.name) +
+					`,"flags":` + string(b) + `}`)
+			}
+			buf.WriteByte(']')
+			if withCode {
+				buf.WriteString(`,"library_code":` + jsonString(lib.code))
+			}
+			buf.WriteByte('}')
+		}
+		buf.WriteString(`],"elapsed":"` + time.Now().Sub(start).String() + "\"}")
+		return resp.StringValue(buf.String()), nil
+	case RESP:
+		var vals []resp.Value
+		for _, lib := range libs {
+			var fvals []resp.Value
+			for _, f := range lib.funcs {
+				var flvals []resp.Value
+				for _, flag := range flags(f) {
+					flvals = append(flvals, resp.StringValue(flag))
+				}
+				fvals = append(fvals, resp.ArrayValue([]resp.Value{
+					resp.StringValue("name"), resp.StringValue(f.name),
+					resp.StringValue("flags"), resp.ArrayValue(flvals),
+				}))
+			}
+			lvals := []resp.Value{
+				resp.StringValue("library_name"), resp.StringValue(lib.name),
+				resp.StringValue("functions"), resp.ArrayValue(fvals),
+			}
+			if withCode {
+				lvals = append(lvals, resp.StringValue("library_code"),
+					resp.StringValue(lib.code))
+			}
+			vals = append(vals, resp.ArrayValue(lvals))
+		}
+		return resp.ArrayValue(vals), nil
+	}
+	return NOMessage, nil
+}
+
+// FUNCTION DUMP returns the code of all libraries as a JSON array that can
+// be passed to FUNCTION RESTORE.
+func (s *Server) cmdFunctionDump(msg *Message) (res resp.Value, err error) {
+	start := time.Now()
+	if len(msg.Args) != 2 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	codes := []string{}
+	for _, lib := range s.luafuncs.sorted() {
+		codes = append(codes, lib.code)
+	}
+	payload, _ := json.Marshal(codes)
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"payload":` +
+			jsonString(string(payload)) + `,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return resp.StringValue(string(payload)), nil
+	}
+	return NOMessage, nil
+}
+
+// FUNCTION RESTORE payload [FLUSH|APPEND|REPLACE]
+func (s *Server) cmdFunctionRestore(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	vs := msg.Args[2:]
+	if len(vs) != 1 && len(vs) != 2 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	policy := "append"
+	if len(vs) == 2 {
+		policy = strings.ToLower(vs[1])
+		switch policy {
+		case "flush", "append", "replace":
+		default:
+			return NOMessage, d, errInvalidArgument(vs[1])
+		}
+	}
+	var codes []string
+	if err := json.Unmarshal([]byte(vs[0]), &codes); err != nil {
+		return NOMessage, d, errors.New("invalid payload")
+	}
+	// restore into a copy so that nothing changes when a library fails
+	fm := s.luafuncs.copy()
+	if policy == "flush" {
+		fm = newFunctionMap()
+	}
+	for _, code := range codes {
+		lib, err := s.compileLibrary(code)
+		if err != nil {
+			return NOMessage, d, err
+		}
+		if err := fm.add(lib, policy == "replace"); err != nil {
+			return NOMessage, d, err
+		}
+	}
+	if err := s.checkHookFunctions(fm); err != nil {
+		return NOMessage, d, err
+	}
+	s.luafuncs = fm
+	d.updated = true
+	d.timestamp = time.Now()
+	return OKMessage(msg, start), d, nil
+}
+
+// luaFunctionCallback returns the callback of a function of a library. The
+// registered functions are kept in the registry of each state and are
+// registered again when the library has changed.
+func luaFunctionCallback(luaState *lua.LState, lib *luaLibrary, name string) (
+	lua.LValue, error,
+) {
+	libs, ok := luaState.G.Registry.RawGetString("tile38_libs").(*lua.LTable)
+	if !ok {
+		libs = luaState.NewTable()
+		luaState.G.Registry.RawSetString("tile38_libs", libs)
+	}
+	cached, ok := libs.RawGetString(lib.name).(*lua.LTable)
+	if !ok || cached.RawGetString("sha").String() != lib.sha {
+		reg, err := runLibrary(luaState, lib)
+		if err != nil {
+			return nil, err
+		}
+		cached = luaState.NewTable()
+		cached.RawSetString("sha", lua.LString(lib.sha))
+		cached.RawSetString("functions", reg)
+		libs.RawSetString(lib.name, cached)
+	}
+	entry := cached.RawGetString("functions").(*lua.LTable).RawGetString(name)
+	return entry.(*lua.LTable).RawGetString("callback"), nil
+}
+
+// hookFunction returns an error when a function can't transform the events
+// of a hook. Hook functions may only read, so they must have the no-writes
+// flag.
+func hookFunction(fm *lFunctionMap, name string) error {
+	lib := fm.funcs[name]
+	if lib == nil {
+		return errFunctionNotFound
+	}
+	if f, _ := lib.function(name); !f.noWrites {
+		return fmt.Errorf("function '%s' must have the no-writes flag", name)
+	}
+	return nil
+}
+
+// checkHookFunctions returns an error when the libraries are missing a
+// function that is used by a hook. The libraries can't be changed in a way
+// that leaves a hook without its function.
+func (s *Server) checkHookFunctions(fm *lFunctionMap) error {
+	for _, hook := range s.hooks {
+		if hook.Function == "" {
+			continue
+		}
+		if err := hookFunction(fm, hook.Function); err != nil {
+			return fmt.Errorf("function '%s' is used by hook '%s'",
+				hook.Function, hook.Name)
+		}
+	}
+	return nil
+}
+
+// FCALL function numkeys [key ...] [arg ...]
+// FCALL_RO function numkeys [key ...] [arg ...]
+func (s *Server) cmdFcall(msg *Message, readOnly bool) (
+	res resp.Value, err error,
+) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	var ok bool
+	var name, numkeysStr, key, arg string
+	if vs, name, ok = tokenval(vs); !ok || name == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, numkeysStr, ok = tokenval(vs); !ok || numkeysStr == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	numkeys, err := strconv.ParseUint(numkeysStr, 10, 64)
+	if err != nil {
+		return NOMessage, errInvalidArgument(numkeysStr)
+	}
+	lib := s.luafuncs.funcs[name]
+	if lib == nil {
+		return NOMessage, errFunctionNotFound
+	}
+	f, _ := lib.function(name)
+	if readOnly && !f.noWrites {
+		return NOMessage,
+			errors.New("can not call a function that may write with FCALL_RO")
+	}
+	evalCmd := "eval"
+	if readOnly || f.noWrites {
+		evalCmd = "evalro"
+	}
+
+	luaState, err := s.luapool.Get()
+	if err != nil {
+		return NOMessage, err
+	}
+	defer s.luapool.Put(luaState)
+
+	fn, err := luaFunctionCallback(luaState, lib, name)
+	if err != nil {
+		return NOMessage, err
+	}
+
+	luaDeadline := lua.LNil
+	if msg.Deadline != nil {
+		dlTime := msg.Deadline.GetDeadlineTime()
+		ctx, cancel := context.WithDeadline(context.Background(), dlTime)
+		defer cancel()
+		luaState.SetContext(ctx)
+		defer luaState.RemoveContext()
+		luaDeadline = lua.LNumber(float64(dlTime.UnixNano()) / 1e9)
+	}
+	keysTbl := luaState.CreateTable(int(numkeys), 0)
+	for i := uint64(0); i < numkeys; i++ {
+		if vs, key, ok = tokenval(vs); !ok || key == "" {
+			return NOMessage, errInvalidNumberOfArguments
+		}
+		keysTbl.Append(lua.LString(key))
+	}
+	argsTbl := luaState.CreateTable(len(vs), 0)
+	for len(vs) > 0 {
+		if vs, arg, ok = tokenval(vs); !ok || arg == "" {
+			return NOMessage, errInvalidNumberOfArguments
+		}
+		argsTbl.Append(lua.LString(arg))
+	}
+	luaSetRawGlobals(
+		luaState, map[string]lua.LValue{
+			"KEYS":     keysTbl,
+			"ARGV":     argsTbl,
+			"DEADLINE": luaDeadline,
+			"EVAL_CMD": lua.LString(evalCmd),
+		})
+	defer luaSetRawGlobals(
+		luaState, map[string]lua.LValue{
+			"KEYS":     lua.LNil,
+			"ARGV":     lua.LNil,
+			"DEADLINE": lua.LNil,
+			"EVAL_CMD": lua.LNil,
+		})
+	luaState.Push(fn)
+	luaState.Push(keysTbl)
+	luaState.Push(argsTbl)
+	if err := luaState.PCall(2, 1, nil); err != nil {
+		if strings.Contains(err.Error(), "context deadline exceeded") {
+			msg.Deadline.Check()
+		}
+		return NOMessage, makeSafeErr(err)
+	}
+	ret := luaState.Get(-1)
+	luaState.Pop(1)
+
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"result":` + ConvertToJSON(ret) +
+			`,"elapsed":"` + time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return ConvertToRESP(ret), nil
+	}
+	return NOMessage, nil
+}
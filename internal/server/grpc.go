@@ -0,0 +1,552 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/core"
+	"github.com/tidwall/tile38/internal/deadline"
+	"github.com/tidwall/tile38/internal/hservice"
+	"github.com/tidwall/tile38/internal/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer implements the Tile38 service of tile38.proto. Each request is
+// translated into a command that runs through Server.command.
+type grpcServer struct {
+	s *Server
+}
+
+// grpcServe serves the gRPC API on the --grpc-port.
+func (server *Server) grpcServe() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("%s:%d", server.host, core.GRPCPort))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	gs := grpc.NewServer()
+	hservice.RegisterTile38Server(gs, &grpcServer{server})
+	log.Infof("Ready to accept gRPC connections at %s", ln.Addr())
+	return gs.Serve(ln)
+}
+
+// grpcError converts a command error to a gRPC status error.
+func grpcError(err error) error {
+	switch err {
+	case errKeyNotFound, errIDNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case errIDAlreadyExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errNotLeader, errCatchingUp:
+		return status.Error(codes.Unavailable, err.Error())
+	case errReadOnly:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err.Error() == "timeout" {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// auth checks the protected mode and the password, which is sent as the
+// "authorization" metadata.
+func (g *grpcServer) auth(ctx context.Context) error {
+	if p, ok := peer.FromContext(ctx); ok && g.s.isProtected() {
+		host, _, _ := net.SplitHostPort(p.Addr.String())
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return status.Error(codes.PermissionDenied, "protected mode")
+		}
+	}
+	if pass := g.s.config.requirePass(); pass != "" {
+		var auth string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if vals := md.Get("authorization"); len(vals) > 0 {
+				auth = strings.TrimPrefix(vals[0], "Bearer ")
+			}
+		}
+		if auth == "" {
+			return status.Error(codes.Unauthenticated, "authentication required")
+		}
+		if strings.TrimSpace(auth) != pass {
+			return status.Error(codes.Unauthenticated, "invalid password")
+		}
+	}
+	return nil
+}
+
+// command runs a command with JSON output, holding the same locks as
+// handleInputCommand. Writes are appended to the AOF. The deadline of the
+// context applies to reads.
+func (g *grpcServer) command(ctx context.Context, write bool, args ...string,
+) (res string, d commandDetails, err error) {
+	if err := g.auth(ctx); err != nil {
+		return "", d, err
+	}
+	s := g.s
+	msg := &Message{Args: args, OutputType: JSON}
+	s.statsTotalCommands.add(1)
+	err = func() error {
+		if write {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.config.followHost() != "" {
+				return errNotLeader
+			}
+			if s.config.readOnly() {
+				return errReadOnly
+			}
+		} else {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			if s.config.followHost() != "" && !s.fcuponce {
+				return errCatchingUp
+			}
+			if dl, ok := ctx.Deadline(); ok {
+				msg.Deadline = deadline.New(dl)
+				defer func() {
+					if msg.Deadline.Hit() {
+						v := recover()
+						if v != nil {
+							if s, ok := v.(string); !ok || s != "deadline" {
+								panic(v)
+							}
+						}
+						err = errTimeoutOnCmd(msg.Command())
+					}
+				}()
+			}
+		}
+		var v resp.Value
+		v, d, err = s.command(msg, nil)
+		if err != nil {
+			return err
+		}
+		res = v.String()
+		if write {
+			return s.writeAOF(msg.Args, &d)
+		}
+		return nil
+	}()
+	if err != nil {
+		return "", d, grpcError(err)
+	}
+	if atomic.LoadInt32(&s.aofdirty) != 0 {
+		func() {
+			// prewrite
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.flushAOF(false)
+		}()
+		atomic.StoreInt32(&s.aofdirty, 0)
+	}
+	return res, d, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// grpcFields converts JSON fields to the Field messages. The fields are
+// either an object of names and values, or an array of values with the
+// names in the array of names.
+func grpcFields(fields, names gjson.Result) []*hservice.Field {
+	var res []*hservice.Field
+	if fields.IsArray() {
+		names := names.Array()
+		for i, value := range fields.Array() {
+			if i < len(names) {
+				res = append(res, &hservice.Field{
+					Name: names[i].String(), Value: value.Float(),
+				})
+			}
+		}
+	} else {
+		fields.ForEach(func(name, value gjson.Result) bool {
+			res = append(res, &hservice.Field{
+				Name: name.String(), Value: value.Float(),
+			})
+			return true
+		})
+	}
+	return res
+}
+
+func grpcSearchOptions(args []string, opts *hservice.SearchOptions) []string {
+	if opts == nil {
+		return args
+	}
+	if opts.Cursor != 0 {
+		args = append(args, "cursor", strconv.FormatUint(opts.Cursor, 10))
+	}
+	if opts.Limit != 0 {
+		args = append(args, "limit", strconv.FormatUint(opts.Limit, 10))
+	}
+	if opts.Match != "" {
+		args = append(args, "match", opts.Match)
+	}
+	for _, where := range opts.Where {
+		min, max := "-inf", "+inf"
+		if !math.IsInf(where.Min, -1) {
+			min = formatFloat(where.Min)
+		}
+		if !math.IsInf(where.Max, +1) {
+			max = formatFloat(where.Max)
+		}
+		args = append(args, "where", where.Field, min, max)
+	}
+	if opts.Nofields {
+		args = append(args, "nofields")
+	}
+	return args
+}
+
+func (g *grpcServer) search(ctx context.Context, args ...string,
+) (*hservice.SearchReply, error) {
+	res, _, err := g.command(ctx, false, args...)
+	if err != nil {
+		return nil, err
+	}
+	json := gjson.Parse(res)
+	names := json.Get("fields")
+	reply := &hservice.SearchReply{
+		Count:  json.Get("count").Uint(),
+		Cursor: json.Get("cursor").Uint(),
+	}
+	for _, obj := range json.Get("objects").Array() {
+		reply.Objects = append(reply.Objects, &hservice.Object{
+			Id:       obj.Get("id").String(),
+			Object:   obj.Get("object").Raw,
+			Fields:   grpcFields(obj.Get("fields"), names),
+			Distance: obj.Get("distance").Float(),
+		})
+	}
+	return reply, nil
+}
+
+// Set implements the Set rpc.
+func (g *grpcServer) Set(ctx context.Context, req *hservice.SetRequest,
+) (*hservice.SetReply, error) {
+	args := []string{"set", req.Key, req.Id}
+	for _, field := range req.Fields {
+		args = append(args, "field", field.Name, formatFloat(field.Value))
+	}
+	if req.Ex != 0 {
+		args = append(args, "ex", formatFloat(req.Ex))
+	}
+	if req.Nx {
+		args = append(args, "nx")
+	}
+	if req.Xx {
+		args = append(args, "xx")
+	}
+	switch {
+	case req.Object != "":
+		args = append(args, "object", req.Object)
+	case req.Point != nil:
+		args = append(args, "point", formatFloat(req.Point.Lat),
+			formatFloat(req.Point.Lon))
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing object")
+	}
+	if _, _, err := g.command(ctx, true, args...); err != nil {
+		return nil, err
+	}
+	return &hservice.SetReply{}, nil
+}
+
+// Get implements the Get rpc.
+func (g *grpcServer) Get(ctx context.Context, req *hservice.GetRequest,
+) (*hservice.GetReply, error) {
+	args := []string{"get", req.Key, req.Id}
+	if req.WithFields {
+		args = append(args, "withfields")
+	}
+	res, _, err := g.command(ctx, false, args...)
+	if err != nil {
+		return nil, err
+	}
+	json := gjson.Parse(res)
+	return &hservice.GetReply{
+		Object: &hservice.Object{
+			Id:     req.Id,
+			Object: json.Get("object").Raw,
+			Fields: grpcFields(json.Get("fields"), gjson.Result{}),
+		},
+	}, nil
+}
+
+// Del implements the Del rpc.
+func (g *grpcServer) Del(ctx context.Context, req *hservice.DelRequest,
+) (*hservice.DelReply, error) {
+	_, d, err := g.command(ctx, true, "del", req.Key, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &hservice.DelReply{Deleted: d.updated}, nil
+}
+
+// Nearby implements the Nearby rpc.
+func (g *grpcServer) Nearby(ctx context.Context, req *hservice.NearbyRequest,
+) (*hservice.SearchReply, error) {
+	if req.Point == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing point")
+	}
+	args := grpcSearchOptions([]string{"nearby", req.Key}, req.Options)
+	if req.Distance {
+		args = append(args, "distance")
+	}
+	args = append(args, "point", formatFloat(req.Point.Lat),
+		formatFloat(req.Point.Lon))
+	if req.Meters != 0 {
+		args = append(args, formatFloat(req.Meters))
+	}
+	return g.search(ctx, args...)
+}
+
+func grpcAreaArgs(cmd string, req *hservice.AreaRequest) ([]string, error) {
+	args := grpcSearchOptions([]string{cmd, req.Key}, req.Options)
+	switch {
+	case req.Object != "":
+		args = append(args, "object", req.Object)
+	case req.Bounds != nil:
+		args = append(args, "bounds",
+			formatFloat(req.Bounds.MinLat), formatFloat(req.Bounds.MinLon),
+			formatFloat(req.Bounds.MaxLat), formatFloat(req.Bounds.MaxLon))
+	case req.Circle != nil:
+		args = append(args, "circle",
+			formatFloat(req.Circle.Lat), formatFloat(req.Circle.Lon),
+			formatFloat(req.Circle.Meters))
+	case req.Hash != "":
+		args = append(args, "hash", req.Hash)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "missing area")
+	}
+	return args, nil
+}
+
+// Within implements the Within rpc.
+func (g *grpcServer) Within(ctx context.Context, req *hservice.AreaRequest,
+) (*hservice.SearchReply, error) {
+	args, err := grpcAreaArgs("within", req)
+	if err != nil {
+		return nil, err
+	}
+	return g.search(ctx, args...)
+}
+
+// Intersects implements the Intersects rpc.
+func (g *grpcServer) Intersects(ctx context.Context, req *hservice.AreaRequest,
+) (*hservice.SearchReply, error) {
+	args, err := grpcAreaArgs("intersects", req)
+	if err != nil {
+		return nil, err
+	}
+	return g.search(ctx, args...)
+}
+
+// Scan implements the Scan rpc.
+func (g *grpcServer) Scan(ctx context.Context, req *hservice.ScanRequest,
+) (*hservice.SearchReply, error) {
+	return g.search(ctx, grpcSearchOptions([]string{"scan", req.Key},
+		req.Options)...)
+}
+
+// Fence implements the Fence rpc. The events are matched the same way as
+// for the live geofences of the other transports.
+func (g *grpcServer) Fence(req *hservice.FenceRequest,
+	stream hservice.Tile38_FenceServer,
+) error {
+	ctx := stream.Context()
+	if err := g.auth(ctx); err != nil {
+		return err
+	}
+	switch req.Command {
+	case "nearby", "within", "intersects":
+	default:
+		return status.Error(codes.InvalidArgument,
+			errInvalidArgument(req.Command).Error())
+	}
+	args := grpcSearchOptions([]string{req.Command, req.Key}, req.Options)
+	args = append(args, "fence")
+	if len(req.Detect) > 0 {
+		args = append(args, "detect", strings.Join(req.Detect, ","))
+	}
+	switch {
+	case req.Object != "" && req.Command != "nearby":
+		args = append(args, "object", req.Object)
+	case req.Point != nil && req.Command == "nearby":
+		args = append(args, "point", formatFloat(req.Point.Lat),
+			formatFloat(req.Point.Lon), formatFloat(req.Meters))
+	case req.Point != nil:
+		args = append(args, "circle", formatFloat(req.Point.Lat),
+			formatFloat(req.Point.Lon), formatFloat(req.Meters))
+	default:
+		return status.Error(codes.InvalidArgument, "missing area")
+	}
+	s := g.s
+	msg := &Message{Args: args, OutputType: JSON}
+	s.mu.RLock()
+	_, _, err := s.command(msg, nil)
+	s.mu.RUnlock()
+	fence, ok := err.(liveFenceSwitches)
+	if !ok {
+		if err == nil {
+			return status.Error(codes.Internal, "expected a live geofence")
+		}
+		return grpcError(err)
+	}
+	defer fence.Close()
+
+	lb := &liveBuffer{
+		key:   fence.key,
+		glob:  fence.glob,
+		fence: &fence,
+		cond:  sync.NewCond(&sync.Mutex{}),
+	}
+	var wr bytes.Buffer
+	s.mu.RLock()
+	sw, err := s.newScanWriter(
+		&wr, msg, fence.key, fence.output, fence.precision, fence.glob, false,
+		fence.cursor, fence.limit, fence.wheres, fence.whereins,
+		fence.whereevals, fence.whereexprs, fence.wherejsons, fence.nofields)
+	s.mu.RUnlock()
+	if err != nil {
+		return grpcError(err)
+	}
+	s.lcond.L.Lock()
+	s.lives[lb] = true
+	s.lcond.L.Unlock()
+	defer func() {
+		s.lcond.L.Lock()
+		delete(s.lives, lb)
+		s.lcond.L.Unlock()
+	}()
+	// the headers tell the client that the fence is live
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	var done bool
+	go func() {
+		<-ctx.Done()
+		lb.cond.L.Lock()
+		done = true
+		lb.cond.Broadcast()
+		lb.cond.L.Unlock()
+	}()
+	lb.cond.L.Lock()
+	defer lb.cond.L.Unlock()
+	for {
+		for len(lb.details) > 0 && !done {
+			details := lb.details[0]
+			lb.details = lb.details[1:]
+			lb.cond.L.Unlock()
+			var msgs []string
+			func() {
+				s.mu.RLock()
+				defer s.mu.RUnlock()
+				msgs = FenceMatch("", sw, &fence, nil, details)
+			}()
+			for _, msg := range msgs {
+				json := gjson.Parse(msg)
+				if err := stream.Send(&hservice.FenceEvent{
+					Command:  json.Get("command").String(),
+					Group:    json.Get("group").String(),
+					Detect:   json.Get("detect").String(),
+					Key:      json.Get("key").String(),
+					Id:       json.Get("id").String(),
+					Time:     json.Get("time").String(),
+					Object:   json.Get("object").Raw,
+					Fields:   grpcFields(json.Get("fields"), gjson.Result{}),
+					Distance: json.Get("distance").Float(),
+					Json:     msg,
+				}); err != nil {
+					lb.cond.L.Lock()
+					return err
+				}
+			}
+			s.statsTotalMsgsSent.add(len(msgs))
+			lb.cond.L.Lock()
+		}
+		if done {
+			return nil
+		}
+		lb.cond.Wait()
+	}
+}
+
+// Subscribe implements the Subscribe rpc.
+func (g *grpcServer) Subscribe(req *hservice.SubscribeRequest,
+	stream hservice.Tile38_SubscribeServer,
+) error {
+	ctx := stream.Context()
+	if err := g.auth(ctx); err != nil {
+		return err
+	}
+	if len(req.Channels)+len(req.Patterns) == 0 {
+		return status.Error(codes.InvalidArgument,
+			errInvalidNumberOfArguments.Error())
+	}
+	s := g.s
+	target := newSubtarget()
+	for _, channel := range req.Channels {
+		s.pubsub.register(pubsubChannel, channel, target)
+	}
+	for _, pattern := range req.Patterns {
+		s.pubsub.register(pubsubPattern, pattern, target)
+	}
+	defer func() {
+		for _, channel := range req.Channels {
+			s.pubsub.unregister(pubsubChannel, channel, target)
+		}
+		for _, pattern := range req.Patterns {
+			s.pubsub.unregister(pubsubPattern, pattern, target)
+		}
+	}()
+	// the headers tell the client that the subscriptions are active
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		target.cond.L.Lock()
+		target.closed = true
+		target.cond.Broadcast()
+		target.cond.L.Unlock()
+	}()
+	for {
+		target.cond.L.Lock()
+		for len(target.msgs) == 0 && !target.closed {
+			target.cond.Wait()
+		}
+		msgs, closed := target.msgs, target.closed
+		target.msgs = nil
+		target.cond.L.Unlock()
+		if closed {
+			return nil
+		}
+		for _, msg := range msgs {
+			if err := stream.Send(&hservice.SubscribeMessage{
+				Channel: msg.channel,
+				Pattern: msg.pattern,
+				Message: msg.message,
+			}); err != nil {
+				return err
+			}
+		}
+		s.statsTotalMsgsSent.add(len(msgs))
+	}
+}
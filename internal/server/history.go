@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/redcon"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/collection"
+)
+
+// keyHistoryLength is the number of previous objects that are kept for each
+// id of a key with history.
+const keyHistoryLength = 10
+
+// keepHistory keeps the previous object of an id, when the key has history.
+// The objects that are replaced or deleted are kept, oldest first, and only
+// the last keyHistoryLength of them. The history of an id stays when the id
+// is deleted, until the history of the key is turned off or the key is
+// dropped.
+func (s *Server) keepHistory(key, id string, obj geojson.Object) {
+	if obj == nil {
+		return
+	}
+	if kc := s.keyConfigs[key]; kc == nil || !kc.history {
+		return
+	}
+	h := s.histories[key]
+	if h == nil {
+		h = make(map[string][]geojson.Object)
+		s.histories[key] = h
+	}
+	objs := h[id]
+	if len(objs) == keyHistoryLength {
+		objs = append(objs[:0:0], objs[1:]...)
+	}
+	h[id] = append(objs, obj)
+}
+
+// appendKeyHistory appends the commands that restore the history of a key
+// to an aof buffer, for the aof shrink. The previous objects of each id are
+// set in order, before the current object is set, and the ids that no
+// longer exist are deleted after.
+func appendKeyHistory(
+	aofbuf []byte, h map[string][]geojson.Object, key string,
+	col *collection.Collection,
+) []byte {
+	ids := make([]string, 0, len(h))
+	for id := range h {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		for _, obj := range h[id] {
+			aofbuf = redcon.AppendArray(aofbuf, 5)
+			aofbuf = redcon.AppendBulkString(aofbuf, "set")
+			aofbuf = redcon.AppendBulkString(aofbuf, key)
+			aofbuf = redcon.AppendBulkString(aofbuf, id)
+			if objIsSpatial(obj) {
+				aofbuf = redcon.AppendBulkString(aofbuf, "object")
+				aofbuf = redcon.AppendBulk(aofbuf, obj.AppendJSON(nil))
+			} else {
+				aofbuf = redcon.AppendBulkString(aofbuf, "string")
+				aofbuf = redcon.AppendBulkString(aofbuf, obj.String())
+			}
+		}
+		var exists bool
+		if col != nil {
+			_, exists = col.Access(id)
+		}
+		if !exists {
+			aofbuf = redcon.AppendArray(aofbuf, 3)
+			aofbuf = redcon.AppendBulkString(aofbuf, "del")
+			aofbuf = redcon.AppendBulkString(aofbuf, key)
+			aofbuf = redcon.AppendBulkString(aofbuf, id)
+		}
+	}
+	return aofbuf
+}
+
+// HISTORY key id
+//
+// Returns the previous objects of an id, newest first.
+func (s *Server) cmdHistory(msg *Message) (resp.Value, error) {
+	start := time.Now()
+	if len(msg.Args) != 3 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	key, id := msg.Args[1], msg.Args[2]
+	if kc := s.keyConfigs[key]; kc == nil || !kc.history {
+		return NOMessage, errKeyHasNoHistory
+	}
+	objs := s.histories[key][id]
+	switch msg.OutputType {
+	case JSON:
+		var buf bytes.Buffer
+		buf.WriteString(`{"ok":true,"objects":[`)
+		for i := len(objs) - 1; i >= 0; i-- {
+			if i < len(objs)-1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(objs[i].AppendJSON(nil))
+		}
+		buf.WriteString(`],"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}")
+		return resp.StringValue(buf.String()), nil
+	case RESP:
+		vals := make([]resp.Value, 0, len(objs))
+		for i := len(objs) - 1; i >= 0; i-- {
+			vals = append(vals, resp.StringValue(objs[i].String()))
+		}
+		return resp.ArrayValue(vals), nil
+	}
+	return NOMessage, nil
+}
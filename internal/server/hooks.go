@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"sort"
 	"strconv"
@@ -15,8 +16,13 @@ import (
 	"github.com/tidwall/tile38/internal/endpoint"
 	"github.com/tidwall/tile38/internal/glob"
 	"github.com/tidwall/tile38/internal/log"
+	lua "github.com/yuin/gopher-lua"
 )
 
+// hookTransformTimeout is the time that the function or script of a hook has
+// to handle an event.
+const hookTransformTimeout = time.Second / 10
+
 var hookLogSetDefaults = &buntdb.SetOptions{
 	Expires: true, // automatically delete after 30 seconds
 	TTL:     time.Second * 30,
@@ -68,6 +74,7 @@ func (s *Server) cmdSetHook(msg *Message, chanCmd bool) (
 	var types []string
 	var expires float64
 	var expiresSet bool
+	var function, script string
 	metaMap := make(map[string]string)
 	for {
 		commandvs = vs
@@ -101,6 +108,25 @@ func (s *Server) cmdSetHook(msg *Message, chanCmd bool) (
 			expires = v
 			expiresSet = true
 			continue
+		case "function":
+			if vs, function, ok = tokenval(vs); !ok || function == "" {
+				return NOMessage, d, errInvalidNumberOfArguments
+			}
+			if err := hookFunction(s.luafuncs, function); err != nil {
+				return NOMessage, d, err
+			}
+			continue
+		case "script":
+			// scripts are not persisted, so the script doesn't need to be
+			// loaded yet, and it may be loaded again after a restart.
+			if vs, script, ok = tokenval(vs); !ok || script == "" {
+				return NOMessage, d, errInvalidNumberOfArguments
+			}
+			script = strings.ToLower(script)
+			if !validSha(script) {
+				return NOMessage, d, errInvalidArgument(script)
+			}
+			continue
 		case "nearby":
 			types = nearbyTypes
 		case "within", "intersects":
@@ -108,6 +134,10 @@ func (s *Server) cmdSetHook(msg *Message, chanCmd bool) (
 		}
 		break
 	}
+	if function != "" && script != "" {
+		return NOMessage, d,
+			errors.New("FUNCTION and SCRIPT can't be used together")
+	}
 	args, err := s.cmdSearchArgs(true, cmdlc, vs, types)
 	defer args.Close()
 	if err != nil {
@@ -137,9 +167,12 @@ func (s *Server) cmdSetHook(msg *Message, chanCmd bool) (
 		Message:   cmsg,
 		epm:       s.epc,
 		Metas:     metas,
+		Function:  function,
+		Script:    script,
 		channel:   chanCmd,
 		cond:      sync.NewCond(&sync.Mutex{}),
 		counter:   &s.statsTotalMsgsSent,
+		server:    s,
 	}
 	if expiresSet {
 		hook.expires =
@@ -152,7 +185,7 @@ func (s *Server) cmdSetHook(msg *Message, chanCmd bool) (
 	hook.ScanWriter, err = s.newScanWriter(
 		&wr, cmsg, args.key, args.output, args.precision, args.glob, false,
 		args.cursor, args.limit, args.wheres, args.whereins, args.whereevals,
-		args.nofields)
+		args.whereexprs, args.wherejsons, args.nofields)
 	if err != nil {
 
 		return NOMessage, d, err
@@ -436,7 +469,14 @@ func (s *Server) cmdHooks(msg *Message, channel bool) (
 				buf.WriteString(`:`)
 				buf.WriteString(jsonString(meta.Value))
 			}
-			buf.WriteString(`}}`)
+			buf.WriteString(`}`)
+			if hook.Function != "" {
+				buf.WriteString(`,"function":` + jsonString(hook.Function))
+			}
+			if hook.Script != "" {
+				buf.WriteString(`,"script":` + jsonString(hook.Script))
+			}
+			buf.WriteString(`}`)
 		}
 		buf.WriteString(`],"elapsed":"` +
 			time.Now().Sub(start).String() + "\"}")
@@ -480,6 +520,8 @@ type Hook struct {
 	Fence      *liveFenceSwitches
 	ScanWriter *scanWriter
 	Metas      []FenceMeta
+	Function   string // name of the function that transforms the events
+	Script     string // sha of the script that transforms the events
 	db         *buntdb.DB
 	channel    bool
 	closed     bool
@@ -489,6 +531,14 @@ type Hook struct {
 	expires    time.Time
 	counter    *aint // counter that grows when a message was sent
 	sig        int
+	server     *Server  // runs the function
+	pending    []string // channel events that wait for the function
+}
+
+// transforms returns true when a function or a script transforms the events
+// of the hook.
+func (h *Hook) transforms() bool {
+	return h.Function != "" || h.Script != ""
 }
 
 // Expires returns when the hook expires. Required by the expire.Item interface.
@@ -501,7 +551,9 @@ func (h *Hook) Equals(hook *Hook) bool {
 	if h.Key != hook.Key ||
 		h.Name != hook.Name ||
 		len(h.Endpoints) != len(hook.Endpoints) ||
-		len(h.Metas) != len(hook.Metas) {
+		len(h.Metas) != len(hook.Metas) ||
+		h.Function != hook.Function ||
+		h.Script != hook.Script {
 		return false
 	}
 	if !h.expires.Equal(hook.expires) {
@@ -551,8 +603,8 @@ func (arr hookMetaByName) Swap(a, b int) {
 // Open is called when a hook is first created. It calls the manager
 // function in a goroutine
 func (h *Hook) Open() {
-	if h.channel {
-		// nothing to open for channels
+	if h.channel && !h.transforms() {
+		// nothing to open for channels without a transform
 		return
 	}
 	h.cond.L.Lock()
@@ -567,8 +619,8 @@ func (h *Hook) Open() {
 
 // Close closed the hook and stop the manager function
 func (h *Hook) Close() {
-	if h.channel {
-		// nothing to close for channels
+	if h.channel && !h.transforms() {
+		// nothing to close for channels without a transform
 		return
 	}
 	h.cond.L.Lock()
@@ -583,8 +635,8 @@ func (h *Hook) Close() {
 // Signal can be called at any point to wake up the hook and
 // notify the manager that there may be something new in the queue.
 func (h *Hook) Signal() {
-	if h.channel {
-		// nothing to signal for channels
+	if h.channel && !h.transforms() {
+		// nothing to signal for channels without a transform
 		return
 	}
 	h.cond.L.Lock()
@@ -610,6 +662,9 @@ func (h *Hook) manager() {
 		if !func() bool {
 			h.cond.L.Unlock()
 			defer h.cond.L.Lock()
+			if h.channel {
+				return h.procChan()
+			}
 			return h.proc()
 		}() {
 			// a send failed, try again in a moment
@@ -677,8 +732,20 @@ func (h *Hook) proc() (ok bool) {
 	for i, key := range keys {
 		val := vals[i]
 		idx := stringToUint64(key[len(hookLogPrefix):])
+		endpoints := h.Endpoints
+		if h.transforms() {
+			payload, endpoint, ok := h.server.runHookTransform(h, val)
+			if !ok {
+				// dropped by the transform
+				continue
+			}
+			if endpoint != "" {
+				endpoints = []string{endpoint}
+			}
+			val = payload
+		}
 		var sent bool
-		for _, endpoint := range h.Endpoints {
+		for _, endpoint := range endpoints {
 			err := h.epm.Send(endpoint, val)
 			if err != nil {
 				log.Debugf("Endpoint connect/send error: %v: %v: %v",
@@ -718,3 +785,146 @@ func (h *Hook) proc() (ok bool) {
 	}
 	return true
 }
+
+// hookMaxPending is the number of events of a channel hook that can wait
+// for its transform. The oldest events are dropped beyond this.
+const hookMaxPending = 10000
+
+// queue adds an event of a channel hook, which is published once the
+// transform of the hook has run on it.
+func (h *Hook) queue(msg string) {
+	h.cond.L.Lock()
+	if len(h.pending) == hookMaxPending {
+		log.Errorf("hook %s: transform is behind, dropping an event", h.Name)
+		h.pending = h.pending[1:]
+	}
+	h.pending = append(h.pending, msg)
+	h.sig++
+	h.cond.Broadcast()
+	h.cond.L.Unlock()
+}
+
+// procChan runs the transform of a channel hook on the pending events and
+// publishes them.
+func (h *Hook) procChan() bool {
+	h.cond.L.Lock()
+	msgs := h.pending
+	h.pending = nil
+	h.cond.L.Unlock()
+	for _, msg := range msgs {
+		if payload, _, ok := h.server.runHookTransform(h, msg); ok {
+			h.server.publishChan(h.Name, payload)
+		}
+	}
+	return true
+}
+
+// runHookTransform runs the function or the script of a hook on an event.
+// A function receives the key in keys[1], and the event and the hook name in
+// args[1] and args[2], and a script receives them in KEYS and ARGV. It may
+// only read from the database. Returning nil or false drops the event and
+// returning true keeps it as is. Returning a string or a table replaces the
+// event, and an optional second string value is the endpoint to send it to
+// instead of the endpoints of the hook. An event is dropped when the
+// transform fails, runs past hookTransformTimeout or, for a script, isn't
+// loaded, so that an event is never delivered without its transform. The
+// transform runs in the goroutine of the hook, outside of the server lock,
+// and each of its calls takes the read lock.
+func (s *Server) runHookTransform(hook *Hook, msg string) (
+	payload, endpoint string, ok bool,
+) {
+	payload, endpoint, ok, err := func() (string, string, bool, error) {
+		luaState, err := s.luapool.Get()
+		if err != nil {
+			return "", "", false, err
+		}
+		defer s.luapool.Put(luaState)
+		var fn lua.LValue
+		if hook.Function != "" {
+			s.mu.RLock()
+			lib := s.luafuncs.funcs[hook.Function]
+			s.mu.RUnlock()
+			if lib == nil {
+				return "", "", false, errFunctionNotFound
+			}
+			fn, err = luaFunctionCallback(luaState, lib, hook.Function)
+			if err != nil {
+				return "", "", false, err
+			}
+		} else {
+			compiled, ok := s.luascripts.Get(hook.Script)
+			if !ok {
+				return "", "", false, errShaNotFound
+			}
+			fn = &lua.LFunction{
+				Env:      luaState.Env,
+				Proto:    compiled,
+				Upvalues: make([]*lua.Upvalue, 0),
+			}
+		}
+		dl := time.Now().Add(hookTransformTimeout)
+		ctx, cancel := context.WithDeadline(context.Background(), dl)
+		defer cancel()
+		luaState.SetContext(ctx)
+		defer luaState.RemoveContext()
+		keysTbl := luaState.CreateTable(1, 0)
+		keysTbl.Append(lua.LString(hook.Key))
+		argsTbl := luaState.CreateTable(2, 0)
+		argsTbl.Append(lua.LString(msg))
+		argsTbl.Append(lua.LString(hook.Name))
+		luaSetRawGlobals(
+			luaState, map[string]lua.LValue{
+				"KEYS":     keysTbl,
+				"ARGV":     argsTbl,
+				"DEADLINE": lua.LNumber(float64(dl.UnixNano()) / 1e9),
+				"EVAL_CMD": lua.LString("hook"),
+			})
+		defer luaSetRawGlobals(
+			luaState, map[string]lua.LValue{
+				"KEYS":     lua.LNil,
+				"ARGV":     lua.LNil,
+				"DEADLINE": lua.LNil,
+				"EVAL_CMD": lua.LNil,
+			})
+		luaState.Push(fn)
+		nargs := 0
+		if hook.Function != "" {
+			luaState.Push(keysTbl)
+			luaState.Push(argsTbl)
+			nargs = 2
+		}
+		if err := luaState.PCall(nargs, 2, nil); err != nil {
+			return "", "", false, makeSafeErr(err)
+		}
+		ret, ep := luaState.Get(-2), luaState.Get(-1)
+		luaState.Pop(2)
+		if ep.Type() == lua.LTString {
+			endpoint = ep.String()
+			if err := s.epc.Validate(endpoint); err != nil {
+				return "", "", false, err
+			}
+		}
+		switch ret.Type() {
+		case lua.LTNil:
+			return "", "", false, nil
+		case lua.LTBool:
+			return msg, endpoint, ret == lua.LTrue, nil
+		case lua.LTString:
+			return ret.String(), endpoint, true, nil
+		case lua.LTTable:
+			return ConvertToJSON(ret), endpoint, true, nil
+		}
+		return "", "", false,
+			errors.New("unsupported return type: " + ret.Type().String())
+	}()
+	if err != nil {
+		if hook.Function != "" {
+			log.Errorf("hook %s: function %s: %v",
+				hook.Name, hook.Function, err)
+		} else {
+			log.Errorf("hook %s: script %s: %v", hook.Name, hook.Script, err)
+		}
+		return "", "", false
+	}
+	return payload, endpoint, ok
+}
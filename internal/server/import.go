@@ -0,0 +1,199 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/geofile"
+	"github.com/tidwall/tile38/internal/log"
+)
+
+// importBatchSize is the number of objects that are written to the
+// collection, and appended to the AOF, each time the server lock is held.
+const importBatchSize = 1000
+
+// maxImportErrors is the number of per-line errors that are returned by
+// IMPORT. Any errors beyond this are counted but not returned.
+const maxImportErrors = 100
+
+type importState struct {
+	opts     geofile.Options
+	batch    []geofile.Record
+	imported int
+	failed   int
+	errs     []string
+	lines    int
+}
+
+func (is *importState) fail(line int, err error) {
+	is.failed++
+	if len(is.errs) < maxImportErrors {
+		is.errs = append(is.errs, fmt.Sprintf("line %d: %v", line, err))
+	}
+}
+
+// IMPORT key FROM path [IDFIELD prop] [FIELDS name [name ...]]
+//
+// The objects are imported in batches, and an import that stops on an error
+// isn't rolled back. The objects that were read before the error are
+// imported, and the error tells how many there are.
+func (server *Server) cmdImport(msg *Message) (res resp.Value, err error) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	var is importState
+	var path, tok string
+	var ok bool
+	if vs, is.opts.Key, ok = tokenval(vs); !ok || is.opts.Key == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, tok, ok = tokenval(vs); !ok || strings.ToLower(tok) != "from" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if vs, path, ok = tokenval(vs); !ok || path == "" {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	for len(vs) > 0 {
+		vs, tok, _ = tokenval(vs)
+		switch strings.ToLower(tok) {
+		case "idfield":
+			if is.opts.IDField != "" {
+				return NOMessage, errDuplicateArgument(strings.ToUpper(tok))
+			}
+			if vs, is.opts.IDField, ok = tokenval(vs); !ok || is.opts.IDField == "" {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+		case "fields":
+			if len(vs) == 0 {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			for _, field := range vs {
+				if field == "z" {
+					return NOMessage, errInvalidArgument(field)
+				}
+				is.opts.Fields = append(is.opts.Fields, field)
+			}
+			vs = nil
+		default:
+			return NOMessage, errInvalidArgument(tok)
+		}
+	}
+	path, err = server.filePath(path)
+	if err != nil {
+		return NOMessage, err
+	}
+	format := geofile.Format(path)
+	if format == "" {
+		return NOMessage, errors.New("unknown file format")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NOMessage, errors.New("file not found")
+		}
+		return NOMessage, err
+	}
+	defer f.Close()
+	log.Infof("import: %s from %s", is.opts.Key, path)
+	server.statsImportsRunning.add(1)
+	defer server.statsImportsRunning.add(-1)
+	err = geofile.Read(f, format, &is.opts, func(rec geofile.Record) error {
+		server.statsImportedLines.add(rec.Line - is.lines)
+		is.lines = rec.Line
+		if rec.Err != nil {
+			is.fail(rec.Line, rec.Err)
+			return nil
+		}
+		is.batch = append(is.batch, rec)
+		if len(is.batch) == importBatchSize {
+			return server.importBatch(msg, &is)
+		}
+		return nil
+	})
+	if berr := server.importBatch(msg, &is); err == nil {
+		err = berr
+	}
+	log.Infof("import: %s done %d objects, %d errors", is.opts.Key, is.imported,
+		is.failed)
+	if err != nil {
+		return NOMessage, fmt.Errorf("import stopped after %d objects: %v",
+			is.imported, err)
+	}
+	switch msg.OutputType {
+	case JSON:
+		var buf strings.Builder
+		buf.WriteString(`{"ok":true,"imported":` + strconv.Itoa(is.imported))
+		buf.WriteString(`,"failed":` + strconv.Itoa(is.failed) + `,"errors":[`)
+		for i, e := range is.errs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(jsonString(e))
+		}
+		buf.WriteString(`],"elapsed":"` + time.Now().Sub(start).String() + "\"}")
+		res = resp.StringValue(buf.String())
+	case RESP:
+		errs := make([]resp.Value, len(is.errs))
+		for i, e := range is.errs {
+			errs[i] = resp.StringValue(e)
+		}
+		res = resp.ArrayValue([]resp.Value{
+			resp.IntegerValue(is.imported),
+			resp.IntegerValue(is.failed),
+			resp.ArrayValue(errs),
+		})
+	}
+	return res, nil
+}
+
+// importBatch writes the pending records while holding the server lock. The
+// records are executed as SET commands and appended to the AOF together, and
+// the followers, hooks and notifications are told about them at once.
+func (server *Server) importBatch(msg *Message, is *importState) error {
+	if len(is.batch) == 0 {
+		return nil
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.config.followHost() != "" {
+		return errNotLeader
+	}
+	if server.config.readOnly() {
+		return errReadOnly
+	}
+	d := commandDetails{parent: true, timestamp: time.Now()}
+	var err error
+	for _, rec := range is.batch {
+		nmsg := *msg
+		nmsg._command = ""
+		nmsg.Args = rec.Args
+		nmsg.Deadline = nil
+		_, dc, cerr := server.command(&nmsg, nil)
+		if cerr == errOOM {
+			err = cerr
+			break
+		}
+		if cerr != nil {
+			is.fail(rec.Line, cerr)
+			continue
+		}
+		if dc.updated {
+			server.appendAOF(nmsg.Args)
+			d.children = append(d.children, &dc)
+		}
+		is.imported++
+		server.statsImportedObjects.add(1)
+	}
+	if len(d.children) > 0 {
+		d.updated = true
+		if nerr := server.notifyWrite(&d); nerr != nil && err == nil {
+			err = nerr
+		}
+	}
+	is.batch = is.batch[:0]
+	return err
+}
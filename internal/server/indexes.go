@@ -0,0 +1,194 @@
+package server
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/deadline"
+)
+
+// CREATEINDEX key field
+func (server *Server) cmdCreateIndex(msg *Message) (res resp.Value, d commandDetails, err error) {
+	return server.cmdCreateOrDropIndex(msg, true)
+}
+
+// DROPINDEX key field
+func (server *Server) cmdDropIndex(msg *Message) (res resp.Value, d commandDetails, err error) {
+	return server.cmdCreateOrDropIndex(msg, false)
+}
+
+func (server *Server) cmdCreateOrDropIndex(msg *Message, create bool) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	var field string
+	var ok bool
+	if vs, d.key, ok = tokenval(vs); !ok || d.key == "" {
+		err = errInvalidNumberOfArguments
+		return
+	}
+	if vs, field, ok = tokenval(vs); !ok || field == "" {
+		err = errInvalidNumberOfArguments
+		return
+	}
+	if len(vs) != 0 {
+		err = errInvalidNumberOfArguments
+		return
+	}
+	if field == "z" {
+		err = errInvalidArgument(field)
+		return
+	}
+	col := server.getCol(d.key)
+	if col == nil {
+		err = errKeyNotFound
+		return
+	}
+	if create {
+		d.command = "createindex"
+		d.updated = col.CreateIndex(field)
+	} else {
+		d.command = "dropindex"
+		d.updated = col.DropIndex(field)
+	}
+	d.timestamp = time.Now()
+	switch msg.OutputType {
+	case JSON:
+		res = resp.StringValue(`{"ok":true,"elapsed":"` + time.Now().Sub(start).String() + "\"}")
+	case RESP:
+		if d.updated {
+			res = resp.IntegerValue(1)
+		} else {
+			res = resp.IntegerValue(0)
+		}
+	}
+	return
+}
+
+// maxIndexSelectivity is the largest fraction of a collection that a WHERE
+// range may match for a search to use the field index instead of walking
+// every object.
+const maxIndexSelectivity = 0.25
+
+// indexCursor marks the cursor of a search that used a field index. Its
+// offset counts the candidates of the index instead of the objects of the
+// collection, so the next page must use the index too, even when the range
+// is no longer selective. A plain non-zero cursor never uses the index.
+const indexCursor = 1 << 62
+
+// errIndexCursor is returned for the cursor of a search that used a field
+// index, when the next page can't use the index, because the index was
+// dropped or the search has no WHERE on an indexed field.
+var errIndexCursor = errors.New("invalid cursor, the search no longer uses a field index")
+
+// plainCursor returns errIndexCursor when the cursor belongs to a search
+// that used a field index. Must be called by a search that doesn't use the
+// index, before the cursor is used as an offset.
+func (sw *scanWriter) plainCursor() error {
+	if sw.cursor&indexCursor != 0 {
+		return errIndexCursor
+	}
+	return nil
+}
+
+// indexCandidates returns the objects in the WHERE range of the first indexed
+// field, for a search that is answered through the index. The candidates
+// still have to be tested against the other filters. Returns false when no
+// WHERE field is indexed, when the range is not selective enough or when the
+// cursor belongs to a search that didn't use the index. The cursor of a
+// search that used the index keeps its mark when false is returned, so that
+// plainCursor rejects it.
+func (sw *scanWriter) indexCandidates(dl *deadline.Deadline) (
+	[]orderItem, bool,
+) {
+	paging := sw.cursor&indexCursor != 0
+	if sw.cursor != 0 && !paging {
+		return nil, false
+	}
+	var where *whereT
+	for i := range sw.wheres {
+		if sw.col.HasIndex(sw.wheres[i].field) {
+			where = &sw.wheres[i]
+			break
+		}
+	}
+	if where == nil {
+		return nil, false
+	}
+	max := -1
+	if !paging {
+		max = int(float64(sw.col.Count()) * maxIndexSelectivity)
+	}
+	var items []orderItem
+	selective := sw.col.IndexRange(where.field, where.min, where.max, dl,
+		func(id string, o geojson.Object, fields []float64) bool {
+			if len(items) == max {
+				return false
+			}
+			items = append(items, orderItem{id: id, o: o, fields: fields})
+			return true
+		},
+	)
+	if !selective {
+		return nil, false
+	}
+	sw.cursor &^= indexCursor
+	sw.indexed = true
+	return items, true
+}
+
+// writeCandidates writes the page of index candidates that starts at the
+// cursor. Every candidate counts for the cursor, also when it's skipped
+// because the params function returns false.
+func (sw *scanWriter) writeCandidates(items []orderItem,
+	params func(item orderItem) (ScanWriterParams, bool),
+) {
+	if sw.cursor >= uint64(len(items)) {
+		return
+	}
+	sw.Step(sw.cursor)
+	for _, item := range items[sw.cursor:] {
+		sw.Step(1)
+		p, ok := params(item)
+		if !ok {
+			continue
+		}
+		if !sw.writeObject(p) {
+			break
+		}
+	}
+}
+
+// sortCandidates orders the candidates by id, like a plain scan.
+func sortCandidates(items []orderItem, desc bool) {
+	sort.Slice(items, func(i, j int) bool {
+		if desc {
+			return items[i].id > items[j].id
+		}
+		return items[i].id < items[j].id
+	})
+}
+
+// indexScan attempts to answer a SCAN through the index of one of its WHERE
+// fields. Returns false, without writing anything, when the index can't be
+// used.
+func (sw *scanWriter) indexScan(desc bool, dl *deadline.Deadline) bool {
+	items, ok := sw.indexCandidates(dl)
+	if !ok {
+		return false
+	}
+	sortCandidates(items, desc)
+	sw.writeCandidates(items, func(item orderItem) (ScanWriterParams, bool) {
+		return ScanWriterParams{
+			id:     item.id,
+			o:      item.o,
+			fields: item.fields,
+			noLock: true,
+		}, true
+	})
+	return true
+}
@@ -295,6 +295,9 @@ func (s *Server) cmdJset(msg *Message) (res resp.Value, d commandDetails, err er
 		// SET key id OBJECT json
 		return s.cmdSet(&nmsg, false)
 	}
+	if err := s.checkMaxObjects(key, id, col); err != nil {
+		return NOMessage, d, err
+	}
 	if createcol {
 		s.setCol(key, col)
 	}
@@ -306,7 +309,12 @@ func (s *Server) cmdJset(msg *Message) (res resp.Value, d commandDetails, err er
 	d.updated = true
 
 	s.clearIDExpires(key, id)
-	col.Set(d.id, d.obj, nil, nil)
+	oldObj, _, _ := col.Set(d.id, d.obj, nil, nil)
+	s.keepHistory(key, id, oldObj)
+	if ttl := s.keyDefaultTTL(key); ttl > 0 {
+		s.expireAt(key, id, d.timestamp.Add(
+			time.Duration(float64(time.Second)*ttl)))
+	}
 	switch msg.OutputType {
 	case JSON:
 		var buf bytes.Buffer
@@ -372,7 +380,8 @@ func (s *Server) cmdJdel(msg *Message) (res resp.Value, d commandDetails, err er
 	d.updated = true
 
 	s.clearIDExpires(d.key, d.id)
-	col.Set(d.id, d.obj, nil, nil)
+	oldObj, _, _ := col.Set(d.id, d.obj, nil, nil)
+	s.keepHistory(d.key, d.id, oldObj)
 	switch msg.OutputType {
 	case JSON:
 		var buf bytes.Buffer
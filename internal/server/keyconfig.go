@@ -0,0 +1,358 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geometry"
+	"github.com/tidwall/redcon"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/collection"
+)
+
+// Key configuration parameters
+const (
+	keyDefaultTTL = "default-ttl"
+	keyMaxObjects = "max-objects"
+	keyIndex      = "index"
+	keyHistory    = "history"
+)
+
+var keyConfigParams = []string{keyDefaultTTL, keyMaxObjects, keyIndex,
+	keyHistory}
+
+var errMaxObjects = errors.New("key has reached max-objects")
+var errKeyHasNoHistory = errors.New("key has no history")
+
+// keyConfig is the configuration of a key, set with KEYCONFIG. It belongs to
+// the key, not to the collection, so it stays when the last object of the
+// key is deleted. DROP and FLUSHDB remove it, and RENAME moves it, along
+// with the history of the key.
+type keyConfig struct {
+	defaultTTL float64 // seconds, zero is no ttl
+	maxObjects int     // zero is unlimited
+	index      string  // geometry index kind, empty is the server default
+	history    bool    // keep the previous objects of each id
+}
+
+func (kc *keyConfig) setProperty(name, value string) error {
+	var invalid bool
+	switch strings.ToLower(name) {
+	default:
+		return fmt.Errorf("Unsupported KEYCONFIG parameter: %s", name)
+	case keyDefaultTTL:
+		if value == "" {
+			value = "0"
+		}
+		ttl, err := strconv.ParseFloat(value, 64)
+		if err != nil || ttl < 0 {
+			invalid = true
+		} else {
+			kc.defaultTTL = ttl
+		}
+	case keyMaxObjects:
+		if value == "" {
+			value = "0"
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			invalid = true
+		} else {
+			kc.maxObjects = int(n)
+		}
+	case keyIndex:
+		switch strings.ToLower(value) {
+		case "", "none", "rtree", "quadtree":
+			kc.index = strings.ToLower(value)
+		default:
+			invalid = true
+		}
+	case keyHistory:
+		switch strings.ToLower(value) {
+		case "on":
+			kc.history = true
+		case "", "off":
+			kc.history = false
+		default:
+			invalid = true
+		}
+	}
+	if invalid {
+		return fmt.Errorf("Invalid argument '%s' for KEYCONFIG SET '%s'",
+			value, name)
+	}
+	return nil
+}
+
+func (kc *keyConfig) getProperty(name string) string {
+	switch name {
+	case keyDefaultTTL:
+		return strconv.FormatFloat(kc.defaultTTL, 'f', -1, 64)
+	case keyMaxObjects:
+		return strconv.Itoa(kc.maxObjects)
+	case keyIndex:
+		return kc.index
+	case keyHistory:
+		if kc.history {
+			return "on"
+		}
+		return "off"
+	}
+	return ""
+}
+
+// empty returns true when every parameter has its default value.
+func (kc *keyConfig) empty() bool {
+	return *kc == keyConfig{}
+}
+
+// indexKind returns the geometry index kind of the key.
+func (kc *keyConfig) indexKind() geometry.IndexKind {
+	switch kc.index {
+	case "none":
+		return geometry.None
+	case "rtree":
+		return geometry.RTree
+	}
+	return geometry.QuadTree
+}
+
+// keyParseOpts returns the options for parsing the objects of a key.
+func (s *Server) keyParseOpts(key string) *geojson.ParseOptions {
+	kc := s.keyConfigs[key]
+	if kc == nil || kc.index == "" {
+		return &s.geomParseOpts
+	}
+	opts := s.geomParseOpts
+	opts.IndexGeometryKind = kc.indexKind()
+	return &opts
+}
+
+// reindexKey parses the objects of a key again when the geometry index kind
+// of the key has changed, so that every object has the index of the key.
+// Points and rectangles have no geometry index and are kept.
+func (s *Server) reindexKey(key string, opts *geojson.ParseOptions) error {
+	col := s.getCol(key)
+	if col == nil {
+		return nil
+	}
+	type reindexed struct {
+		id  string
+		obj geojson.Object
+	}
+	var objs []reindexed
+	var err error
+	col.Scan(false, nil, nil,
+		func(id string, o geojson.Object, fields []float64) bool {
+			switch o.(type) {
+			case collection.String, *geojson.Point, *geojson.SimplePoint,
+				*geojson.Rect:
+				return true
+			}
+			var obj geojson.Object
+			obj, err = geojson.Parse(o.String(), opts)
+			if err != nil {
+				return false
+			}
+			objs = append(objs, reindexed{id, obj})
+			return true
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		col.Set(o.id, o.obj, nil, nil)
+	}
+	return nil
+}
+
+// keyDefaultTTL returns the ttl of the objects of a key that are set without
+// EX, or zero for none.
+func (s *Server) keyDefaultTTL(key string) float64 {
+	if kc := s.keyConfigs[key]; kc != nil {
+		return kc.defaultTTL
+	}
+	return 0
+}
+
+// checkMaxObjects returns errMaxObjects when setting an object would grow a
+// key over its max-objects. Replacing an object is always allowed.
+func (s *Server) checkMaxObjects(
+	key, id string, col *collection.Collection,
+) error {
+	kc := s.keyConfigs[key]
+	if kc == nil || kc.maxObjects == 0 || col.Count() < kc.maxObjects {
+		return nil
+	}
+	if _, ok := col.Access(id); ok {
+		return nil
+	}
+	return errMaxObjects
+}
+
+// keyConfigArgs returns the KEYCONFIG command that restores the parameters
+// of a key, for the aof shrink. The index and the history are restored before
+// the objects are loaded, so that they are indexed like before and their
+// history is kept, and the other parameters after, so that objects without a
+// ttl or over max-objects are kept.
+func (kc *keyConfig) keyConfigArgs(key string, beforeObjects bool) []string {
+	args := []string{"keyconfig", key, "set"}
+	if beforeObjects {
+		if kc.index != "" {
+			args = append(args, keyIndex, kc.index)
+		}
+		if kc.history {
+			args = append(args, keyHistory, kc.getProperty(keyHistory))
+		}
+	} else {
+		if kc.defaultTTL != 0 {
+			args = append(args, keyDefaultTTL, kc.getProperty(keyDefaultTTL))
+		}
+		if kc.maxObjects != 0 {
+			args = append(args, keyMaxObjects, kc.getProperty(keyMaxObjects))
+		}
+	}
+	if len(args) == 3 {
+		return nil
+	}
+	return args
+}
+
+// moveKeyConfig moves the configuration and the history of a key to a
+// newKey.
+func (s *Server) moveKeyConfig(key, newKey string) {
+	delete(s.keyConfigs, newKey)
+	delete(s.histories, newKey)
+	if kc := s.keyConfigs[key]; kc != nil {
+		delete(s.keyConfigs, key)
+		s.keyConfigs[newKey] = kc
+	}
+	if h := s.histories[key]; h != nil {
+		delete(s.histories, key)
+		s.histories[newKey] = h
+	}
+}
+
+// deleteKeyConfig deletes the configuration and the history of a key.
+func (s *Server) deleteKeyConfig(key string) {
+	delete(s.keyConfigs, key)
+	delete(s.histories, key)
+}
+
+// appendKeyConfig appends the KEYCONFIG command of keyConfigArgs to an aof
+// buffer.
+func appendKeyConfig(
+	aofbuf []byte, kc *keyConfig, key string, beforeObjects bool,
+) []byte {
+	args := kc.keyConfigArgs(key, beforeObjects)
+	if args == nil {
+		return aofbuf
+	}
+	aofbuf = redcon.AppendArray(aofbuf, len(args))
+	for _, arg := range args {
+		aofbuf = redcon.AppendBulkString(aofbuf, arg)
+	}
+	return aofbuf
+}
+
+// isKeyConfigWrite returns true for the KEYCONFIG commands that are written
+// to the aof.
+func isKeyConfigWrite(msg *Message) bool {
+	return len(msg.Args) > 2 && strings.ToLower(msg.Args[2]) == "set"
+}
+
+// KEYCONFIG key SET parameter value [parameter value ...]
+// KEYCONFIG key GET
+//
+// Changing the index of a key with objects indexes the objects again, and
+// turning the history off forgets the history of the key.
+func (s *Server) cmdKeyConfig(msg *Message) (
+	res resp.Value, d commandDetails, err error,
+) {
+	start := time.Now()
+	if len(msg.Args) < 3 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	d.key = msg.Args[1]
+	switch strings.ToLower(msg.Args[2]) {
+	default:
+		return NOMessage, d, errInvalidArgument(msg.Args[2])
+	case "get":
+		if len(msg.Args) != 3 {
+			return NOMessage, d, errInvalidNumberOfArguments
+		}
+		kc := s.keyConfigs[d.key]
+		if kc == nil {
+			kc = &keyConfig{}
+		}
+		m := make(map[string]interface{})
+		for _, name := range keyConfigParams {
+			m[name] = kc.getProperty(name)
+		}
+		switch msg.OutputType {
+		case JSON:
+			data, err := json.Marshal(m)
+			if err != nil {
+				return NOMessage, d, err
+			}
+			res = resp.StringValue(`{"ok":true,"properties":` +
+				string(data) + `,"elapsed":"` +
+				time.Now().Sub(start).String() + "\"}")
+		case RESP:
+			res = respSimpleMap(msg, m)
+		}
+		return res, d, nil
+	case "set":
+	}
+	vs := msg.Args[3:]
+	if len(vs) == 0 || len(vs)%2 != 0 {
+		return NOMessage, d, errInvalidNumberOfArguments
+	}
+	// all of the parameters are set, or none
+	var kc keyConfig
+	if prev := s.keyConfigs[d.key]; prev != nil {
+		kc = *prev
+	}
+	for i := 0; i < len(vs); i += 2 {
+		if err := kc.setProperty(vs[i], vs[i+1]); err != nil {
+			return NOMessage, d, err
+		}
+	}
+	prev := s.keyConfigs[d.key]
+	prevKind := s.keyParseOpts(d.key).IndexGeometryKind
+	if kc.empty() {
+		delete(s.keyConfigs, d.key)
+	} else {
+		s.keyConfigs[d.key] = &kc
+	}
+	if opts := s.keyParseOpts(d.key); opts.IndexGeometryKind != prevKind {
+		if err := s.reindexKey(d.key, opts); err != nil {
+			if prev == nil {
+				delete(s.keyConfigs, d.key)
+			} else {
+				s.keyConfigs[d.key] = prev
+			}
+			return NOMessage, d, err
+		}
+	}
+	if !kc.history {
+		delete(s.histories, d.key)
+	}
+	d.command = "keyconfig"
+	d.updated = true
+	d.timestamp = time.Now()
+	switch msg.OutputType {
+	case JSON:
+		res = resp.StringValue(`{"ok":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}")
+	case RESP:
+		res = resp.SimpleStringValue("OK")
+	}
+	return res, d, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"strings"
+)
+
+// Keyspace notification flags, set with the notify-keyspace-events config
+// property. At least one of notifyKeyspace or notifyKeyevent and at least
+// one event class must be set for anything to be published.
+const (
+	notifyKeyspace = 1 << iota // K: publish on __keyspace__:<key>
+	notifyKeyevent             // E: publish on __keyevent__:<event>
+	notifyGeneric              // g: del, drop, rename
+	notifyObject               // o: set, fset
+	notifyExpired              // x: expired, evicted
+
+	notifyAll = notifyGeneric | notifyObject | notifyExpired // A
+)
+
+// parseNotifyKeyspaceEvents parses the flags of a notify-keyspace-events
+// value. An empty value disables the notifications.
+func parseNotifyKeyspaceEvents(value string) (flags int, ok bool) {
+	for _, c := range value {
+		switch c {
+		case 'K':
+			flags |= notifyKeyspace
+		case 'E':
+			flags |= notifyKeyevent
+		case 'g':
+			flags |= notifyGeneric
+		case 'o':
+			flags |= notifyObject
+		case 'x':
+			flags |= notifyExpired
+		case 'A':
+			flags |= notifyAll
+		default:
+			return 0, false
+		}
+	}
+	return flags, true
+}
+
+// formatNotifyKeyspaceEvents is the reverse of parseNotifyKeyspaceEvents.
+func formatNotifyKeyspaceEvents(flags int) string {
+	var b strings.Builder
+	if flags&notifyAll == notifyAll {
+		b.WriteByte('A')
+	} else {
+		if flags&notifyGeneric != 0 {
+			b.WriteByte('g')
+		}
+		if flags&notifyObject != 0 {
+			b.WriteByte('o')
+		}
+		if flags&notifyExpired != 0 {
+			b.WriteByte('x')
+		}
+	}
+	if flags&notifyKeyspace != 0 {
+		b.WriteByte('K')
+	}
+	if flags&notifyKeyevent != 0 {
+		b.WriteByte('E')
+	}
+	return b.String()
+}
+
+// notifyKeyspaceEvent publishes a keyspace notification for a write. The
+// message is a JSON object with the event, the collection key and the object
+// id, when there is one. It's sent to __keyspace__:<key> and to
+// __keyevent__:<event>, depending on the configured flags.
+func (s *Server) notifyKeyspaceEvent(d *commandDetails) {
+	flags := s.config.notifyKeyspaceEvents()
+	if flags&(notifyKeyspace|notifyKeyevent) == 0 {
+		return
+	}
+	if d.parent {
+		for _, d := range d.children {
+			s.notifyKeyspaceEvent(d)
+		}
+		return
+	}
+	var event string
+	var class int
+	switch d.command {
+	case "set", "fset":
+		event, class = d.command, notifyObject
+	case "del", "drop", "rename":
+		event, class = d.command, notifyGeneric
+	case "expire":
+		event, class = "expired", notifyExpired
+	case "evict":
+		event, class = "evicted", notifyExpired
+	default:
+		return
+	}
+	if flags&class == 0 {
+		return
+	}
+	msg := `{"event":` + jsonString(event) + `,"key":` + jsonString(d.key)
+	if d.id != "" {
+		msg += `,"id":` + jsonString(d.id)
+	}
+	if d.newKey != "" {
+		msg += `,"new_key":` + jsonString(d.newKey)
+	}
+	msg += `}`
+	if flags&notifyKeyspace != 0 {
+		s.Publish("__keyspace__:"+d.key, msg)
+		if d.newKey != "" {
+			s.Publish("__keyspace__:"+d.newKey, msg)
+		}
+	}
+	if flags&notifyKeyevent != 0 {
+		s.Publish("__keyevent__:"+event, msg)
+	}
+}
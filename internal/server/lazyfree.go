@@ -0,0 +1,45 @@
+package server
+
+import (
+	"runtime"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/tile38/internal/collection"
+)
+
+// lazyfreeBatchSize is the number of objects that are removed at a time from
+// a collection that is being freed in the background.
+const lazyfreeBatchSize = 1024
+
+// lazyfree releases the objects of collections that have been detached from
+// the database by DROP ASYNC or FLUSHDB ASYNC. The objects are removed in
+// small batches from a background goroutine, which allows for the memory to
+// be reclaimed gradually rather than all at once. The collections must not
+// be accessible to any other part of the server.
+func (s *Server) lazyfree(cols []*collection.Collection) {
+	var pending int
+	for _, col := range cols {
+		pending += col.Count()
+	}
+	s.statsLazyfreePending.add(pending)
+	go func() {
+		ids := make([]string, 0, lazyfreeBatchSize)
+		for _, col := range cols {
+			for col.Count() > 0 {
+				ids = ids[:0]
+				col.Scan(false, nil, nil,
+					func(id string, obj geojson.Object, fields []float64) bool {
+						ids = append(ids, id)
+						return len(ids) < lazyfreeBatchSize
+					},
+				)
+				for _, id := range ids {
+					col.Delete(id)
+				}
+				s.statsLazyfreePending.add(-len(ids))
+				s.statsLazyfreed.add(len(ids))
+				runtime.Gosched()
+			}
+		}
+	}()
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/resp"
+)
+
+// Rate limits
+//
+// The commands and the bytes of their arguments are counted per second for
+// each client, and the number of searches that run at the same time is
+// limited for the whole server. The limits are set with CONFIG SET, and
+// CLIENT LIMIT overrides the client limits of a connection. A command that
+// is over a limit is rejected with a LIMIT error. There are no per-user
+// limits, the requirepass is shared by every client and the username of
+// HELLO AUTH isn't checked, so there is no user to count.
+
+// errLimit is the error of a rejected command.
+type errLimit struct{ reason string }
+
+func (err errLimit) Error() string {
+	return "too many " + err.reason
+}
+
+var (
+	errLimitCommands = errLimit{"commands per second"}
+	errLimitBytes    = errLimit{"bytes per second"}
+	errLimitHeavy    = errLimit{"concurrent heavy queries"}
+)
+
+// parseLimit parses a limit value. An empty value is unlimited.
+func parseLimit(value string) (int64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(value, 10, 63)
+	if err != nil {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+// formatLimit is the reverse of parseLimit.
+func formatLimit(limit int64) string {
+	if limit == 0 {
+		return ""
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+// lowerLimit returns the lower of two limits, where zero is unlimited.
+func lowerLimit(a, b int64) int64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// rateWindow counts in one second windows.
+type rateWindow struct {
+	sec   int64
+	count int64
+}
+
+// allow adds n to the window when that keeps it within the limit. The first
+// add of a window is always allowed, which lets a large command through.
+func (w *rateWindow) allow(sec, n, limit int64) bool {
+	if w.sec != sec {
+		w.sec = sec
+		w.count = 0
+	}
+	if limit > 0 && w.count > 0 && w.count+n > limit {
+		return false
+	}
+	w.count += n
+	return true
+}
+
+// clientLimits are the counters and the overrides of a client. The overrides
+// are set with CLIENT LIMIT, and zero uses the config. An override can only
+// lower the config.
+type clientLimits struct {
+	commands    rateWindow
+	bytes       rateWindow
+	maxCommands int64
+	maxBytes    int64
+}
+
+// checkLimits counts a command of a client and returns an errLimit when the
+// command is over a limit. A non-nil done function must be called once a
+// search has finished.
+func (s *Server) checkLimits(client *Client, msg *Message) (
+	done func(), err error,
+) {
+	var n int64
+	for _, arg := range msg.Args {
+		n += int64(len(arg))
+	}
+	sec := time.Now().Unix()
+
+	client.mu.Lock()
+	maxCommands := lowerLimit(client.limits.maxCommands,
+		s.config.clientCommandsPerSec())
+	maxBytes := lowerLimit(client.limits.maxBytes, s.config.clientBytesPerSec())
+	if !client.limits.commands.allow(sec, 1, maxCommands) {
+		err = errLimitCommands
+	} else if !client.limits.bytes.allow(sec, n, maxBytes) {
+		err = errLimitBytes
+	}
+	client.mu.Unlock()
+	if err != nil {
+		s.statsRejected(err)
+		return nil, err
+	}
+
+	if !isSearchCommand(msg.Command()) {
+		return nil, nil
+	}
+	maxHeavy := s.config.maxHeavyQueries()
+	if n := s.heavyQueries.add(1); maxHeavy > 0 && int64(n) > maxHeavy {
+		s.heavyQueries.add(-1)
+		s.statsRejected(errLimitHeavy)
+		return nil, errLimitHeavy
+	}
+	return func() { s.heavyQueries.add(-1) }, nil
+}
+
+func (s *Server) statsRejected(err error) {
+	switch err {
+	case errLimitCommands:
+		s.statsRejectedCommands.add(1)
+	case errLimitBytes:
+		s.statsRejectedBytes.add(1)
+	case errLimitHeavy:
+		s.statsRejectedHeavy.add(1)
+	}
+}
+
+// checkClientLimit returns an error when a CLIENT LIMIT value is above the
+// config.
+func checkClientLimit(name string, limit, config int64) error {
+	if config > 0 && limit > config {
+		return errors.New("limit is above the configured " + name)
+	}
+	return nil
+}
+
+// cmdClientLimit handles the CLIENT LIMIT command.
+//
+// CLIENT LIMIT id [COMMANDS count] [BYTES count]
+//
+// The limits are per second and override the client-commands-per-sec and
+// client-bytes-per-sec config of the client. Zero uses the config. A limit
+// can't be above the config, so that a client can't raise its own limit.
+func (s *Server) cmdClientLimit(msg *Message) (resp.Value, error) {
+	start := time.Now()
+	if len(msg.Args) < 4 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	id, err := strconv.Atoi(msg.Args[2])
+	if err != nil {
+		return NOMessage, errInvalidArgument(msg.Args[2])
+	}
+	var setCommands, setBytes bool
+	var maxCommands, maxBytes int64
+	for i := 3; i < len(msg.Args); i++ {
+		opt := strings.ToLower(msg.Args[i])
+		if opt != "commands" && opt != "bytes" {
+			return NOMessage, errInvalidArgument(msg.Args[i])
+		}
+		i++
+		if i == len(msg.Args) {
+			return NOMessage, errInvalidNumberOfArguments
+		}
+		n, err := strconv.ParseUint(msg.Args[i], 10, 63)
+		if err != nil {
+			return NOMessage, errInvalidArgument(msg.Args[i])
+		}
+		if opt == "commands" {
+			setCommands, maxCommands = true, int64(n)
+			err = checkClientLimit(ClientCommandsPerSec, maxCommands,
+				s.config.clientCommandsPerSec())
+		} else {
+			setBytes, maxBytes = true, int64(n)
+			err = checkClientLimit(ClientBytesPerSec, maxBytes,
+				s.config.clientBytesPerSec())
+		}
+		if err != nil {
+			return NOMessage, err
+		}
+	}
+	s.connsmu.RLock()
+	client := s.conns[id]
+	s.connsmu.RUnlock()
+	if client == nil {
+		return NOMessage, errors.New("No such client")
+	}
+	client.mu.Lock()
+	if setCommands {
+		client.limits.maxCommands = maxCommands
+	}
+	if setBytes {
+		client.limits.maxBytes = maxBytes
+	}
+	client.mu.Unlock()
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return resp.SimpleStringValue("OK"), nil
+	}
+	return NOMessage, nil
+}
@@ -107,7 +107,8 @@ func (server *Server) goLive(
 	server.mu.RLock()
 	sw, err = server.newScanWriter(
 		&wr, msg, s.key, s.output, s.precision, s.glob, false,
-		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.nofields)
+		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.whereexprs,
+		s.wherejsons, s.nofields)
 	server.mu.RUnlock()
 
 	// everything below if for live SCAN, NEARBY, WITHIN, INTERSECTS
@@ -157,6 +158,7 @@ func (server *Server) goLive(
 	}()
 	outputType := msg.OutputType
 	connType := msg.ConnType
+	resp3 := msg.RESP3 && !websocket
 	if websocket {
 		outputType = JSON
 	}
@@ -167,7 +169,18 @@ func (server *Server) goLive(
 	case RESP:
 		livemsg = redcon.AppendOK(nil)
 	}
-	if err := writeLiveMessage(conn, livemsg, false, connType, websocket); err != nil {
+	var sse *sseWriter
+	if connType == SSE {
+		// SSE clients receive the events in a text/event-stream
+		if sse, err = newSSEWriter(conn); err != nil {
+			return nil // nil return is fine here
+		}
+		defer sse.close()
+		err = sse.writeEvent(0, `{"ok":true,"live":true}`)
+	} else {
+		err = writeLiveMessage(conn, livemsg, false, connType, websocket)
+	}
+	if err != nil {
 		return nil // nil return is fine here
 	}
 	for {
@@ -192,7 +205,19 @@ func (server *Server) goLive(
 				msgs = FenceMatch("", sw, fence, nil, details)
 			}()
 			for _, msg := range msgs {
-				if err := writeLiveMessage(conn, []byte(msg), true, connType, websocket); err != nil {
+				var err error
+				if sse != nil {
+					err = sse.writeEvent(0, msg)
+				} else if resp3 {
+					// RESP3 clients receive the events as push messages
+					b := appendPush(nil, 2, true)
+					b = redcon.AppendBulkString(b, "fence")
+					b = redcon.AppendBulkString(b, msg)
+					err = writeLiveMessage(conn, b, false, connType, websocket)
+				} else {
+					err = writeLiveMessage(conn, []byte(msg), true, connType, websocket)
+				}
+				if err != nil {
 					return nil // nil return is fine here
 				}
 			}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/log"
+)
+
+// multiCommands are the commands that may be queued by MULTI. The value is
+// true for commands that write.
+var multiCommands = map[string]bool{
+	"set":         true,
+	"fset":        true,
+	"del":         true,
+	"pdel":        true,
+	"drop":        true,
+	"flushdb":     true,
+	"expire":      true,
+	"persist":     true,
+	"jset":        true,
+	"jdel":        true,
+	"rename":      true,
+	"renamenx":    true,
+	"createindex": true,
+	"dropindex":   true,
+	"get":         false,
+	"jget":        false,
+	"ttl":         false,
+	"type":        false,
+	"bounds":      false,
+	"keys":        false,
+	"scan":        false,
+}
+
+// watchT is the state of a watched object at the time of WATCH.
+type watchT struct {
+	key    string
+	id     string
+	obj    geojson.Object
+	fields []float64
+}
+
+func (s *Server) getWatch(key, id string) watchT {
+	w := watchT{key: key, id: id}
+	if col := s.getCol(key); col != nil {
+		var fields []float64
+		w.obj, fields, _ = col.Get(id)
+		w.fields = append([]float64(nil), fields...)
+	}
+	return w
+}
+
+func (w watchT) changed(s *Server) bool {
+	cur := s.getWatch(w.key, w.id)
+	if cur.obj != w.obj || len(cur.fields) != len(w.fields) {
+		return true
+	}
+	for i := range cur.fields {
+		if cur.fields[i] != w.fields[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// queueMulti adds a command to the transaction of a client that is in
+// MULTI. A command that cannot be queued fails the whole transaction.
+func (s *Server) queueMulti(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	if _, ok := multiCommands[msg.Command()]; !ok {
+		client.multiErr = true
+		return NOMessage, errors.New(
+			"'" + msg.Command() + "' is not allowed in MULTI")
+	}
+	qmsg := *msg
+	qmsg.Args = append([]string(nil), msg.Args...)
+	qmsg.Deadline = nil
+	client.queued = append(client.queued, &qmsg)
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"queued":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return resp.SimpleStringValue("QUEUED"), nil
+	}
+	return NOMessage, nil
+}
+
+// MULTI
+func (s *Server) cmdMulti(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	if len(msg.Args) != 1 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if client == nil || msg.ConnType == HTTP || msg.ConnType == REST ||
+		msg.ConnType == SSE {
+		return NOMessage, errors.New("MULTI is not supported on this connection")
+	}
+	if client.multi {
+		return NOMessage, errors.New("MULTI calls can not be nested")
+	}
+	client.multi = true
+	return OKMessage(msg, start), nil
+}
+
+// DISCARD
+func (s *Server) cmdDiscard(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	if len(msg.Args) != 1 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if client == nil || !client.multi {
+		return NOMessage, errors.New("DISCARD without MULTI")
+	}
+	client.resetMulti()
+	return OKMessage(msg, start), nil
+}
+
+// WATCH key id [key id ...]
+func (s *Server) cmdWatch(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	vs := msg.Args[1:]
+	if len(vs) == 0 || len(vs)%2 != 0 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if client == nil || msg.ConnType == HTTP || msg.ConnType == REST ||
+		msg.ConnType == SSE {
+		return NOMessage, errors.New("WATCH is not supported on this connection")
+	}
+	if client.multi {
+		return NOMessage, errors.New("WATCH inside MULTI is not allowed")
+	}
+	for i := 0; i < len(vs); i += 2 {
+		client.watches = append(client.watches, s.getWatch(vs[i], vs[i+1]))
+	}
+	return OKMessage(msg, start), nil
+}
+
+// UNWATCH
+func (s *Server) cmdUnwatch(msg *Message, client *Client) (resp.Value, error) {
+	start := time.Now()
+	if len(msg.Args) != 1 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if client != nil {
+		client.watches = nil
+	}
+	return OKMessage(msg, start), nil
+}
+
+// EXEC runs the queued commands while holding the write lock. Nothing runs
+// when a watched object has changed since it was watched. The writes are
+// appended to the AOF together, and the hooks are queued at once, only once
+// every command has run. A command that fails does not undo the commands before
+// it.
+func (s *Server) cmdExec(msg *Message, client *Client) (res resp.Value, err error) {
+	start := time.Now()
+	if len(msg.Args) != 1 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	if client == nil || !client.multi {
+		return NOMessage, errors.New("EXEC without MULTI")
+	}
+	queued, watches, multiErr := client.queued, client.watches, client.multiErr
+	client.resetMulti()
+	client.watches = nil
+	if multiErr {
+		return NOMessage, errors.New(
+			"transaction discarded because of previous errors")
+	}
+	for _, w := range watches {
+		if w.changed(s) {
+			switch msg.OutputType {
+			case JSON:
+				return resp.StringValue(`{"ok":true,"results":null,"elapsed":"` +
+					time.Now().Sub(start).String() + "\"}"), nil
+			case RESP:
+				return resp.NullValue(), nil
+			}
+			return NOMessage, nil
+		}
+	}
+	for _, qmsg := range queued {
+		if multiCommands[qmsg.Command()] {
+			if s.config.followHost() != "" {
+				return NOMessage, errNotLeader
+			}
+			if s.config.readOnly() {
+				return NOMessage, errReadOnly
+			}
+			break
+		}
+	}
+	results := make([]resp.Value, len(queued))
+	details := make([]commandDetails, len(queued))
+	for i, qmsg := range queued {
+		var err error
+		results[i], details[i], err = s.command(qmsg, client)
+		if err != nil {
+			errMsg := err.Error()
+			if err == errInvalidNumberOfArguments {
+				errMsg = "wrong number of arguments for '" +
+					qmsg.Command() + "' command"
+			}
+			switch msg.OutputType {
+			case JSON:
+				results[i] = resp.StringValue(`{"ok":false,"err":` +
+					jsonString(errMsg) + "}")
+			case RESP:
+				results[i] = resp.ErrorValue(errors.New("ERR " + errMsg))
+			}
+		} else if msg.resp3 != nil && qmsg.resp3 != nil {
+			msg.resp3.merge(qmsg.resp3, &results[i])
+		}
+	}
+	// every write is appended before anyone is notified, like a batch of
+	// IMPORT, so the results of the commands are never lost.
+	d := commandDetails{parent: true, timestamp: time.Now()}
+	for i, qmsg := range queued {
+		if !details[i].updated {
+			continue
+		}
+		s.appendAOF(qmsg.Args)
+		if details[i].parent {
+			d.children = append(d.children, details[i].children...)
+		} else {
+			d.children = append(d.children, &details[i])
+		}
+	}
+	if len(d.children) > 0 {
+		d.updated = true
+		if err := s.notifyWrite(&d); err != nil {
+			log.Errorf("exec: %v", err)
+		}
+	}
+	switch msg.OutputType {
+	case JSON:
+		var buf strings.Builder
+		buf.WriteString(`{"ok":true,"results":[`)
+		for i, res := range results {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(res.String())
+		}
+		buf.WriteString(`],"elapsed":"` + time.Now().Sub(start).String() + "\"}")
+		return resp.StringValue(buf.String()), nil
+	case RESP:
+		return resp.ArrayValue(results), nil
+	}
+	return NOMessage, nil
+}
@@ -35,6 +35,12 @@ func newPubsub() *pubsub {
 
 // Publish a message to subscribers
 func (s *Server) Publish(channel string, message ...string) int {
+	return s.publish(channel, 0, message...)
+}
+
+// publish a message to subscribers. A non-zero id is the id of the message in
+// the hook queue.
+func (s *Server) publish(channel string, id uint64, message ...string) int {
 	var msgs []submsg
 	s.pubsub.mu.RLock()
 	if hub := s.pubsub.hubs[pubsubChannel][channel]; hub != nil {
@@ -45,6 +51,7 @@ func (s *Server) Publish(channel string, message ...string) int {
 					target:  target,
 					channel: channel,
 					message: message,
+					id:      id,
 				})
 			}
 		}
@@ -59,6 +66,7 @@ func (s *Server) Publish(channel string, message ...string) int {
 						channel: channel,
 						pattern: pattern,
 						message: message,
+						id:      id,
 					})
 				}
 			}
@@ -105,6 +113,7 @@ type submsg struct {
 	pattern string
 	channel string
 	message string
+	id      uint64 // hook queue id, zero if not queued
 }
 
 type subtarget struct {
@@ -183,19 +192,37 @@ func (s *Server) liveSubscription(
 
 	outputType := msg.OutputType
 	connType := msg.ConnType
-	if websocket {
+	resp3 := msg.RESP3
+	if websocket || connType == SSE {
 		outputType = JSON
 	}
 
+	var sse *sseWriter
+	if connType == SSE {
+		// SSE clients receive the messages in a text/event-stream
+		var err error
+		if sse, err = newSSEWriter(conn); err != nil {
+			return nil
+		}
+		defer sse.close()
+	}
+
 	var start time.Time
 
 	// write helpers
 	var writeLock sync.Mutex
-	write := func(data []byte) {
+	writeEvent := func(id uint64, data []byte) {
 		writeLock.Lock()
 		defer writeLock.Unlock()
+		if sse != nil {
+			sse.writeEvent(id, string(data))
+			return
+		}
 		writeLiveMessage(conn, data, outputType == JSON, connType, websocket)
 	}
+	write := func(data []byte) {
+		writeEvent(0, data)
+	}
 	writeOK := func() {
 		switch outputType {
 		case JSON:
@@ -236,7 +263,7 @@ func (s *Server) liveSubscription(
 				`,"num":` + strconv.FormatInt(int64(num), 10) +
 				`,"elapsed":"` + time.Now().Sub(start).String() + `"}`))
 		case RESP:
-			b := redcon.AppendArray(nil, 3)
+			b := appendPush(nil, 3, resp3)
 			b = redcon.AppendBulkString(b, command)
 			b = redcon.AppendBulkString(b, channel)
 			b = redcon.AppendInt(b, int64(num))
@@ -253,9 +280,9 @@ func (s *Server) liveSubscription(
 				} else {
 					data = []byte(msg.message)
 				}
-				write(data)
+				writeEvent(msg.id, data)
 			case RESP:
-				b := redcon.AppendArray(nil, 3)
+				b := appendPush(nil, 3, resp3)
 				b = redcon.AppendBulkString(b, "message")
 				b = redcon.AppendBulkString(b, msg.channel)
 				b = redcon.AppendBulkString(b, msg.message)
@@ -270,9 +297,9 @@ func (s *Server) liveSubscription(
 				} else {
 					data = []byte(msg.message)
 				}
-				write(data)
+				writeEvent(msg.id, data)
 			case RESP:
-				b := redcon.AppendArray(nil, 4)
+				b := appendPush(nil, 4, resp3)
 				b = redcon.AppendBulkString(b, "pmessage")
 				b = redcon.AppendBulkString(b, msg.pattern)
 				b = redcon.AppendBulkString(b, msg.channel)
@@ -301,9 +328,26 @@ func (s *Server) liveSubscription(
 		target.cond.Broadcast()
 		target.cond.L.Unlock()
 	}()
+
+	// An SSE client that reconnects with a Last-Event-ID first receives the
+	// queued channel messages that it missed. The live messages wait until
+	// the missed ones are written, and those already replayed are skipped.
+	var lastID, replayedID uint64
+	var replay bool
+	if sse != nil && msg.LastEventID != "" {
+		n, err := strconv.ParseUint(msg.LastEventID, 10, 64)
+		if err == nil {
+			lastID, replay = n, true
+		}
+	}
+	ready := make(chan struct{})
+	if !replay {
+		close(ready)
+	}
 	go func() {
 		log.Debugf("pubsub open")
 		defer log.Debugf("pubsub closed")
+		<-ready
 		for {
 			var msgs []submsg
 			target.cond.L.Lock()
@@ -313,6 +357,9 @@ func (s *Server) liveSubscription(
 			}
 			target.cond.L.Unlock()
 			for _, msg := range msgs {
+				if msg.id != 0 && msg.id <= replayedID {
+					continue
+				}
 				writeMessage(msg)
 			}
 			target.cond.L.Lock()
@@ -362,6 +409,18 @@ func (s *Server) liveSubscription(
 				writeSubscribe(msg.Command(), channel, len(m[0])+len(m[1]))
 			}
 		}
+		if replay {
+			replay = false
+			var channels []string
+			for channel := range m[pubsubChannel] {
+				channels = append(channels, channel)
+			}
+			for _, msg := range s.chanlog.since(channels, lastID) {
+				writeMessage(msg)
+				replayedID = msg.id
+			}
+			close(ready)
+		}
 		var err error
 		msgs, err = rd.ReadMessages()
 		if err != nil {
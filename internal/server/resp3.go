@@ -0,0 +1,245 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tidwall/redcon"
+	"github.com/tidwall/resp"
+)
+
+// A RESP connection switches to RESP3 with HELLO 3. Replies then use the
+// RESP3 maps, doubles, booleans and nulls, and the pub/sub and live geofence
+// messages are sent as push messages. RESP2 stays the default.
+//
+// The resp package only knows the RESP2 types, so the replies are always
+// made of RESP2 values, and the resp3Hints of the message record which of
+// them are RESP3 maps, doubles and booleans. Only marshalRESP reads the
+// hints, and the values stay the same for EXEC, scripts and JSON.
+
+type resp3Type byte
+
+const (
+	resp3Map    resp3Type = '%'
+	resp3Double resp3Type = ','
+	resp3Bool   resp3Type = '#'
+)
+
+// resp3Hints are the RESP3 types of the values of a reply. A map is found by
+// the address of the first element of its array, a double by the address of
+// the first byte of its string, and a boolean by the address of its element
+// in an array. The reply itself has its own type.
+type resp3Hints struct {
+	reply  resp3Type
+	values map[interface{}]resp3Type
+}
+
+// useRESP3 switches the replies of a message to RESP3, or back to RESP2.
+func (msg *Message) useRESP3(on bool) {
+	msg.RESP3 = on
+	msg.resp3 = nil
+	if on {
+		msg.resp3 = &resp3Hints{values: make(map[interface{}]resp3Type)}
+	}
+}
+
+// mapKey returns the key of the hint of a map. The array of an empty map
+// needs a capacity of one.
+func mapKey(vals []resp.Value) interface{} {
+	if cap(vals) == 0 {
+		return nil
+	}
+	return &vals[:1][0]
+}
+
+func doubleKey(v resp.Value) interface{} {
+	b := v.Bytes()
+	if len(b) == 0 {
+		return nil
+	}
+	return &b[0]
+}
+
+// typeOf returns the RESP3 type of a value, and zero when the RESP2 type is
+// used. The slot is the element of the array that holds the value, or nil
+// for the reply itself.
+func (h *resp3Hints) typeOf(v resp.Value, slot *resp.Value) resp3Type {
+	if slot == nil && h.reply != 0 {
+		return h.reply
+	}
+	var key interface{}
+	switch v.Type() {
+	case resp.Array:
+		key = mapKey(v.Array())
+	case resp.BulkString:
+		key = doubleKey(v)
+	case resp.Integer:
+		if slot != nil {
+			key = slot
+		}
+	}
+	if key == nil {
+		return 0
+	}
+	return h.values[key]
+}
+
+// merge adds the hints of the reply of a queued command, which is the
+// element slot of the EXEC reply.
+func (h *resp3Hints) merge(qh *resp3Hints, slot *resp.Value) {
+	for key, t := range qh.values {
+		h.values[key] = t
+	}
+	if qh.reply != 0 {
+		h.values[slot] = qh.reply
+	}
+}
+
+// respMap returns the key/value pairs as a flat array, which is a map for
+// RESP3.
+func respMap(msg *Message, vals []resp.Value) resp.Value {
+	if msg.resp3 != nil {
+		if cap(vals) == 0 {
+			vals = make([]resp.Value, 0, 1)
+		}
+		msg.resp3.values[mapKey(vals)] = resp3Map
+	}
+	return resp.ArrayValue(vals)
+}
+
+// respDouble returns a bulk string, which is a double for RESP3.
+func respDouble(msg *Message, f float64) resp.Value {
+	v := resp.FloatValue(f)
+	if msg.resp3 != nil {
+		msg.resp3.values[doubleKey(v)] = resp3Double
+	}
+	return v
+}
+
+// respBools marks the 1 or 0 integers of an array as booleans for RESP3.
+func respBools(msg *Message, vals []resp.Value, idxs ...int) {
+	if msg.resp3 != nil {
+		for _, i := range idxs {
+			msg.resp3.values[&vals[i]] = resp3Bool
+		}
+	}
+}
+
+// respBool returns a 1 or 0 integer reply, which is a boolean for RESP3.
+func respBool(msg *Message, t bool) resp.Value {
+	if msg.resp3 != nil {
+		msg.resp3.reply = resp3Bool
+	}
+	return resp.BoolValue(t)
+}
+
+// respSimpleMap returns the values of respValuesSimpleMap for RESP2, and
+// the map for RESP3, with numbers as integers or doubles.
+func respSimpleMap(msg *Message, m map[string]interface{}) resp.Value {
+	if msg.resp3 == nil {
+		return resp.ArrayValue(respValuesSimpleMap(m))
+	}
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	vals := make([]resp.Value, 0, len(keys)*2+1)
+	var bools []int
+	for _, key := range keys {
+		var val resp.Value
+		switch v := m[key].(type) {
+		case int:
+			val = resp.IntegerValue(v)
+		case int32:
+			val = resp.IntegerValue(int(v))
+		case int64:
+			val = resp.IntegerValue(int(v))
+		case uint32:
+			val = resp.IntegerValue(int(v))
+		case uint64:
+			val = resp.IntegerValue(int(v))
+		case float64:
+			val = respDouble(msg, v)
+		case bool:
+			val = resp.BoolValue(v)
+			bools = append(bools, len(vals)+1)
+		default:
+			val = resp.StringValue(fmt.Sprintf("%v", v))
+		}
+		vals = append(vals, resp.StringValue(key), val)
+	}
+	respBools(msg, vals, bools...)
+	return respMap(msg, vals)
+}
+
+// marshalRESP returns the RESP3 representation of a reply when the message
+// uses RESP3, and the RESP2 representation otherwise.
+func marshalRESP(v resp.Value, msg *Message) ([]byte, error) {
+	if msg.resp3 == nil {
+		return v.MarshalRESP()
+	}
+	return msg.resp3.appendValue(nil, v, nil)
+}
+
+func (h *resp3Hints) appendValue(b []byte, v resp.Value, slot *resp.Value,
+) ([]byte, error) {
+	if v.IsNull() {
+		return append(b, "_\r\n"...), nil
+	}
+	switch h.typeOf(v, slot) {
+	case resp3Double:
+		var s string
+		switch f := v.Float(); {
+		case math.IsInf(f, +1):
+			s = "inf"
+		case math.IsInf(f, -1):
+			s = "-inf"
+		case math.IsNaN(f):
+			s = "nan"
+		default:
+			s = v.String()
+		}
+		return append(append(append(b, ','), s...), '\r', '\n'), nil
+	case resp3Bool:
+		if v.Bool() {
+			return append(b, "#t\r\n"...), nil
+		}
+		return append(b, "#f\r\n"...), nil
+	case resp3Map:
+		b = appendRESPHeader(b, '%', len(v.Array())/2)
+	default:
+		if v.Type() != resp.Array {
+			data, err := v.MarshalRESP()
+			if err != nil {
+				return nil, err
+			}
+			return append(b, data...), nil
+		}
+		b = appendRESPHeader(b, '*', len(v.Array()))
+	}
+	vals := v.Array()
+	for i := range vals {
+		var err error
+		if b, err = h.appendValue(b, vals[i], &vals[i]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendRESPHeader(b []byte, typ byte, n int) []byte {
+	b = strconv.AppendInt(append(b, typ), int64(n), 10)
+	return append(b, '\r', '\n')
+}
+
+// appendPush appends the header of a push message for RESP3, or the header
+// of an array for RESP2.
+func appendPush(b []byte, n int, resp3 bool) []byte {
+	if !resp3 {
+		return redcon.AppendArray(b, n)
+	}
+	return appendRESPHeader(b, '>', n)
+}
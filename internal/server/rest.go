@@ -0,0 +1,400 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/tile38/core"
+	"github.com/tidwall/tile38/internal/log"
+)
+
+// restMaxBodySize is the largest request body accepted by the REST API.
+const restMaxBodySize = 64 * 1024 * 1024
+
+var errRESTNotFound = errors.New("not found")
+var errRESTInvalidJSON = errors.New("invalid json")
+
+// restServe serves the REST API on the --rest-port. The routes are:
+//
+//	GET    /keys                       KEYS
+//	DELETE /keys/{key}                 DROP
+//	GET    /keys/{key}/objects         SCAN
+//	GET    /keys/{key}/objects/{id}    GET
+//	PUT    /keys/{key}/objects/{id}    SET
+//	DELETE /keys/{key}/objects/{id}    DEL
+//	POST   /keys/{key}/search          NEARBY, WITHIN, INTERSECTS or SCAN
+//
+// Each request is translated into a command that runs through
+// handleInputCommand, the same as the other transports.
+func (server *Server) restServe() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("%s:%d", server.host, core.RESTPort))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	log.Infof("Ready to accept REST connections at %s", ln.Addr())
+	return http.Serve(ln, http.HandlerFunc(server.serveREST))
+}
+
+func (server *Server) serveREST(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		// CORS preflight
+		w.Header().Set("Access-Control-Allow-Methods",
+			"GET, PUT, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers",
+			"Authorization, Content-Type")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	if ip := net.ParseIP(host); (ip == nil || !ip.IsLoopback()) &&
+		server.isProtected() {
+		restError(w, http.StatusForbidden, "protected mode")
+		return
+	}
+	var parts []string
+	for _, part := range strings.Split(
+		strings.Trim(r.URL.EscapedPath(), "/"), "/",
+	) {
+		part, err := url.PathUnescape(part)
+		if err != nil || part == "" {
+			restError(w, http.StatusNotFound, errRESTNotFound.Error())
+			return
+		}
+		parts = append(parts, part)
+	}
+	var args []string
+	var allow string
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "keys":
+		allow = "GET"
+		if r.Method == http.MethodGet {
+			args = restKeysArgs(r.URL.Query())
+		}
+	case len(parts) == 2 && parts[0] == "keys":
+		allow = "DELETE"
+		if r.Method == http.MethodDelete {
+			args = []string{"drop", parts[1]}
+		}
+	case len(parts) == 3 && parts[0] == "keys" && parts[2] == "objects":
+		allow = "GET"
+		if r.Method == http.MethodGet {
+			args, err = restScanArgs(parts[1], r.URL.Query())
+		}
+	case len(parts) == 4 && parts[0] == "keys" && parts[2] == "objects":
+		allow = "GET, PUT, DELETE"
+		switch r.Method {
+		case http.MethodGet:
+			args, err = restGetArgs(parts[1], parts[3], r.URL.Query())
+		case http.MethodPut:
+			var body []byte
+			if body, err = restReadBody(w, r); err == nil {
+				args, err = restSetArgs(parts[1], parts[3], body)
+			}
+		case http.MethodDelete:
+			args = []string{"del", parts[1], parts[3]}
+		}
+	case len(parts) == 3 && parts[0] == "keys" && parts[2] == "search":
+		allow = "POST"
+		if r.Method == http.MethodPost {
+			var body []byte
+			if body, err = restReadBody(w, r); err == nil {
+				args, err = restSearchArgs(parts[1], body)
+			}
+		}
+	default:
+		restError(w, http.StatusNotFound, errRESTNotFound.Error())
+		return
+	}
+	if err != nil {
+		restError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if args == nil {
+		w.Header().Set("Allow", allow+", OPTIONS")
+		restError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		auth = auth[len("Bearer "):]
+	}
+	msg := &Message{Args: args, ConnType: REST, OutputType: JSON, Auth: auth}
+	client := &Client{
+		remoteAddr: r.RemoteAddr,
+		opened:     time.Now(),
+		last:       time.Now(),
+	}
+	server.statsTotalCommands.add(1)
+	if err := server.handleInputCommand(client, msg); err != nil {
+		log.Error(err)
+		restError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if atomic.LoadInt32(&server.aofdirty) != 0 {
+		func() {
+			// prewrite
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			server.flushAOF(false)
+		}()
+		atomic.StoreInt32(&server.aofdirty, 0)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(client.out)))
+	w.WriteHeader(restStatus(client.out))
+	w.Write(client.out)
+}
+
+// restStatus returns the HTTP status code for a JSON command response.
+func restStatus(res []byte) int {
+	if gjson.GetBytes(res, "ok").Bool() {
+		return http.StatusOK
+	}
+	switch gjson.GetBytes(res, "err").String() {
+	case errKeyNotFound.Error(), errIDNotFound.Error():
+		return http.StatusNotFound
+	case "authentication required", "invalid password":
+		return http.StatusUnauthorized
+	case errReadOnly.Error():
+		return http.StatusForbidden
+	case errNotLeader.Error(), errCatchingUp.Error():
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+func restError(w http.ResponseWriter, status int, errMsg string) {
+	res := `{"ok":false,"err":` + jsonString(errMsg) + `}`
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(res)))
+	w.WriteHeader(status)
+	w.Write([]byte(res))
+}
+
+func restReadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, restMaxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, errRESTInvalidJSON
+	}
+	return body, nil
+}
+
+// GET /keys?pattern=*
+func restKeysArgs(query url.Values) []string {
+	pattern := query.Get("pattern")
+	if pattern == "" {
+		pattern = "*"
+	}
+	return []string{"keys", pattern}
+}
+
+// GET /keys/{key}/objects?cursor=0&limit=100&match=*&output=objects
+func restScanArgs(key string, query url.Values) ([]string, error) {
+	args := []string{"scan", key}
+	for _, name := range []string{"cursor", "limit", "match"} {
+		if value := query.Get(name); value != "" {
+			args = append(args, name, value)
+		}
+	}
+	return restAppendOutput(args, query.Get("output"), query.Get("precision"))
+}
+
+// GET /keys/{key}/objects/{id}?withfields=true&output=object
+func restGetArgs(key, id string, query url.Values) ([]string, error) {
+	args := []string{"get", key, id}
+	if withfields, _ := strconv.ParseBool(query.Get("withfields")); withfields {
+		args = append(args, "withfields")
+	}
+	switch output := query.Get("output"); output {
+	case "", "object":
+	case "point", "bounds":
+		args = append(args, output)
+	case "hash":
+		precision := query.Get("precision")
+		if precision == "" {
+			return nil, errInvalidArgument("precision")
+		}
+		args = append(args, output, precision)
+	default:
+		return nil, errInvalidArgument(output)
+	}
+	return args, nil
+}
+
+// PUT /keys/{key}/objects/{id}
+//
+// The body is a GeoJSON object, or a JSON object with one of "object",
+// "point" ([lat,lon] or [lat,lon,z]), "hash" or "string", and the optional
+// "fields", "ex", "nx" and "xx" members.
+func restSetArgs(key, id string, body []byte) ([]string, error) {
+	args := []string{"set", key, id}
+	json := gjson.ParseBytes(body)
+	if !json.IsObject() {
+		return nil, errRESTInvalidJSON
+	}
+	if json.Get("type").Exists() {
+		// a plain GeoJSON object
+		return append(args, "object", json.Raw), nil
+	}
+	var err error
+	json.Get("fields").ForEach(func(name, value gjson.Result) bool {
+		if value.Type != gjson.Number {
+			err = errInvalidArgument(value.String())
+			return false
+		}
+		args = append(args, "field", name.String(), value.Raw)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ex := json.Get("ex"); ex.Exists() {
+		args = append(args, "ex", ex.String())
+	}
+	if json.Get("nx").Bool() {
+		args = append(args, "nx")
+	}
+	if json.Get("xx").Bool() {
+		args = append(args, "xx")
+	}
+	switch {
+	case json.Get("object").Exists():
+		args = append(args, "object", json.Get("object").Raw)
+	case json.Get("point").IsArray():
+		args = append(args, "point")
+		for _, v := range json.Get("point").Array() {
+			args = append(args, v.String())
+		}
+	case json.Get("hash").Exists():
+		args = append(args, "hash", json.Get("hash").String())
+	case json.Get("string").Exists():
+		args = append(args, "string", json.Get("string").String())
+	default:
+		return nil, errors.New("missing object")
+	}
+	return args, nil
+}
+
+// POST /keys/{key}/search
+//
+// The body describes the search area with at most one of "nearby",
+// "within" or "intersects", and the options "cursor", "limit", "match",
+// "where", "nofields", "distance", "clip", "output" and "precision". A
+// search without an area scans the whole collection.
+func restSearchArgs(key string, body []byte) ([]string, error) {
+	json := gjson.ParseBytes(body)
+	if !json.IsObject() {
+		return nil, errRESTInvalidJSON
+	}
+	var cmd string
+	for _, name := range []string{"nearby", "within", "intersects"} {
+		if json.Get(name).Exists() {
+			if cmd != "" {
+				return nil, errors.New(
+					"only one of nearby, within or intersects is allowed")
+			}
+			cmd = name
+		}
+	}
+	if cmd == "" {
+		cmd = "scan"
+	}
+	args := []string{cmd, key}
+	for _, name := range []string{"cursor", "limit", "match"} {
+		if value := json.Get(name); value.Exists() {
+			args = append(args, name, value.String())
+		}
+	}
+	for _, where := range json.Get("where").Array() {
+		args = append(args, "where", where.Get("field").String(),
+			restBound(where.Get("min"), "-inf"),
+			restBound(where.Get("max"), "+inf"))
+	}
+	if json.Get("nofields").Bool() {
+		args = append(args, "nofields")
+	}
+	if json.Get("distance").Bool() {
+		args = append(args, "distance")
+	}
+	if json.Get("clip").Bool() {
+		args = append(args, "clip")
+	}
+	args, err := restAppendOutput(args, json.Get("output").String(),
+		json.Get("precision").String())
+	if err != nil {
+		return nil, err
+	}
+	switch cmd {
+	case "nearby":
+		nearby := json.Get("nearby")
+		args = append(args, "point", nearby.Get("lat").String(),
+			nearby.Get("lon").String())
+		if meters := nearby.Get("meters"); meters.Exists() {
+			args = append(args, meters.String())
+		}
+	case "within", "intersects":
+		area := json.Get(cmd)
+		switch {
+		case area.Get("type").Exists():
+			args = append(args, "object", area.Raw)
+		case area.Get("bounds").IsArray():
+			args = append(args, "bounds")
+			for _, v := range area.Get("bounds").Array() {
+				args = append(args, v.String())
+			}
+		case area.Get("circle").Exists():
+			circle := area.Get("circle")
+			args = append(args, "circle", circle.Get("lat").String(),
+				circle.Get("lon").String(), circle.Get("meters").String())
+		case area.Get("hash").Exists():
+			args = append(args, "hash", area.Get("hash").String())
+		case area.Get("get").Exists():
+			get := area.Get("get")
+			args = append(args, "get", get.Get("key").String(),
+				get.Get("id").String())
+		default:
+			return nil, errInvalidArgument(cmd)
+		}
+	}
+	return args, nil
+}
+
+func restBound(value gjson.Result, def string) string {
+	if !value.Exists() {
+		return def
+	}
+	return value.String()
+}
+
+func restAppendOutput(args []string, output, precision string) ([]string, error) {
+	switch output {
+	case "", "objects":
+	case "count", "ids", "points", "bounds":
+		args = append(args, output)
+	case "hashes":
+		if precision == "" {
+			return nil, errInvalidArgument("precision")
+		}
+		args = append(args, output, precision)
+	default:
+		return nil, errInvalidArgument(output)
+	}
+	return args, nil
+}
@@ -48,7 +48,7 @@ func (s *Server) cmdScan(msg *Message) (res resp.Value, err error) {
 	sw, err := s.newScanWriter(
 		wr, msg, args.key, args.output, args.precision, args.glob, false,
 		args.cursor, args.limit, args.wheres, args.whereins, args.whereevals,
-		args.nofields)
+		args.whereexprs, args.wherejsons, args.nofields)
 	if err != nil {
 		return NOMessage, err
 	}
@@ -57,14 +57,30 @@ func (s *Server) cmdScan(msg *Message) (res resp.Value, err error) {
 	}
 	sw.writeHead()
 	if sw.col != nil {
-		if sw.output == outputCount && len(sw.wheres) == 0 &&
-			len(sw.whereins) == 0 && sw.globEverything == true {
+		counted := sw.output == outputCount && len(sw.wheres) == 0 &&
+			len(sw.whereins) == 0 && len(sw.whereexprs) == 0 &&
+			len(sw.wherejsons) == 0 &&
+			sw.globEverything == true
+		ordered := !counted && args.orderBy != "" && sw.output != outputCount
+		indexed := !counted && !ordered && sw.indexScan(args.desc, msg.Deadline)
+		if !indexed {
+			if err := sw.plainCursor(); err != nil {
+				return NOMessage, err
+			}
+		}
+		if counted {
 			count := sw.col.Count() - int(args.cursor)
 			if count < 0 {
 				count = 0
 			}
 			sw.count = uint64(count)
-		} else {
+		} else if ordered {
+			sw.writeOrdered(args.orderBy, args.desc, nil, func(
+				iter func(id string, o geojson.Object, fields []float64) bool,
+			) {
+				sw.col.Scan(false, nil, msg.Deadline, iter)
+			})
+		} else if !indexed {
 			g := glob.Parse(sw.globPattern, args.desc)
 			if g.Limits[0] == "" && g.Limits[1] == "" {
 				sw.col.Scan(args.desc, sw,
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"math"
+	"sort"
 	"strconv"
 	"sync"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/tidwall/resp"
 	"github.com/tidwall/tile38/internal/clip"
 	"github.com/tidwall/tile38/internal/collection"
+	"github.com/tidwall/tile38/internal/expr"
 	"github.com/tidwall/tile38/internal/glob"
 )
 
@@ -42,10 +44,13 @@ type scanWriter struct {
 	wheres         []whereT
 	whereins       []whereinT
 	whereevals     []whereevalT
+	whereexprs     []*expr.Expr
+	wherejsons     []wherejsonT
 	numberIters    uint64
 	numberItems    uint64
 	nofields       bool
 	cursor         uint64
+	indexed        bool // answered through a field index, see indexCursor
 	limit          uint64
 	hitLimit       bool
 	once           bool
@@ -75,7 +80,8 @@ type ScanWriterParams struct {
 func (s *Server) newScanWriter(
 	wr *bytes.Buffer, msg *Message, key string, output outputT,
 	precision uint64, globPattern string, matchValues bool,
-	cursor, limit uint64, wheres []whereT, whereins []whereinT, whereevals []whereevalT, nofields bool,
+	cursor, limit uint64, wheres []whereT, whereins []whereinT, whereevals []whereevalT,
+	whereexprs []*expr.Expr, wherejsons []wherejsonT, nofields bool,
 ) (
 	*scanWriter, error,
 ) {
@@ -100,6 +106,8 @@ func (s *Server) newScanWriter(
 		wheres:      wheres,
 		whereins:    whereins,
 		whereevals:  whereevals,
+		whereexprs:  whereexprs,
+		wherejsons:  wherejsons,
 		output:      output,
 		nofields:    nofields,
 		precision:   precision,
@@ -170,6 +178,8 @@ func (sw *scanWriter) writeFoot() {
 	cursor := sw.numberIters
 	if !sw.hitLimit {
 		cursor = 0
+	} else if sw.indexed {
+		cursor |= indexCursor
 	}
 	switch sw.msg.OutputType {
 	case JSON:
@@ -194,7 +204,22 @@ func (sw *scanWriter) writeFoot() {
 	}
 }
 
-func (sw *scanWriter) fieldMatch(fields []float64, o geojson.Object) (fvals []float64, match bool) {
+// exprEnv resolves the identifiers of a WHERE expression for an object.
+type exprEnv struct {
+	sw     *scanWriter
+	id     string
+	o      geojson.Object
+	fields []float64
+}
+
+func (env *exprEnv) Ident(name string) expr.Value {
+	if name == "id" {
+		return expr.StringValue(env.id)
+	}
+	return expr.NumberValue(env.sw.fieldValue(name, env.o, env.fields))
+}
+
+func (sw *scanWriter) fieldMatch(id string, fields []float64, o geojson.Object) (fvals []float64, match bool) {
 	var z float64
 	var gotz bool
 	fvals = sw.fvals
@@ -300,10 +325,100 @@ func (sw *scanWriter) fieldMatch(fields []float64, o geojson.Object) (fvals []fl
 			}
 		}
 	}
+	if len(sw.whereexprs) > 0 {
+		env := &exprEnv{sw: sw, id: id, o: o, fields: fields}
+		for _, whereexpr := range sw.whereexprs {
+			if !whereexpr.Match(env) {
+				return
+			}
+		}
+	}
+	if len(sw.wherejsons) > 0 {
+		json := o.String()
+		for _, wherejson := range sw.wherejsons {
+			if !wherejson.match(json) {
+				return
+			}
+		}
+	}
 	match = true
 	return
 }
 
+// fieldValue returns the value of the named field for an object. Missing
+// fields are zero, the same as for WHERE.
+func (sw *scanWriter) fieldValue(field string, o geojson.Object, fields []float64) float64 {
+	if field == "z" {
+		if point, ok := o.(*geojson.Point); ok {
+			return point.Z()
+		}
+		return 0
+	}
+	if idx, ok := sw.fmap[field]; ok && idx < len(fields) {
+		return fields[idx]
+	}
+	return 0
+}
+
+type orderItem struct {
+	id     string
+	o      geojson.Object
+	fields []float64
+	value  float64
+}
+
+// writeOrdered collects every object passed to the scan iterator that
+// matches the writer's filters, sorts them by the ORDER BY field and writes
+// the page that starts at the cursor. Objects with equal values are ordered
+// by id so that paging with CURSOR is stable.
+func (sw *scanWriter) writeOrdered(
+	field string, desc bool, clipObj geojson.Object,
+	scan func(iter func(id string, o geojson.Object, fields []float64) bool),
+) {
+	var items []orderItem
+	scan(func(id string, o geojson.Object, fields []float64) bool {
+		ok, keepGoing, _ := sw.testObject(id, o, fields, false)
+		if ok {
+			items = append(items, orderItem{
+				id:     id,
+				o:      o,
+				fields: fields,
+				value:  sw.fieldValue(field, o, fields),
+			})
+		}
+		return keepGoing
+	})
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].value != items[j].value {
+			if desc {
+				return items[i].value > items[j].value
+			}
+			return items[i].value < items[j].value
+		}
+		if desc {
+			return items[i].id > items[j].id
+		}
+		return items[i].id < items[j].id
+	})
+	if sw.cursor >= uint64(len(items)) {
+		return
+	}
+	sw.Step(sw.cursor)
+	for _, item := range items[sw.cursor:] {
+		sw.Step(1)
+		if !sw.writeObject(ScanWriterParams{
+			id:          item.id,
+			o:           item.o,
+			fields:      item.fields,
+			clip:        clipObj,
+			noLock:      true,
+			skipTesting: true,
+		}) {
+			return
+		}
+	}
+}
+
 func (sw *scanWriter) globMatch(id string, o geojson.Object) (ok, keepGoing bool) {
 	if !sw.globEverything {
 		if sw.globSingle {
@@ -345,7 +460,7 @@ func (sw *scanWriter) testObject(id string, o geojson.Object, fields []float64,
 			return false, kg, fieldVals
 		}
 	}
-	nf, ok := sw.fieldMatch(fields, o)
+	nf, ok := sw.fieldMatch(id, fields, o)
 	return ok, true, nf
 }
 
@@ -457,14 +572,14 @@ func (sw *scanWriter) writeObject(opts ScanWriterParams) bool {
 				}
 				if z != 0 {
 					vals = append(vals, resp.ArrayValue([]resp.Value{
-						resp.FloatValue(point.Y),
-						resp.FloatValue(point.X),
-						resp.FloatValue(z),
+						respDouble(sw.msg, point.Y),
+						respDouble(sw.msg, point.X),
+						respDouble(sw.msg, z),
 					}))
 				} else {
 					vals = append(vals, resp.ArrayValue([]resp.Value{
-						resp.FloatValue(point.Y),
-						resp.FloatValue(point.X),
+						respDouble(sw.msg, point.Y),
+						respDouble(sw.msg, point.X),
 					}))
 				}
 			case outputHashes:
@@ -475,12 +590,12 @@ func (sw *scanWriter) writeObject(opts ScanWriterParams) bool {
 				bbox := opts.o.Rect()
 				vals = append(vals, resp.ArrayValue([]resp.Value{
 					resp.ArrayValue([]resp.Value{
-						resp.FloatValue(bbox.Min.Y),
-						resp.FloatValue(bbox.Min.X),
+						respDouble(sw.msg, bbox.Min.Y),
+						respDouble(sw.msg, bbox.Min.X),
 					}),
 					resp.ArrayValue([]resp.Value{
-						resp.FloatValue(bbox.Max.Y),
-						resp.FloatValue(bbox.Max.X),
+						respDouble(sw.msg, bbox.Max.Y),
+						respDouble(sw.msg, bbox.Max.X),
 					}),
 				}))
 			}
@@ -490,14 +605,14 @@ func (sw *scanWriter) writeObject(opts ScanWriterParams) bool {
 				if len(fvs) > 0 {
 					fvals := make([]resp.Value, 0, len(fvs)*2)
 					for i, fv := range fvs {
-						fvals = append(fvals, resp.StringValue(fv.field), resp.StringValue(strconv.FormatFloat(fv.value, 'f', -1, 64)))
+						fvals = append(fvals, resp.StringValue(fv.field), respDouble(sw.msg, fv.value))
 						i++
 					}
-					vals = append(vals, resp.ArrayValue(fvals))
+					vals = append(vals, respMap(sw.msg, fvals))
 				}
 			}
 			if opts.distance > 0 {
-				vals = append(vals, resp.FloatValue(opts.distance))
+				vals = append(vals, respDouble(sw.msg, opts.distance))
 			}
 
 			sw.values = append(sw.values, resp.ArrayValue(vals))
@@ -162,8 +162,12 @@ func (pl *lStatePool) New() *lua.LState {
 		"status_reply": statusReply,
 		"sha1hex":      sha1hex,
 		"distance_to":  distanceTo,
+
+		"register_function": registerFunction,
 	}
-	L.SetGlobal("tile38", L.SetFuncs(L.NewTable(), exports))
+	tile38 := L.SetFuncs(L.NewTable(), exports)
+	tile38.RawSetString("geo", pl.newLuaGeo(L))
+	L.SetGlobal("tile38", tile38)
 
 	// Load json
 	L.SetGlobal("json", L.Get(luajson.Loader(L)))
@@ -364,6 +368,15 @@ func Sha1Sum(s string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// validSha returns true when a string is a hex representation of a sha1 sum
+func validSha(s string) bool {
+	if len(s) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
 // Replace newlines with literal \n since RESP errors cannot have newlines
 func makeSafeErr(err error) error {
 	return errors.New(strings.Replace(err.Error(), "\n", `\n`, -1))
@@ -629,6 +642,8 @@ func (s *Server) commandInScript(msg *Message) (
 		res, err = s.cmdGet(msg)
 	case "jget":
 		res, err = s.cmdJget(msg)
+	case "history":
+		res, err = s.cmdHistory(msg)
 	case "jset":
 		res, d, err = s.cmdJset(msg)
 	case "jdel":
@@ -663,7 +678,8 @@ func (s *Server) luaTile38Call(evalcmd string, cmd string, args ...string) (resp
 		"follow", "readonly", "config", "output", "client",
 		"aofshrink",
 		"script load", "script exists", "script flush",
-		"eval", "evalsha", "evalro", "evalrosha", "evalna", "evalnasha":
+		"eval", "evalsha", "evalro", "evalrosha", "evalna", "evalnasha",
+		"function", "fcall", "fcall_ro":
 		return resp.NullValue(), errCmdNotSupported
 	}
 
@@ -674,6 +690,11 @@ func (s *Server) luaTile38Call(evalcmd string, cmd string, args ...string) (resp
 		return s.luaTile38AtomicRO(msg)
 	case "evalna", "evalnasha":
 		return s.luaTile38NonAtomic(msg)
+	case "hook":
+		// hook functions run outside of the server lock and may only read
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.luaTile38AtomicRO(msg)
 	}
 
 	return resp.NullValue(), errCmdNotSupported
@@ -697,7 +718,8 @@ func (s *Server) luaTile38AtomicRW(msg *Message) (resp.Value, error) {
 			return resp.NullValue(), errReadOnly
 		}
 	case "get", "keys", "scan", "nearby", "within", "intersects", "hooks", "search",
-		"ttl", "bounds", "server", "info", "type", "jget", "test":
+		"ttl", "bounds", "server", "info", "type", "jget", "test",
+		"history":
 		// read operations
 		if s.config.followHost() != "" && !s.fcuponce {
 			return resp.NullValue(), errCatchingUp
@@ -750,7 +772,8 @@ func (s *Server) luaTile38AtomicRO(msg *Message) (resp.Value, error) {
 		return resp.NullValue(), errReadOnly
 
 	case "get", "keys", "scan", "nearby", "within", "intersects", "hooks", "search",
-		"ttl", "bounds", "server", "info", "type", "jget", "test":
+		"ttl", "bounds", "server", "info", "type", "jget", "test",
+		"history":
 		// read operations
 		if s.config.followHost() != "" && !s.fcuponce {
 			return resp.NullValue(), errCatchingUp
@@ -801,7 +824,8 @@ func (s *Server) luaTile38NonAtomic(msg *Message) (resp.Value, error) {
 			return resp.NullValue(), errReadOnly
 		}
 	case "get", "keys", "scan", "nearby", "within", "intersects", "hooks", "search",
-		"ttl", "bounds", "server", "info", "type", "jget", "test":
+		"ttl", "bounds", "server", "info", "type", "jget", "test",
+		"history":
 		// read operations
 		s.mu.RLock()
 		defer s.mu.RUnlock()
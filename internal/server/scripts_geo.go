@@ -0,0 +1,327 @@
+package server
+
+import (
+	"math"
+
+	"github.com/mmcloughlin/geohash"
+	"github.com/tidwall/geojson"
+	"github.com/tidwall/geojson/geo"
+	"github.com/tidwall/geojson/geometry"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const earthRadius = 6371e3
+
+// newLuaGeo returns the tile38.geo table. Geometries are passed to and from
+// scripts as GeoJSON strings, and a Lua table is accepted wherever a GeoJSON
+// string is. Coordinates are in lat, lon order like the rest of Tile38.
+func (pl *lStatePool) newLuaGeo(L *lua.LState) *lua.LTable {
+	indexOptions := func() *geometry.IndexOptions {
+		return &geometry.IndexOptions{
+			Kind:      pl.s.geomParseOpts.IndexGeometryKind,
+			MinPoints: pl.s.geomParseOpts.IndexGeometry,
+		}
+	}
+	// checkObject parses the GeoJSON argument at position n.
+	checkObject := func(ls *lua.LState, n int) geojson.Object {
+		var s string
+		switch v := ls.Get(n); v.Type() {
+		case lua.LTString:
+			s = v.String()
+		case lua.LTTable:
+			s = ConvertToJSON(v)
+		default:
+			ls.TypeError(n, lua.LTString)
+		}
+		o, err := geojson.Parse(s, &pl.s.geomParseOpts)
+		if err != nil {
+			ls.ArgError(n, err.Error())
+		}
+		return o
+	}
+	checkNumber := func(ls *lua.LState, n int) float64 {
+		return float64(ls.CheckNumber(n))
+	}
+	parse := func(ls *lua.LState) int {
+		ls.Push(lua.LString(checkObject(ls, 1).JSON()))
+		return 1
+	}
+	contains := func(ls *lua.LState) int {
+		a, b := checkObject(ls, 1), checkObject(ls, 2)
+		ls.Push(lua.LBool(a.Contains(b)))
+		return 1
+	}
+	intersects := func(ls *lua.LState) int {
+		a, b := checkObject(ls, 1), checkObject(ls, 2)
+		ls.Push(lua.LBool(a.Intersects(b)))
+		return 1
+	}
+	within := func(ls *lua.LState) int {
+		a, b := checkObject(ls, 1), checkObject(ls, 2)
+		ls.Push(lua.LBool(a.Within(b)))
+		return 1
+	}
+	distance := func(ls *lua.LState) int {
+		ls.Push(lua.LNumber(geo.DistanceTo(checkNumber(ls, 1),
+			checkNumber(ls, 2), checkNumber(ls, 3), checkNumber(ls, 4))))
+		return 1
+	}
+	bearing := func(ls *lua.LState) int {
+		ls.Push(lua.LNumber(geo.BearingTo(checkNumber(ls, 1),
+			checkNumber(ls, 2), checkNumber(ls, 3), checkNumber(ls, 4))))
+		return 1
+	}
+	destination := func(ls *lua.LState) int {
+		lat, lon := geo.DestinationPoint(checkNumber(ls, 1),
+			checkNumber(ls, 2), checkNumber(ls, 3), checkNumber(ls, 4))
+		ls.Push(lua.LNumber(lat))
+		ls.Push(lua.LNumber(lon))
+		return 2
+	}
+	area := func(ls *lua.LState) int {
+		ls.Push(lua.LNumber(objectArea(checkObject(ls, 1))))
+		return 1
+	}
+	bbox := func(ls *lua.LState) int {
+		r := checkObject(ls, 1).Rect()
+		ls.Push(lua.LNumber(r.Min.Y))
+		ls.Push(lua.LNumber(r.Min.X))
+		ls.Push(lua.LNumber(r.Max.Y))
+		ls.Push(lua.LNumber(r.Max.X))
+		return 4
+	}
+	center := func(ls *lua.LState) int {
+		c := checkObject(ls, 1).Center()
+		ls.Push(lua.LNumber(c.Y))
+		ls.Push(lua.LNumber(c.X))
+		return 2
+	}
+	buffer := func(ls *lua.LState) int {
+		o := checkObject(ls, 1)
+		meters := checkNumber(ls, 2)
+		if meters < 0 || math.IsNaN(meters) || math.IsInf(meters, 0) {
+			ls.ArgError(2, "invalid distance")
+		}
+		ls.Push(lua.LString(bufferObject(o, meters, indexOptions()).JSON()))
+		return 1
+	}
+	simplify := func(ls *lua.LState) int {
+		o := checkObject(ls, 1)
+		meters := checkNumber(ls, 2)
+		if meters < 0 || math.IsNaN(meters) || math.IsInf(meters, 0) {
+			ls.ArgError(2, "invalid distance")
+		}
+		ls.Push(lua.LString(simplifyObject(o, meters, indexOptions()).JSON()))
+		return 1
+	}
+	geohashEncode := func(ls *lua.LState) int {
+		lat, lon := checkNumber(ls, 1), checkNumber(ls, 2)
+		precision := ls.OptInt(3, 12)
+		if precision < 1 || precision > 12 {
+			ls.ArgError(3, "precision must be between 1 and 12")
+		}
+		ls.Push(lua.LString(geohash.EncodeWithPrecision(lat, lon,
+			uint(precision))))
+		return 1
+	}
+	geohashDecode := func(ls *lua.LState) int {
+		hash := ls.CheckString(1)
+		if err := geohash.Validate(hash); err != nil {
+			ls.ArgError(1, err.Error())
+		}
+		lat, lon := geohash.DecodeCenter(hash)
+		ls.Push(lua.LNumber(lat))
+		ls.Push(lua.LNumber(lon))
+		return 2
+	}
+	var exports = map[string]lua.LGFunction{
+		"parse":          parse,
+		"contains":       contains,
+		"intersects":     intersects,
+		"within":         within,
+		"distance":       distance,
+		"bearing":        bearing,
+		"destination":    destination,
+		"area":           area,
+		"bbox":           bbox,
+		"center":         center,
+		"buffer":         buffer,
+		"simplify":       simplify,
+		"geohash_encode": geohashEncode,
+		"geohash_decode": geohashDecode,
+	}
+	return L.SetFuncs(L.NewTable(), exports)
+}
+
+// objectArea returns the area of an object in square meters. Points and
+// lines have no area.
+func objectArea(o geojson.Object) float64 {
+	switch o := o.(type) {
+	case *geojson.Feature:
+		return objectArea(o.Base())
+	case *geojson.Circle:
+		return objectArea(o.Primative())
+	case *geojson.Rect:
+		r := o.Base()
+		return math.Abs(sphericalRingArea([]geometry.Point{
+			r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y},
+		}))
+	case *geojson.Polygon:
+		poly := o.Base()
+		area := math.Abs(sphericalRingArea(seriesPoints(poly.Exterior)))
+		for _, hole := range poly.Holes {
+			area -= math.Abs(sphericalRingArea(seriesPoints(hole)))
+		}
+		return area
+	case geojson.Collection:
+		var area float64
+		for _, child := range o.Children() {
+			area += objectArea(child)
+		}
+		return area
+	}
+	return 0
+}
+
+// sphericalRingArea returns the signed area of a ring on the sphere in
+// square meters.
+func sphericalRingArea(ring []geometry.Point) float64 {
+	var area float64
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += (b.X - a.X) * math.Pi / 180 *
+			(2 + math.Sin(a.Y*math.Pi/180) + math.Sin(b.Y*math.Pi/180))
+	}
+	return area * earthRadius * earthRadius / 2
+}
+
+// simplifyObject reduces the number of points in the lines and polygons of
+// an object using the Douglas-Peucker algorithm. Points that are closer than
+// meters to the simplified outline are removed. Rings keep at least enough
+// points to stay rings. Other objects are returned as is.
+func simplifyObject(o geojson.Object, meters float64,
+	opts *geometry.IndexOptions,
+) geojson.Object {
+	switch o := o.(type) {
+	case *geojson.Feature:
+		return simplifyObject(o.Base(), meters, opts)
+	case *geojson.LineString:
+		return geojson.NewLineString(simplifyLine(o.Base(), meters, opts))
+	case *geojson.Polygon:
+		return geojson.NewPolygon(simplifyPoly(o.Base(), meters, opts))
+	case *geojson.MultiLineString:
+		var lines []*geometry.Line
+		for _, child := range o.Children() {
+			lines = append(lines,
+				simplifyLine(child.(*geojson.LineString).Base(), meters, opts))
+		}
+		return geojson.NewMultiLineString(lines)
+	case *geojson.MultiPolygon:
+		var polys []*geometry.Poly
+		for _, child := range o.Children() {
+			polys = append(polys,
+				simplifyPoly(child.(*geojson.Polygon).Base(), meters, opts))
+		}
+		return geojson.NewMultiPolygon(polys)
+	}
+	return o
+}
+
+func simplifyLine(line *geometry.Line, meters float64,
+	opts *geometry.IndexOptions,
+) *geometry.Line {
+	points := make([]geometry.Point, line.NumPoints())
+	for i := range points {
+		points[i] = line.PointAt(i)
+	}
+	return geometry.NewLine(douglasPeucker(points, meters, 2), opts)
+}
+
+func simplifyPoly(poly *geometry.Poly, meters float64,
+	opts *geometry.IndexOptions,
+) *geometry.Poly {
+	simplifyRing := func(ring geometry.Ring) []geometry.Point {
+		points := closeRing(seriesPoints(ring))
+		return douglasPeucker(points, meters, 4)
+	}
+	var holes [][]geometry.Point
+	for _, hole := range poly.Holes {
+		holes = append(holes, simplifyRing(hole))
+	}
+	return geometry.NewPoly(simplifyRing(poly.Exterior), holes, opts)
+}
+
+// douglasPeucker simplifies a series of points, keeping at least minPoints points
+// when the series has that many.
+func douglasPeucker(points []geometry.Point, meters float64, minPoints int,
+) []geometry.Point {
+	if len(points) <= minPoints {
+		return points
+	}
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	var simplify func(i, j int)
+	simplify = func(i, j int) {
+		var idx int
+		var dist float64
+		for k := i + 1; k < j; k++ {
+			d := segmentDistance(points[k], points[i], points[j])
+			if d > dist {
+				idx, dist = k, d
+			}
+		}
+		if idx != 0 && dist > meters {
+			keep[idx] = true
+			simplify(i, idx)
+			simplify(idx, j)
+		}
+	}
+	simplify(0, len(points)-1)
+	var n int
+	for _, k := range keep {
+		if k {
+			n++
+		}
+	}
+	// keep the points farthest from the outline until there are enough
+	for n < minPoints {
+		var idx int
+		var dist float64
+		prev := 0
+		for k := 1; k < len(points); k++ {
+			if keep[k] {
+				for m := prev + 1; m < k; m++ {
+					d := segmentDistance(points[m], points[prev], points[k])
+					if !keep[m] && d >= dist {
+						idx, dist = m, d
+					}
+				}
+				prev = k
+			}
+		}
+		keep[idx] = true
+		n++
+	}
+	simplified := make([]geometry.Point, 0, n)
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns the distance in meters from p to the segment ab,
+// using an equirectangular projection around p.
+func segmentDistance(p, a, b geometry.Point) float64 {
+	kx := math.Cos(p.Y*math.Pi/180) * math.Pi / 180 * earthRadius
+	ky := math.Pi / 180 * earthRadius
+	ax, ay := (a.X-p.X)*kx, (a.Y-p.Y)*ky
+	bx, by := (b.X-p.X)*kx, (b.Y-p.Y)*ky
+	dx, dy := bx-ax, by-ay
+	var t float64
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
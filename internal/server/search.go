@@ -326,6 +326,11 @@ func (server *Server) cmdSearchArgs(
 			s.roam.scan = scan
 		}
 	}
+	if !s.roam.on && cmd != "nearby" {
+		if vs, s.obj, err = server.parseBuffer(vs, s.obj); err != nil {
+			return
+		}
+	}
 	if len(vs) != 0 {
 		err = errInvalidNumberOfArguments
 		return
@@ -361,7 +366,8 @@ func (server *Server) cmdNearby(msg *Message) (res resp.Value, err error) {
 	}
 	sw, err := server.newScanWriter(
 		wr, msg, s.key, s.output, s.precision, s.glob, false,
-		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.nofields)
+		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.whereexprs,
+		s.wherejsons, s.nofields)
 	if err != nil {
 		return NOMessage, err
 	}
@@ -385,7 +391,12 @@ func (server *Server) cmdNearby(msg *Message) (res resp.Value, err error) {
 				skipTesting:     true,
 			})
 		}
-		server.nearestNeighbors(&s, sw, msg.Deadline, s.obj.(*geojson.Circle), iter)
+		if !server.indexSearch(&s, sw, msg.Deadline) {
+			if err := sw.plainCursor(); err != nil {
+				return NOMessage, err
+			}
+			server.nearestNeighbors(&s, sw, msg.Deadline, s.obj.(*geojson.Circle), iter)
+		}
 	}
 	sw.writeFoot()
 	if msg.OutputType == JSON {
@@ -437,6 +448,68 @@ func (server *Server) nearestNeighbors(
 	}
 }
 
+// indexSearch attempts to answer a NEARBY, WITHIN or INTERSECTS through the
+// index of one of its WHERE fields. The candidates of the index are tested
+// against the area, and the NEARBY candidates are ordered by distance.
+// Returns false, without writing anything, when the index can't be used.
+func (server *Server) indexSearch(
+	s *liveFenceSwitches, sw *scanWriter, dl *deadline.Deadline,
+) bool {
+	items, ok := sw.indexCandidates(dl)
+	if !ok {
+		return false
+	}
+	sortCandidates(items, false)
+	var clip geojson.Object
+	switch s.cmd {
+	case "nearby":
+		target := s.obj.(*geojson.Circle)
+		maxDist := target.Haversine()
+		var near []orderItem
+		for _, item := range items {
+			item.value = target.HaversineTo(item.o.Center())
+			if maxDist <= 0 || item.value <= maxDist {
+				near = append(near, item)
+			}
+		}
+		items = near
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].value < items[j].value
+		})
+	case "intersects":
+		if s.clip {
+			clip = s.obj
+		}
+	}
+	sw.writeCandidates(items, func(item orderItem) (ScanWriterParams, bool) {
+		if server.hasExpired(s.key, item.id) {
+			return ScanWriterParams{}, false
+		}
+		switch s.cmd {
+		case "within":
+			if !item.o.Within(s.obj) {
+				return ScanWriterParams{}, false
+			}
+		case "intersects":
+			if !item.o.Intersects(s.obj) {
+				return ScanWriterParams{}, false
+			}
+		}
+		params := ScanWriterParams{
+			id:     item.id,
+			o:      item.o,
+			fields: item.fields,
+			clip:   clip,
+			noLock: true,
+		}
+		if s.cmd == "nearby" && s.distance {
+			params.distance = geo.DistanceFromHaversine(item.value)
+		}
+		return params, true
+	})
+	return true
+}
+
 func (server *Server) cmdWithin(msg *Message) (res resp.Value, err error) {
 	return server.cmdWithinOrIntersects("within", msg)
 }
@@ -470,7 +543,8 @@ func (server *Server) cmdWithinOrIntersects(cmd string, msg *Message) (res resp.
 	}
 	sw, err := server.newScanWriter(
 		wr, msg, s.key, s.output, s.precision, s.glob, false,
-		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.nofields)
+		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.whereexprs,
+		s.wherejsons, s.nofields)
 	if err != nil {
 		return NOMessage, err
 	}
@@ -478,7 +552,37 @@ func (server *Server) cmdWithinOrIntersects(cmd string, msg *Message) (res resp.
 		wr.WriteString(`{"ok":true`)
 	}
 	sw.writeHead()
-	if sw.col != nil {
+	ordered := sw.col != nil && s.orderBy != "" && sw.output != outputCount
+	indexed := sw.col != nil && !ordered && s.sparse == 0 &&
+		server.indexSearch(&s, sw, msg.Deadline)
+	if !indexed {
+		if err := sw.plainCursor(); err != nil {
+			return NOMessage, err
+		}
+	}
+	if ordered {
+		var clipObj geojson.Object
+		if s.clip && cmd == "intersects" {
+			clipObj = s.obj
+		}
+		sw.writeOrdered(s.orderBy, s.desc, clipObj, func(
+			iter func(id string, o geojson.Object, fields []float64) bool,
+		) {
+			filter := func(id string, o geojson.Object, fields []float64) bool {
+				if server.hasExpired(s.key, id) {
+					return true
+				}
+				return iter(id, o, fields)
+			}
+			if cmd == "within" {
+				sw.col.Within(s.obj, 0, nil, msg.Deadline, filter)
+			} else {
+				sw.col.Intersects(s.obj, 0, nil, msg.Deadline, filter)
+			}
+		})
+	} else if indexed {
+		// answered through a field index
+	} else if sw.col != nil {
 		if cmd == "within" {
 			sw.col.Within(s.obj, s.sparse, sw, msg.Deadline, func(
 				id string, o geojson.Object, fields []float64,
@@ -560,16 +664,22 @@ func (server *Server) cmdSearch(msg *Message) (res resp.Value, err error) {
 	}
 	sw, err := server.newScanWriter(
 		wr, msg, s.key, s.output, s.precision, s.glob, true,
-		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.nofields)
+		s.cursor, s.limit, s.wheres, s.whereins, s.whereevals, s.whereexprs,
+		s.wherejsons, s.nofields)
 	if err != nil {
 		return NOMessage, err
 	}
+	if err := sw.plainCursor(); err != nil {
+		return NOMessage, err
+	}
 	if msg.OutputType == JSON {
 		wr.WriteString(`{"ok":true`)
 	}
 	sw.writeHead()
 	if sw.col != nil {
-		if sw.output == outputCount && len(sw.wheres) == 0 && sw.globEverything == true {
+		if sw.output == outputCount && len(sw.wheres) == 0 &&
+			len(sw.whereexprs) == 0 && len(sw.wherejsons) == 0 &&
+			sw.globEverything == true {
 			count := sw.col.Count() - int(s.cursor)
 			if count < 0 {
 				count = 0
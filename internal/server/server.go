@@ -85,18 +85,40 @@ type Server struct {
 	http500Errors bool
 
 	// atomics
-	followc            aint // counter increases when follow property changes
-	statsTotalConns    aint // counter for total connections
-	statsTotalCommands aint // counter for total commands
-	statsTotalMsgsSent aint // counter for total sent webhook messages
-	statsExpired       aint // item expiration counter
-	lastShrinkDuration aint
-	stopServer         abool
-	outOfMemory        abool
+	followc              aint // counter increases when follow property changes
+	statsTotalConns      aint // counter for total connections
+	statsTotalCommands   aint // counter for total commands
+	statsTotalMsgsSent   aint // counter for total sent webhook messages
+	statsExpired         aint // item expiration counter
+	statsEvicted         aint // item eviction counter
+	statsLazyfreePending aint // objects waiting to be freed in the background
+	statsLazyfreed       aint // objects freed in the background
+	lastShrinkDuration   aint
+	stopServer           abool
+	outOfMemory          abool
+
+	statsRejectedCommands aint // commands over a commands per second limit
+	statsRejectedBytes    aint // commands over a bytes per second limit
+	statsRejectedHeavy    aint // searches over a heavy queries limit
+	heavyQueries          aint // running searches
+
+	statsImportsRunning  aint // IMPORT commands that are running
+	statsImportedLines   aint // lines read by IMPORT
+	statsImportedObjects aint // objects written by IMPORT
 
 	connsmu sync.RWMutex
 	conns   map[int]*Client
 
+	evictNext string // where the next allkeys-lru sample starts
+
+	keyConfigs map[string]*keyConfig                  // KEYCONFIG of keys
+	histories  map[string]map[string][]geojson.Object // history of keys
+
+	trackmu         sync.Mutex                            // guards tracked and trackBcast
+	tracked         map[string]map[string]map[int]*Client // key -> id -> clients
+	trackBcast      map[int][]string                      // BCAST client id -> prefixes
+	trackingClients aint                                  // clients with tracking on
+
 	mu       sync.RWMutex
 	aof      *os.File     // active aof file
 	aofdirty int32        // mark the aofbuf as having data
@@ -104,6 +126,7 @@ type Server struct {
 	aofsz    int          // active size of the aof file
 	qdb      *buntdb.DB   // hook queue log
 	qidx     uint64       // hook queue log last idx
+	chanlog  *chanLog     // recent channel messages
 	cols     *btree.BTree // data collections
 	expires  *rhh.Map     // map[string]map[string]time.Time
 
@@ -122,6 +145,7 @@ type Server struct {
 	hooksOut   map[string]*Hook // hooks with "outside" detection
 	aofconnM   map[net.Conn]bool
 	luascripts *lScriptMap
+	luafuncs   *lFunctionMap
 	luapool    *lStatePool
 
 	pubsub *pubsub
@@ -143,23 +167,28 @@ func Serve(host string, port int, dir string, http bool) error {
 
 	// Initialize the server
 	server := &Server{
-		host:     host,
-		port:     port,
-		dir:      dir,
-		follows:  make(map[*bytes.Buffer]bool),
-		fcond:    sync.NewCond(&sync.Mutex{}),
-		lives:    make(map[*liveBuffer]bool),
-		lcond:    sync.NewCond(&sync.Mutex{}),
-		hooks:    make(map[string]*Hook),
-		hooksOut: make(map[string]*Hook),
-		aofconnM: make(map[net.Conn]bool),
-		expires:  rhh.New(0),
-		started:  time.Now(),
-		conns:    make(map[int]*Client),
-		http:     http,
-		pubsub:   newPubsub(),
-		monconns: make(map[net.Conn]bool),
-		cols:     btree.New(byCollectionKey),
+		host:       host,
+		port:       port,
+		dir:        dir,
+		follows:    make(map[*bytes.Buffer]bool),
+		fcond:      sync.NewCond(&sync.Mutex{}),
+		lives:      make(map[*liveBuffer]bool),
+		lcond:      sync.NewCond(&sync.Mutex{}),
+		hooks:      make(map[string]*Hook),
+		hooksOut:   make(map[string]*Hook),
+		aofconnM:   make(map[net.Conn]bool),
+		expires:    rhh.New(0),
+		started:    time.Now(),
+		conns:      make(map[int]*Client),
+		tracked:    make(map[string]map[string]map[int]*Client),
+		trackBcast: make(map[int][]string),
+		keyConfigs: make(map[string]*keyConfig),
+		histories:  make(map[string]map[string][]geojson.Object),
+		chanlog:    newChanLog(),
+		http:       http,
+		pubsub:     newPubsub(),
+		monconns:   make(map[net.Conn]bool),
+		cols:       btree.New(byCollectionKey),
 	}
 
 	server.hookex.Expired = func(item expire.Item) {
@@ -170,6 +199,7 @@ func Serve(host string, port int, dir string, http bool) error {
 	}
 	server.epc = endpoint.NewManager(server)
 	server.luascripts = server.newScriptMap()
+	server.luafuncs = newFunctionMap()
 	server.luapool = server.newPool()
 	defer server.luapool.Shutdown()
 
@@ -190,6 +220,7 @@ func Serve(host string, port int, dir string, http bool) error {
 	// T38IDXGEOMKIND -- None, RTree, QuadTree
 	// T38IDXGEOM -- Min number of points in a geometry for indexing.
 	// T38IDXMULTI -- Min number of object in a Multi/Collection for indexing.
+	// The index kind of a single key can be changed with KEYCONFIG.
 	server.geomParseOpts = *geojson.DefaultParseOptions
 	server.geomIndexOpts = *geometry.DefaultIndexOptions
 	n, err := strconv.ParseUint(os.Getenv("T38IDXGEOM"), 10, 32)
@@ -288,6 +319,20 @@ func Serve(host string, port int, dir string, http bool) error {
 	go server.watchAutoGC()
 	go server.backgroundExpiring()
 	go server.backgroundSyncAOF()
+	if core.RESTPort != 0 {
+		go func() {
+			if err := server.restServe(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	if core.GRPCPort != 0 {
+		go func() {
+			if err := server.grpcServe(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
 	defer func() {
 		// Stop background routines
 		server.followc.add(1) // this will force any follow communication to die
@@ -325,6 +370,25 @@ func (server *Server) netServe() error {
 	defer ln.Close()
 	log.Infof("Ready to accept connections at %s", ln.Addr())
 	var clientID int64
+	if core.UnixSocket != "" {
+		uln, err := listenUnix(core.UnixSocket, core.UnixSocketPerm)
+		if err != nil {
+			return err
+		}
+		defer uln.Close()
+		log.Infof("Ready to accept connections at %s", uln.Addr())
+		go func() {
+			if err := server.acceptConns(uln, &clientID); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	return server.acceptConns(ln, &clientID)
+}
+
+// acceptConns serves the connections of a TCP or Unix domain socket
+// listener.
+func (server *Server) acceptConns(ln net.Listener, clientID *int64) error {
 	for {
 		conn, err := ln.Accept()
 		if err != nil {
@@ -335,9 +399,15 @@ func (server *Server) netServe() error {
 			// open connection
 			// create the client
 			client := new(Client)
-			client.id = int(atomic.AddInt64(&clientID, 1))
+			client.id = int(atomic.AddInt64(clientID, 1))
 			client.opened = time.Now()
 			client.remoteAddr = conn.RemoteAddr().String()
+			client.netConn = conn
+			_, unix := conn.(*net.UnixConn)
+			if unix {
+				// unix socket peers have no address
+				client.remoteAddr = ln.Addr().String() + ":0"
+			}
 
 			// add client to server map
 			server.connsmu.Lock()
@@ -362,6 +432,7 @@ func (server *Server) netServe() error {
 				server.connsmu.Lock()
 				delete(server.conns, client.id)
 				server.connsmu.Unlock()
+				server.stopTracking(client)
 				log.Debugf("Closed connection: %s", client.remoteAddr)
 				conn.Close()
 			}()
@@ -370,7 +441,7 @@ func (server *Server) netServe() error {
 			var lastOutputType Type
 
 			// check if the connection is protected
-			if !strings.HasPrefix(client.remoteAddr, "127.0.0.1:") &&
+			if !unix && !strings.HasPrefix(client.remoteAddr, "127.0.0.1:") &&
 				!strings.HasPrefix(client.remoteAddr, "[::1]:") {
 				if server.isProtected() {
 					// This is a protected server. Only loopback is allowed.
@@ -381,11 +452,17 @@ func (server *Server) netServe() error {
 			packet := make([]byte, 0xFFFF)
 			for {
 				var close bool
-				n, err := conn.Read(packet)
-				if err != nil {
-					return
+				var in []byte
+				if pending := client.takePending(); len(pending) > 0 {
+					// read while a search was running
+					in = pending
+				} else {
+					n, err := conn.Read(packet)
+					if err != nil {
+						return
+					}
+					in = packet[:n]
 				}
-				in := packet[:n]
 
 				// read the payload packet from the client input stream.
 				packet := client.in.Begin(in)
@@ -401,7 +478,7 @@ func (server *Server) netServe() error {
 					// Just closing connection if we have deprecated HTTP or WS connection,
 					// And --http-transport = false
 					if !server.http && (msg.ConnType == WebSocket ||
-						msg.ConnType == HTTP) {
+						msg.ConnType == HTTP || msg.ConnType == SSE) {
 						close = true // close connection
 						break
 					}
@@ -440,6 +517,10 @@ func (server *Server) netServe() error {
 								}
 								client.in = InputStream{}
 								client.pr.rd = rwc
+								if pending := client.takePending(); len(pending) > 0 {
+									client.pr.rd = io.MultiReader(
+										bytes.NewReader(pending), rwc)
+								}
 								client.pr.wr = rwc
 								log.Debugf("Detached connection: %s", client.remoteAddr)
 
@@ -470,7 +551,8 @@ func (server *Server) netServe() error {
 						client.Write([]byte("HTTP/1.1 500 Bad Request\r\nConnection: close\r\n\r\n"))
 						break
 					}
-					if msg.ConnType == HTTP || msg.ConnType == WebSocket {
+					if msg.ConnType == HTTP || msg.ConnType == WebSocket ||
+						msg.ConnType == SSE {
 						close = true // close connection
 						break
 					}
@@ -492,7 +574,9 @@ func (server *Server) netServe() error {
 						}()
 						atomic.StoreInt32(&server.aofdirty, 0)
 					}
+					client.wmu.Lock()
 					conn.Write(client.out)
+					client.wmu.Unlock()
 					client.out = nil
 				}
 				if close {
@@ -606,7 +690,25 @@ func (server *Server) watchOutOfMemory() {
 				runtime.GC()
 			}
 			runtime.ReadMemStats(&mem)
-			server.outOfMemory.set(int(mem.HeapAlloc) > server.config.maxMemory())
+			for {
+				// evict objects until the heap is back under maxmemory, or
+				// until there is nothing left to evict. Like Redis replicas,
+				// followers never evict, they only apply the deletes of the
+				// leader, otherwise their data would drift from the leader.
+				maxMemory := server.config.maxMemory()
+				policy := server.config.maxMemoryPolicy()
+				if maxMemory == 0 || int(mem.HeapAlloc) <= maxMemory ||
+					policy == evictNone || server.config.followHost() != "" {
+					break
+				}
+				if server.evict(policy, int(mem.HeapAlloc)-maxMemory) == 0 {
+					break
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&mem)
+			}
+			maxMemory := server.config.maxMemory()
+			server.outOfMemory.set(maxMemory > 0 && int(mem.HeapAlloc) > maxMemory)
 		}()
 	}
 }
@@ -716,7 +818,7 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 			resStr = res.String()
 		case RESP:
 			var resBytes []byte
-			resBytes, err = res.MarshalRESP()
+			resBytes, err = marshalRESP(res, msg)
 			resStr = string(resBytes)
 		}
 		return resStr, err
@@ -729,7 +831,11 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 			return err
 		case WebSocket:
 			return WriteWebSocketMessage(client, []byte(res))
-		case HTTP:
+		case REST:
+			// the REST handler writes the status and headers
+			_, err := io.WriteString(client, res)
+			return err
+		case HTTP, SSE:
 			status := "200 OK"
 			if server.http500Errors && !gjson.Get(res, "ok").Bool() {
 				status = "500 Internal Server Error"
@@ -801,6 +907,20 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		}
 	}
 
+	msg.useRESP3(client.resp3 && msg.ConnType == RESP)
+	if msg.Command() == "hello" {
+		// HELLO authenticates by itself
+		res, err := server.cmdHello(msg, client)
+		if err != nil {
+			return writeErr(err.Error())
+		}
+		resStr, err := serializeOutput(res)
+		if err != nil {
+			return err
+		}
+		return writeOutput(resStr)
+	}
+
 	var write bool
 
 	if (!client.authd || msg.Command() == "auth") && msg.Command() != "output" {
@@ -822,7 +942,8 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 				return writeErr("invalid password")
 			}
 			client.authd = true
-			if msg.ConnType != HTTP {
+			if msg.ConnType != HTTP && msg.ConnType != REST &&
+				msg.ConnType != SSE {
 				resStr, _ := serializeOutput(OKMessage(msg, start))
 				return writeOutput(resStr)
 			}
@@ -831,6 +952,37 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		}
 	}
 
+	// rate limits
+	done, err := server.checkLimits(client, msg)
+	if err != nil {
+		switch msg.OutputType {
+		case JSON:
+			return writeErr(err.Error())
+		case RESP:
+			return writeOutput("-LIMIT " + err.Error() + "\r\n")
+		}
+		return nil
+	}
+	if done != nil {
+		defer done()
+	}
+
+	if client.multi {
+		switch msg.Command() {
+		case "multi", "exec", "discard", "watch", "unwatch":
+		default:
+			res, err := server.queueMulti(msg, client)
+			if err != nil {
+				return writeErr(err.Error())
+			}
+			resStr, err := serializeOutput(res)
+			if err != nil {
+				return err
+			}
+			return writeOutput(resStr)
+		}
+	}
+
 	// choose the locking strategy
 	switch msg.Command() {
 	default:
@@ -839,7 +991,8 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 	case "set", "del", "drop", "fset", "flushdb",
 		"setchan", "pdelchan", "delchan",
 		"sethook", "pdelhook", "delhook",
-		"expire", "persist", "jset", "pdel", "rename", "renamenx":
+		"expire", "persist", "jset", "pdel", "rename", "renamenx",
+		"createindex", "dropindex":
 		// write operations
 		write = true
 		server.mu.Lock()
@@ -850,7 +1003,33 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		if server.config.readOnly() {
 			return writeErr("read only")
 		}
-	case "eval", "evalsha":
+	case "keyconfig":
+		// SET is written to the aof
+		write = isKeyConfigWrite(msg)
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		if write {
+			if server.config.followHost() != "" {
+				return writeErr("not the leader")
+			}
+			if server.config.readOnly() {
+				return writeErr("read only")
+			}
+		}
+	case "function":
+		// LOAD, DELETE, FLUSH and RESTORE are written to the aof
+		write = isFunctionWrite(msg)
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		if write {
+			if server.config.followHost() != "" {
+				return writeErr("not the leader")
+			}
+			if server.config.readOnly() {
+				return writeErr("read only")
+			}
+		}
+	case "eval", "evalsha", "fcall":
 		// write operations (potentially) but no AOF for the script command itself
 		server.mu.Lock()
 		defer server.mu.Unlock()
@@ -862,7 +1041,7 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		}
 	case "get", "keys", "scan", "nearby", "within", "intersects", "hooks",
 		"chans", "search", "ttl", "bounds", "server", "info", "type", "jget",
-		"evalro", "evalrosha":
+		"evalro", "evalrosha", "fcall_ro", "history":
 		// read operations
 
 		server.mu.RLock()
@@ -880,6 +1059,17 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 	case "echo":
 	case "massinsert":
 		// dev operation
+	case "import", "export":
+		// locks are held only while reading or writing the collection
+	case "exec":
+		// the queued commands run under a single write lock
+		server.mu.Lock()
+		defer server.mu.Unlock()
+	case "watch":
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+	case "multi", "discard", "unwatch":
+		// local connection operations
 	case "sleep":
 		// dev operation
 		server.mu.RLock()
@@ -892,8 +1082,8 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		server.mu.RLock()
 		defer server.mu.RUnlock()
 	case "client":
-		server.mu.Lock()
-		defer server.mu.Unlock()
+		// client operations use the connsmu and client locks, which lets
+		// CLIENT LIST show the commands that are running
 	case "evalna", "evalnasha":
 		// No locking for scripts, otherwise writes cannot happen within scripts
 	case "subscribe", "psubscribe", "publish":
@@ -901,6 +1091,21 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 	case "montior":
 		// No locking for monitor
 	}
+	if isSearchCommand(msg.Command()) {
+		// searches run with the default timeout and are canceled when the
+		// client disconnects
+		if msg.Deadline == nil {
+			msg.Deadline = server.defaultDeadline(client)
+		}
+		if msg.Deadline != nil && client.netConn != nil {
+			stop := client.watchDisconnect(msg.Deadline)
+			defer stop()
+		}
+	}
+	client.mu.Lock()
+	client.cmd = msg.Command()
+	client.cmdStart = time.Now()
+	client.mu.Unlock()
 	res, d, err := func() (res resp.Value, d commandDetails, err error) {
 		if msg.Deadline != nil {
 			if write {
@@ -923,6 +1128,9 @@ func (server *Server) handleInputCommand(client *Client, msg *Message) error {
 		}
 		return server.command(msg, client)
 	}()
+	client.mu.Lock()
+	client.cmd = ""
+	client.mu.Unlock()
 	if res.Type() == resp.Error {
 		return writeErr(res.String())
 	}
@@ -998,6 +1206,12 @@ func (server *Server) command(msg *Message, client *Client) (
 		res, d, err = server.cmdRename(msg, false)
 	case "renamenx":
 		res, d, err = server.cmdRename(msg, true)
+	case "keyconfig":
+		res, d, err = server.cmdKeyConfig(msg)
+	case "createindex":
+		res, d, err = server.cmdCreateIndex(msg)
+	case "dropindex":
+		res, d, err = server.cmdDropIndex(msg)
 	case "sethook":
 		res, d, err = server.cmdSetHook(msg, false)
 	case "delhook":
@@ -1020,6 +1234,20 @@ func (server *Server) command(msg *Message, client *Client) (
 		res, d, err = server.cmdPersist(msg)
 	case "ttl":
 		res, err = server.cmdTTL(msg)
+	case "multi":
+		res, err = server.cmdMulti(msg, client)
+	case "exec":
+		res, err = server.cmdExec(msg, client)
+	case "discard":
+		res, err = server.cmdDiscard(msg, client)
+	case "watch":
+		res, err = server.cmdWatch(msg, client)
+	case "unwatch":
+		res, err = server.cmdUnwatch(msg, client)
+	case "import":
+		res, err = server.cmdImport(msg)
+	case "export":
+		res, err = server.cmdExport(msg)
 	case "shutdown":
 		if !core.DevMode {
 			err = fmt.Errorf("unknown command '%s'", msg.Args[0])
@@ -1064,8 +1292,13 @@ func (server *Server) command(msg *Message, client *Client) (
 		res, err = server.cmdBounds(msg)
 	case "get":
 		res, err = server.cmdGet(msg)
+		if err == nil {
+			server.trackRead(client, msg)
+		}
 	case "jget":
 		res, err = server.cmdJget(msg)
+	case "history":
+		res, err = server.cmdHistory(msg)
 	case "jset":
 		res, d, err = server.cmdJset(msg)
 	case "jdel":
@@ -1108,6 +1341,12 @@ func (server *Server) command(msg *Message, client *Client) (
 		res, err = server.cmdEvalUnified(false, msg)
 	case "evalsha", "evalrosha", "evalnasha":
 		res, err = server.cmdEvalUnified(true, msg)
+	case "function":
+		res, d, err = server.cmdFunction(msg)
+	case "fcall":
+		res, err = server.cmdFcall(msg, false)
+	case "fcall_ro":
+		res, err = server.cmdFcall(msg, true)
 	case "script load":
 		res, err = server.cmdScriptLoad(msg)
 	case "script exists":
@@ -1213,6 +1452,8 @@ const (
 	HTTP
 	WebSocket
 	JSON
+	REST
+	SSE
 )
 
 // Message is a resp message
@@ -1223,6 +1464,10 @@ type Message struct {
 	OutputType Type
 	Auth       string
 	Deadline   *deadline.Deadline
+	RESP3      bool // RESP replies use RESP3
+	resp3      *resp3Hints
+	// LastEventID is the Last-Event-ID header of an SSE request
+	LastEventID string
 }
 
 // Command returns the first argument as a lowercase string
@@ -1301,10 +1546,19 @@ func readNextHTTPCommand(packet []byte, argsIn [][]byte, msg *Message, wr io.Wri
 		websocket := false
 		websocketVersion := 0
 		websocketKey := ""
+		eventStream := false
 		for _, header := range headers[1:] {
 			if header[0] == 'a' || header[0] == 'A' {
 				if strings.HasPrefix(strings.ToLower(header), "authorization:") {
 					msg.Auth = strings.TrimSpace(header[len("authorization:"):])
+				} else if strings.HasPrefix(strings.ToLower(header), "accept:") {
+					if strings.Contains(strings.ToLower(header[len("accept:"):]), "text/event-stream") {
+						eventStream = true
+					}
+				}
+			} else if header[0] == 'l' || header[0] == 'L' {
+				if strings.HasPrefix(strings.ToLower(header), "last-event-id:") {
+					msg.LastEventID = strings.TrimSpace(header[len("last-event-id:"):])
 				}
 			} else if header[0] == 'u' || header[0] == 'U' {
 				if strings.HasPrefix(strings.ToLower(header), "upgrade:") && strings.ToLower(strings.TrimSpace(header[len("upgrade:"):])) == "websocket" {
@@ -1351,6 +1605,9 @@ func readNextHTTPCommand(packet []byte, argsIn [][]byte, msg *Message, wr io.Wri
 			path += string(packet[:contentLength])
 			packet = packet[contentLength:]
 		}
+		if eventStream && msg.ConnType == HTTP {
+			msg.ConnType = SSE
+		}
 		if path == "" {
 			return true, nil
 		}
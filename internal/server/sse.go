@@ -0,0 +1,198 @@
+package server
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sseHeartbeat is how often a comment is sent on an idle Server-Sent Events
+// stream, which keeps proxies from closing it.
+const sseHeartbeat = time.Second * 15
+
+// sseWriter writes the events of a live geofence or subscription that was
+// requested over HTTP with "Accept: text/event-stream".
+type sseWriter struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	last   time.Time
+	closed bool
+	done   chan struct{}
+}
+
+// newSSEWriter writes the response header and starts the heartbeats.
+func newSSEWriter(conn net.Conn) (*sseWriter, error) {
+	_, err := conn.Write([]byte("HTTP/1.1 200 OK\r\n" +
+		"Content-Type: text/event-stream\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"Connection: keep-alive\r\n" +
+		"Access-Control-Allow-Origin: *\r\n" +
+		"\r\n"))
+	if err != nil {
+		return nil, err
+	}
+	w := &sseWriter{conn: conn, last: time.Now(), done: make(chan struct{})}
+	go w.heartbeats()
+	return w, nil
+}
+
+func (w *sseWriter) heartbeats() {
+	t := time.NewTicker(sseHeartbeat / 3)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			if !w.closed && time.Since(w.last) >= sseHeartbeat {
+				w.write([]byte(": heartbeat\n\n"))
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+func (w *sseWriter) write(b []byte) error {
+	w.last = time.Now()
+	_, err := w.conn.Write(b)
+	return err
+}
+
+// writeEvent writes an event. The id is omitted when it's zero. Every line
+// of the data is sent as a separate data field.
+func (w *sseWriter) writeEvent(id uint64, data string) error {
+	var b []byte
+	if id != 0 {
+		b = append(b, "id: "...)
+		b = strconv.AppendUint(b, id, 10)
+		b = append(b, '\n')
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b = append(b, "data: "...)
+		b = append(b, strings.TrimSuffix(line, "\r")...)
+		b = append(b, '\n')
+	}
+	b = append(b, '\n')
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(b)
+}
+
+// close stops the heartbeats.
+func (w *sseWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+	}
+}
+
+// chanLogSize is the number of recent messages of a channel that are kept
+// for the SSE subscribers that resume from a Last-Event-ID.
+const chanLogSize = 1000
+
+// chanLogTTL is how long the recent messages of a channel are kept.
+const chanLogTTL = time.Second * 30
+
+// chanLog keeps the recent messages of the channels in memory, in a bounded
+// ring per channel. It has its own lock, so that it isn't guarded by the
+// server lock.
+type chanLog struct {
+	mu    sync.Mutex
+	idx   uint64 // id of the last message
+	rings map[string]*chanRing
+	swept time.Time
+}
+
+type chanRing struct {
+	entries []chanLogEntry // ordered by id from start
+	start   int
+}
+
+type chanLogEntry struct {
+	id  uint64
+	msg string
+	at  time.Time
+}
+
+// newChanLog returns an empty log. The ids start at the current time, so
+// that the ids of a client that resumes after a restart are older than the
+// new ones.
+func newChanLog() *chanLog {
+	return &chanLog{
+		idx:   uint64(time.Now().UnixNano()),
+		rings: make(map[string]*chanRing),
+		swept: time.Now(),
+	}
+}
+
+// add keeps a message of a channel, which is published right away, and
+// returns its id.
+func (cl *chanLog) add(name, msg string) uint64 {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	now := time.Now()
+	if now.Sub(cl.swept) > chanLogTTL {
+		// drop the rings of the channels that are gone or idle
+		for name, ring := range cl.rings {
+			if now.Sub(ring.last().at) > chanLogTTL {
+				delete(cl.rings, name)
+			}
+		}
+		cl.swept = now
+	}
+	cl.idx++
+	entry := chanLogEntry{id: cl.idx, msg: msg, at: now}
+	ring := cl.rings[name]
+	if ring == nil {
+		ring = &chanRing{}
+		cl.rings[name] = ring
+	}
+	if len(ring.entries) < chanLogSize {
+		ring.entries = append(ring.entries, entry)
+	} else {
+		ring.entries[ring.start] = entry
+		ring.start = (ring.start + 1) % chanLogSize
+	}
+	return cl.idx
+}
+
+// last returns the newest entry of a ring.
+func (ring *chanRing) last() chanLogEntry {
+	return ring.entries[(ring.start+len(ring.entries)-1)%len(ring.entries)]
+}
+
+// since returns the kept messages of the channels that came after the id,
+// ordered by id.
+func (cl *chanLog) since(names []string, id uint64) []submsg {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	var msgs []submsg
+	for _, name := range names {
+		ring := cl.rings[name]
+		if ring == nil {
+			continue
+		}
+		for i := 0; i < len(ring.entries); i++ {
+			entry := ring.entries[(ring.start+i)%len(ring.entries)]
+			if entry.id <= id || time.Since(entry.at) > chanLogTTL {
+				continue
+			}
+			msgs = append(msgs, submsg{
+				kind:    pubsubChannel,
+				channel: name,
+				message: entry.msg,
+				id:      entry.id,
+			})
+		}
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].id < msgs[j].id
+	})
+	return msgs
+}
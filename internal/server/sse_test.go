@@ -0,0 +1,30 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestChanLog(t *testing.T) {
+	cl := newChanLog()
+	first := cl.add("a", "a0")
+	for i := 1; i < chanLogSize+10; i++ {
+		cl.add("a", "a"+strconv.Itoa(i))
+	}
+	last := cl.add("b", "b0")
+	msgs := cl.since([]string{"a"}, 0)
+	if len(msgs) != chanLogSize {
+		t.Fatalf("expected %d, got %d", chanLogSize, len(msgs))
+	}
+	if msgs[0].message != "a10" || msgs[0].id != first+10 {
+		t.Fatalf("expected 'a10', got '%s'", msgs[0].message)
+	}
+	msgs = cl.since([]string{"a", "b"}, last-2)
+	if len(msgs) != 2 || msgs[0].message != "a1009" ||
+		msgs[1].message != "b0" {
+		t.Fatalf("unexpected %v", msgs)
+	}
+	if msgs := cl.since([]string{"c"}, 0); len(msgs) != 0 {
+		t.Fatalf("expected none, got %d", len(msgs))
+	}
+}
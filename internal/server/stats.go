@@ -65,11 +65,24 @@ func (s *Server) cmdStats(msg *Message) (res resp.Value, err error) {
 			m["in_memory_size"] = col.TotalWeight()
 			m["num_objects"] = col.Count()
 			m["num_strings"] = col.StringCount()
+			if indexes := col.Indexes(); len(indexes) > 0 {
+				m["indexes"] = indexes
+			}
+			if kc := s.keyConfigs[key]; kc != nil {
+				m["default_ttl"] = kc.defaultTTL
+				m["max_objects"] = kc.maxObjects
+				if kc.index != "" {
+					m["geometry_index"] = kc.index
+				}
+				if kc.history {
+					m["history"] = kc.getProperty(keyHistory)
+				}
+			}
 			switch msg.OutputType {
 			case JSON:
 				ms = append(ms, m)
 			case RESP:
-				vals = append(vals, resp.ArrayValue(respValuesSimpleMap(m)))
+				vals = append(vals, respSimpleMap(msg, m))
 			}
 		} else {
 			switch msg.OutputType {
@@ -119,8 +132,7 @@ func (s *Server) cmdServer(msg *Message) (res resp.Value, err error) {
 		}
 		res = resp.StringValue(`{"ok":true,"stats":` + string(data) + `,"elapsed":"` + time.Since(start).String() + "\"}")
 	case RESP:
-		vals := respValuesSimpleMap(m)
-		res = resp.ArrayValue(vals)
+		res = respSimpleMap(msg, m)
 	}
 	return res, nil
 }
@@ -139,6 +151,8 @@ func (s *Server) basicStats(m map[string]interface{}) {
 	m["aof_size"] = s.aofsz
 	m["num_collections"] = s.cols.Len()
 	m["num_hooks"] = len(s.hooks)
+	m["lazyfree_pending_objects"] = s.statsLazyfreePending.get()
+	m["lazyfreed_objects"] = s.statsLazyfreed.get()
 	sz := 0
 	s.cols.Ascend(nil, func(v interface{}) bool {
 		col := v.(*collectionKeyContainer).col
@@ -295,6 +309,20 @@ func (s *Server) extStats(m map[string]interface{}) {
 	m["tile38_total_messages_sent"] = s.statsTotalMsgsSent.get()
 	// Number of key expiration events
 	m["tile38_expired_keys"] = s.statsExpired.get()
+	// Number of objects evicted by the maxmemory-policy
+	m["tile38_evicted_keys"] = s.statsEvicted.get()
+	// Number of objects waiting to be freed in the background
+	m["tile38_lazyfree_pending_objects"] = s.statsLazyfreePending.get()
+	// Number of objects freed in the background
+	m["tile38_lazyfreed_objects"] = s.statsLazyfreed.get()
+	// Number of commands rejected by a rate limit
+	m["tile38_rejected_commands_limit"] = s.statsRejectedCommands.get()
+	m["tile38_rejected_bytes_limit"] = s.statsRejectedBytes.get()
+	m["tile38_rejected_heavy_limit"] = s.statsRejectedHeavy.get()
+	// Progress of IMPORT
+	m["tile38_imports_in_progress"] = s.statsImportsRunning.get()
+	m["tile38_imported_lines"] = s.statsImportedLines.get()
+	m["tile38_imported_objects"] = s.statsImportedObjects.get()
 	// Number of connected slaves
 	m["tile38_connected_slaves"] = len(s.aofconnM)
 
@@ -373,10 +401,19 @@ func (s *Server) writeInfoPersistence(w *bytes.Buffer) {
 }
 
 func (s *Server) writeInfoStats(w *bytes.Buffer) {
-	fmt.Fprintf(w, "total_connections_received:%d\r\n", s.statsTotalConns.get())  // Total number of connections accepted by the server
-	fmt.Fprintf(w, "total_commands_processed:%d\r\n", s.statsTotalCommands.get()) // Total number of commands processed by the server
-	fmt.Fprintf(w, "total_messages_sent:%d\r\n", s.statsTotalMsgsSent.get())      // Total number of commands processed by the server
-	fmt.Fprintf(w, "expired_keys:%d\r\n", s.statsExpired.get())                   // Total number of key expiration events
+	fmt.Fprintf(w, "total_connections_received:%d\r\n", s.statsTotalConns.get())    // Total number of connections accepted by the server
+	fmt.Fprintf(w, "total_commands_processed:%d\r\n", s.statsTotalCommands.get())   // Total number of commands processed by the server
+	fmt.Fprintf(w, "total_messages_sent:%d\r\n", s.statsTotalMsgsSent.get())        // Total number of commands processed by the server
+	fmt.Fprintf(w, "expired_keys:%d\r\n", s.statsExpired.get())                     // Total number of key expiration events
+	fmt.Fprintf(w, "evicted_keys:%d\r\n", s.statsEvicted.get())                     // Number of objects evicted by the maxmemory-policy
+	fmt.Fprintf(w, "lazyfree_pending_objects:%d\r\n", s.statsLazyfreePending.get()) // Number of objects waiting to be freed in the background
+	fmt.Fprintf(w, "lazyfreed_objects:%d\r\n", s.statsLazyfreed.get())              // Number of objects freed in the background
+	fmt.Fprintf(w, "rejected_commands_limit:%d\r\n", s.statsRejectedCommands.get()) // Commands rejected by a commands per second limit
+	fmt.Fprintf(w, "rejected_bytes_limit:%d\r\n", s.statsRejectedBytes.get())       // Commands rejected by a bytes per second limit
+	fmt.Fprintf(w, "rejected_heavy_limit:%d\r\n", s.statsRejectedHeavy.get())       // Searches rejected by a concurrent heavy queries limit
+	fmt.Fprintf(w, "imports_in_progress:%d\r\n", s.statsImportsRunning.get())       // Number of IMPORT commands that are running
+	fmt.Fprintf(w, "imported_lines:%d\r\n", s.statsImportedLines.get())             // Number of lines read by IMPORT
+	fmt.Fprintf(w, "imported_objects:%d\r\n", s.statsImportedObjects.get())         // Number of objects written by IMPORT
 }
 
 // writeInfoReplication writes all replication data to the 'info' response
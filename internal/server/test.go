@@ -222,6 +222,9 @@ func (s *Server) parseArea(ovs []string, doClip bool) (vs []string, o geojson.Ob
 			return
 		}
 	}
+	if !doClip {
+		vs, o, err = s.parseBuffer(vs, o)
+	}
 	return
 }
 
@@ -300,11 +303,13 @@ func (s *Server) cmdTest(msg *Message) (res resp.Value, err error) {
 		return resp.StringValue(buf.String()), nil
 	case RESP:
 		if clipped != nil {
-			return resp.ArrayValue([]resp.Value{
-				resp.IntegerValue(result),
-				resp.StringValue(clipped.JSON())}), nil
+			vals := []resp.Value{
+				resp.BoolValue(result != 0),
+				resp.StringValue(clipped.JSON())}
+			respBools(msg, vals, 0)
+			return resp.ArrayValue(vals), nil
 		}
-		return resp.IntegerValue(result), nil
+		return respBool(msg, result != 0), nil
 	}
 	return NOMessage, nil
 }
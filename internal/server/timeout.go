@@ -0,0 +1,144 @@
+package server
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tidwall/resp"
+	"github.com/tidwall/tile38/internal/deadline"
+)
+
+// isSearchCommand returns true for the commands that scan collections. They
+// run with the default timeout and are canceled when the client disconnects.
+func isSearchCommand(command string) bool {
+	switch command {
+	case "keys", "scan", "nearby", "within", "intersects", "search":
+		return true
+	}
+	return false
+}
+
+// defaultDeadline returns the deadline of a search that has no TIMEOUT. The
+// timeout of the client, set with CLIENT SETTIMEOUT, overrides the
+// default-timeout of the server. Without a timeout, the deadline of a
+// network client has no time limit and is only used for cancellation.
+func (server *Server) defaultDeadline(client *Client) *deadline.Deadline {
+	client.mu.Lock()
+	timeout := client.timeout
+	client.mu.Unlock()
+	if timeout == 0 {
+		timeout = server.config.defaultTimeout()
+	}
+	if timeout > 0 {
+		return deadline.New(time.Now().Add(
+			time.Duration(timeout * float64(time.Second))))
+	}
+	if client.netConn != nil {
+		return new(deadline.Deadline)
+	}
+	return nil
+}
+
+// watchDisconnectDelay is how long a search runs before the connection of
+// the client is watched. Most searches are done by then, and they don't pay
+// for the watching.
+const watchDisconnectDelay = time.Second / 10
+
+// watchDisconnect reads the connection of the client while a command runs
+// and cancels the deadline when the connection is reset or closed. The
+// watching starts once the command has run for watchDisconnectDelay, and the
+// returned function stops it. Bytes that are read in the meantime, such as
+// pipelined commands, are kept in client.pending and handled next. An EOF
+// only stops the watching, because a client that has closed its side of the
+// connection may still be reading the reply.
+func (client *Client) watchDisconnect(dl *deadline.Deadline) (stop func()) {
+	conn := client.netConn
+	var mu sync.Mutex
+	var stopped bool
+	var done chan struct{} // closed when the watching ends
+	timer := time.AfterFunc(watchDisconnectDelay, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		done = make(chan struct{})
+		go client.readDisconnect(conn, dl, done)
+	})
+	return func() {
+		timer.Stop()
+		mu.Lock()
+		stopped = true
+		watching := done
+		mu.Unlock()
+		if watching == nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now())
+		<-watching
+		conn.SetReadDeadline(time.Time{})
+	}
+}
+
+// readDisconnect reads the connection for watchDisconnect until it's told to
+// stop with a read deadline.
+func (client *Client) readDisconnect(
+	conn net.Conn, dl *deadline.Deadline, done chan struct{},
+) {
+	defer close(done)
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		client.mu.Lock()
+		client.pending = append(client.pending, buf[:n]...)
+		client.mu.Unlock()
+		if err != nil {
+			if err, ok := err.(net.Error); ok && err.Timeout() {
+				return
+			}
+			if err != io.EOF {
+				dl.Cancel()
+			}
+			return
+		}
+	}
+}
+
+// takePending returns the bytes that were read by watchDisconnect.
+func (client *Client) takePending() []byte {
+	client.mu.Lock()
+	pending := client.pending
+	client.pending = nil
+	client.mu.Unlock()
+	return pending
+}
+
+// cmdClientSetTimeout handles CLIENT SETTIMEOUT seconds. The timeout applies
+// to the searches of the client that have no TIMEOUT, and zero uses the
+// default-timeout of the server.
+func (s *Server) cmdClientSetTimeout(msg *Message, client *Client) (
+	resp.Value, error,
+) {
+	start := time.Now()
+	if len(msg.Args) != 3 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	timeout, err := strconv.ParseFloat(msg.Args[2], 64)
+	if err != nil || timeout < 0 {
+		return NOMessage, errInvalidArgument(msg.Args[2])
+	}
+	client.mu.Lock()
+	client.timeout = timeout
+	client.mu.Unlock()
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return resp.SimpleStringValue("OK"), nil
+	}
+	return NOMessage, nil
+}
@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/tile38/internal/expr"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -167,6 +169,49 @@ func (wherein whereinT) match(value float64) bool {
 	return ok
 }
 
+type wherejsonT struct {
+	path  string
+	op    string
+	value string
+	num   float64
+	isnum bool
+}
+
+// match tests the value at the path of a JSON document. Numbers are compared
+// numerically when both sides are numbers, everything else is compared as
+// a string. A missing path only matches "!=".
+func (wherejson wherejsonT) match(json string) bool {
+	res := gjson.Get(json, wherejson.path)
+	if !res.Exists() {
+		return wherejson.op == "!="
+	}
+	var c int
+	if wherejson.isnum && res.Type == gjson.Number {
+		if res.Num < wherejson.num {
+			c = -1
+		} else if res.Num > wherejson.num {
+			c = 1
+		}
+	} else {
+		c = strings.Compare(res.String(), wherejson.value)
+	}
+	switch wherejson.op {
+	case "==", "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
 type whereevalT struct {
 	c        *Server
 	luaState *lua.LState
@@ -240,6 +285,8 @@ type searchScanBaseTokens struct {
 	wheres     []whereT
 	whereins   []whereinT
 	whereevals []whereevalT
+	whereexprs []*expr.Expr
+	wherejsons []wherejsonT
 	nofields   bool
 	ulimit     bool
 	limit      uint64
@@ -247,6 +294,7 @@ type searchScanBaseTokens struct {
 	sparse     uint8
 	desc       bool
 	clip       bool
+	orderBy    string
 }
 
 func (s *Server) parseSearchScanBaseTokens(
@@ -288,6 +336,15 @@ func (s *Server) parseSearchScanBaseTokens(
 					err = errInvalidNumberOfArguments
 					return
 				}
+				if expr.IsExpr(field) {
+					var e *expr.Expr
+					if e, err = expr.Compile(field); err != nil {
+						err = errors.New("invalid expression: " + err.Error())
+						return
+					}
+					t.whereexprs = append(t.whereexprs, e)
+					continue
+				}
 				if vs, smin, ok = tokenval(vs); !ok || smin == "" {
 					err = errInvalidNumberOfArguments
 					return
@@ -358,6 +415,33 @@ func (s *Server) parseSearchScanBaseTokens(
 				}
 				t.whereins = append(t.whereins, whereinT{field, valMap})
 				continue
+			case "wherejson":
+				vs = nvs
+				var wherejson wherejsonT
+				if vs, wherejson.path, ok = tokenval(vs); !ok || wherejson.path == "" {
+					err = errInvalidNumberOfArguments
+					return
+				}
+				if vs, wherejson.op, ok = tokenval(vs); !ok || wherejson.op == "" {
+					err = errInvalidNumberOfArguments
+					return
+				}
+				switch wherejson.op {
+				case "==", "=", "!=", "<", "<=", ">", ">=":
+				default:
+					err = errInvalidArgument(wherejson.op)
+					return
+				}
+				if vs, wherejson.value, ok = tokenval(vs); !ok {
+					err = errInvalidNumberOfArguments
+					return
+				}
+				if isJSONNumber(wherejson.value) {
+					wherejson.num, _ = strconv.ParseFloat(wherejson.value, 64)
+					wherejson.isnum = true
+				}
+				t.wherejsons = append(t.wherejsons, wherejson)
+				continue
 			case "whereevalsha":
 				fallthrough
 			case "whereeval":
@@ -571,6 +655,26 @@ func (s *Server) parseSearchScanBaseTokens(
 					return
 				}
 				continue
+			case "order":
+				vs = nvs
+				if t.orderBy != "" {
+					err = errDuplicateArgument("ORDER BY")
+					return
+				}
+				var by string
+				if vs, by, ok = tokenval(vs); !ok || by == "" {
+					err = errInvalidNumberOfArguments
+					return
+				}
+				if strings.ToLower(by) != "by" {
+					err = errInvalidArgument(by)
+					return
+				}
+				if vs, t.orderBy, ok = tokenval(vs); !ok || t.orderBy == "" {
+					err = errInvalidNumberOfArguments
+					return
+				}
+				continue
 			case "clip":
 				vs = nvs
 				if t.clip {
@@ -594,7 +698,7 @@ func (s *Server) parseSearchScanBaseTokens(
 			err = errors.New("FENCE is not allowed for " + strings.ToUpper(cmd))
 			return
 		}
-	} else {
+	} else if t.orderBy == "" {
 		if t.desc {
 			err = errors.New("DESC is not allowed for " + strings.ToUpper(cmd))
 			return
@@ -604,6 +708,20 @@ func (s *Server) parseSearchScanBaseTokens(
 			return
 		}
 	}
+	if t.orderBy != "" {
+		if cmd == "nearby" || cmd == "search" {
+			err = errors.New("ORDER BY is not allowed for " + strings.ToUpper(cmd))
+			return
+		}
+		if ssparse != "" {
+			err = errors.New("ORDER BY is not allowed when SPARSE is specified")
+			return
+		}
+		if t.fence {
+			err = errors.New("ORDER BY is not allowed when FENCE is specified")
+			return
+		}
+	}
 	if ssparse != "" && slimit != "" {
 		err = errors.New("LIMIT is not allowed when SPARSE is specified")
 		return
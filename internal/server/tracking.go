@@ -0,0 +1,337 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tidwall/redcon"
+	"github.com/tidwall/resp"
+)
+
+// Client side caching
+//
+// A client that turns on tracking with CLIENT TRACKING is sent an invalidation
+// when an object that it has read with GET is modified. In BCAST mode nothing
+// is remembered and the client is sent the invalidations of all objects in the
+// collections that match one of its prefixes. The invalidations are RESP3 push
+// messages:
+//
+//	>2 "invalidate" [[key id] ...]
+//
+// An entry without an id invalidates the whole collection, and a null
+// invalidates everything.
+//
+// Like Redis, a tracked object is forgotten once it's invalidated and the
+// client must read it again to be sent the next invalidation.
+
+// trackRead remembers that a client read an object with GET.
+func (s *Server) trackRead(client *Client, msg *Message) {
+	if client == nil || len(msg.Args) < 3 {
+		return
+	}
+	s.trackmu.Lock()
+	defer s.trackmu.Unlock()
+	client.mu.Lock()
+	track := client.tracking && !client.trackBcast
+	client.mu.Unlock()
+	if !track {
+		return
+	}
+	key, id := msg.Args[1], msg.Args[2]
+	ids := s.tracked[key]
+	if ids == nil {
+		ids = make(map[string]map[int]*Client)
+		s.tracked[key] = ids
+	}
+	clients := ids[id]
+	if clients == nil {
+		clients = make(map[int]*Client)
+		ids[id] = clients
+	}
+	clients[client.id] = client
+	if client.trackedIDs == nil {
+		client.trackedIDs = make(map[string]map[string]bool)
+	}
+	if client.trackedIDs[key] == nil {
+		client.trackedIDs[key] = make(map[string]bool)
+	}
+	client.trackedIDs[key][id] = true
+}
+
+// untrack forgets the objects that a client has read. The server trackmu
+// must be locked.
+func (s *Server) untrack(client *Client) {
+	for key, cids := range client.trackedIDs {
+		ids := s.tracked[key]
+		for id := range cids {
+			delete(ids[id], client.id)
+			if len(ids[id]) == 0 {
+				delete(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			delete(s.tracked, key)
+		}
+	}
+	client.trackedIDs = nil
+}
+
+// stopTracking forgets everything about the tracking of a client that has
+// disconnected.
+func (s *Server) stopTracking(client *Client) {
+	s.trackmu.Lock()
+	defer s.trackmu.Unlock()
+	client.mu.Lock()
+	tracking := client.tracking
+	client.tracking = false
+	client.mu.Unlock()
+	if tracking {
+		s.trackingClients.add(-1)
+	}
+	s.untrack(client)
+	delete(s.trackBcast, client.id)
+}
+
+// invalidations are the entries that are sent to each client. A nil entry
+// invalidates everything.
+type invalidations map[int][][]string
+
+func (inv invalidations) add(clientID int, entry []string) {
+	inv[clientID] = append(inv[clientID], entry)
+}
+
+// invalidateTracking sends the invalidations for a write to the clients that
+// are tracking the modified objects.
+func (s *Server) invalidateTracking(d *commandDetails) {
+	if s.trackingClients.get() == 0 {
+		return
+	}
+	inv := make(invalidations)
+	s.collectInvalidations(d, inv)
+	if len(inv) == 0 {
+		return
+	}
+	s.connsmu.RLock()
+	defer s.connsmu.RUnlock()
+	for id, entries := range inv {
+		client := s.conns[id]
+		if client == nil {
+			continue
+		}
+		client.mu.Lock()
+		track := client.tracking && client.resp3
+		client.mu.Unlock()
+		if !track {
+			continue
+		}
+		b := appendPush(nil, 2, true)
+		b = redcon.AppendBulkString(b, "invalidate")
+		if entries[0] == nil {
+			b = append(b, "_\r\n"...)
+		} else {
+			b = redcon.AppendArray(b, len(entries))
+			for _, entry := range entries {
+				b = redcon.AppendArray(b, len(entry))
+				for _, v := range entry {
+					b = redcon.AppendBulkString(b, v)
+				}
+			}
+		}
+		client.writePush(b)
+	}
+}
+
+func (s *Server) collectInvalidations(d *commandDetails, inv invalidations) {
+	if d.parent {
+		for _, d := range d.children {
+			s.collectInvalidations(d, inv)
+		}
+		return
+	}
+	if !d.updated {
+		return
+	}
+	switch d.command {
+	case "createindex", "dropindex", "persist", "keyconfig":
+		// the objects did not change
+		return
+	case "flushdb":
+		s.trackmu.Lock()
+		for _, ids := range s.tracked {
+			for _, clients := range ids {
+				for id, client := range clients {
+					inv[id] = [][]string{nil}
+					client.trackedIDs = nil
+				}
+			}
+		}
+		s.tracked = make(map[string]map[string]map[int]*Client)
+		for id := range s.trackBcast {
+			inv[id] = [][]string{nil}
+		}
+		s.trackmu.Unlock()
+		return
+	}
+	keys := []string{d.key}
+	if d.newKey != "" {
+		keys = append(keys, d.newKey)
+	}
+	s.trackmu.Lock()
+	defer s.trackmu.Unlock()
+	for _, key := range keys {
+		entry := []string{key}
+		if d.id != "" {
+			entry = append(entry, d.id)
+		}
+		if ids := s.tracked[key]; ids != nil {
+			if d.id != "" {
+				for id, client := range ids[d.id] {
+					inv.add(id, entry)
+					delete(client.trackedIDs[key], d.id)
+					if len(client.trackedIDs[key]) == 0 {
+						delete(client.trackedIDs, key)
+					}
+				}
+				delete(ids, d.id)
+			} else {
+				seen := make(map[int]bool)
+				for _, clients := range ids {
+					for id, client := range clients {
+						if !seen[id] {
+							seen[id] = true
+							inv.add(id, entry)
+							delete(client.trackedIDs, key)
+						}
+					}
+				}
+				ids = nil
+			}
+			if len(ids) == 0 {
+				delete(s.tracked, key)
+			}
+		}
+		for id, prefixes := range s.trackBcast {
+			if hasTrackingPrefix(prefixes, key) {
+				inv.add(id, entry)
+			}
+		}
+	}
+}
+
+func hasTrackingPrefix(prefixes []string, key string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdClientTracking handles the CLIENT TRACKING command.
+//
+// CLIENT TRACKING ON|OFF [BCAST] [PREFIX prefix [PREFIX prefix ...]]
+func (s *Server) cmdClientTracking(msg *Message, client *Client) (
+	resp.Value, error,
+) {
+	start := time.Now()
+	if len(msg.Args) < 3 {
+		return NOMessage, errInvalidNumberOfArguments
+	}
+	var on, bcast bool
+	var prefixes []string
+	switch strings.ToLower(msg.Args[2]) {
+	case "on":
+		on = true
+	case "off":
+	default:
+		return NOMessage, errInvalidArgument(msg.Args[2])
+	}
+	for i := 3; i < len(msg.Args); i++ {
+		switch strings.ToLower(msg.Args[i]) {
+		case "bcast":
+			bcast = true
+		case "prefix":
+			i++
+			if i == len(msg.Args) {
+				return NOMessage, errInvalidNumberOfArguments
+			}
+			prefixes = append(prefixes, msg.Args[i])
+		default:
+			return NOMessage, errInvalidArgument(msg.Args[i])
+		}
+	}
+	if client == nil {
+		return NOMessage, errors.New("CLIENT TRACKING is not supported " +
+			"on this connection")
+	}
+	if len(prefixes) > 0 && !bcast {
+		return NOMessage, errors.New("PREFIX requires BCAST")
+	}
+	s.trackmu.Lock()
+	defer s.trackmu.Unlock()
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if on && !client.resp3 {
+		return NOMessage, errors.New("client tracking requires RESP3, " +
+			"switch with HELLO 3")
+	}
+	if on != client.tracking {
+		if on {
+			s.trackingClients.add(1)
+		} else {
+			s.trackingClients.add(-1)
+		}
+	}
+	client.tracking = on
+	client.trackBcast = on && bcast
+	s.untrack(client)
+	delete(s.trackBcast, client.id)
+	if client.trackBcast {
+		s.trackBcast[client.id] = prefixes
+	}
+	switch msg.OutputType {
+	case JSON:
+		return resp.StringValue(`{"ok":true,"elapsed":"` +
+			time.Now().Sub(start).String() + "\"}"), nil
+	case RESP:
+		return resp.SimpleStringValue("OK"), nil
+	}
+	return NOMessage, nil
+}
+
+// writePush writes a push message to the connection of the client. The
+// messages are written in the background, in order, and never in the middle
+// of a reply.
+func (client *Client) writePush(b []byte) {
+	client.pmu.Lock()
+	client.pushes = append(client.pushes, b...)
+	start := !client.pushing
+	client.pushing = true
+	client.pmu.Unlock()
+	if start {
+		go client.flushPushes()
+	}
+}
+
+func (client *Client) flushPushes() {
+	for {
+		client.pmu.Lock()
+		b := client.pushes
+		client.pushes = nil
+		if len(b) == 0 {
+			client.pushing = false
+			client.pmu.Unlock()
+			return
+		}
+		client.pmu.Unlock()
+		client.wmu.Lock()
+		if client.netConn != nil {
+			client.netConn.Write(b)
+		}
+		client.wmu.Unlock()
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import "testing"
+
+func TestTrackingCleanup(t *testing.T) {
+	s := &Server{
+		tracked:    make(map[string]map[string]map[int]*Client),
+		trackBcast: make(map[int][]string),
+	}
+	client := &Client{id: 1, resp3: true}
+	on := &Message{Args: []string{"client", "tracking", "on"}}
+	off := &Message{Args: []string{"client", "tracking", "off"}}
+	get := &Message{Args: []string{"get", "fleet", "truck1"}}
+
+	// CLIENT TRACKING OFF forgets the objects
+	if _, err := s.cmdClientTracking(on, client); err != nil {
+		t.Fatal(err)
+	}
+	s.trackRead(client, get)
+	if len(s.tracked) != 1 || s.trackingClients.get() != 1 {
+		t.Fatalf("expected a tracked object")
+	}
+	if _, err := s.cmdClientTracking(off, client); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.tracked) != 0 || client.trackedIDs != nil ||
+		s.trackingClients.get() != 0 {
+		t.Fatalf("expected no tracked objects, got %d", len(s.tracked))
+	}
+
+	// a disconnect forgets the objects and the BCAST prefixes
+	if _, err := s.cmdClientTracking(on, client); err != nil {
+		t.Fatal(err)
+	}
+	s.trackRead(client, get)
+	bcast := &Client{id: 2, resp3: true}
+	if _, err := s.cmdClientTracking(&Message{Args: []string{
+		"client", "tracking", "on", "bcast", "prefix", "fleet"}}, bcast,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if s.trackingClients.get() != 2 {
+		t.Fatalf("expected 2, got %d", s.trackingClients.get())
+	}
+	s.stopTracking(client)
+	s.stopTracking(bcast)
+	if len(s.tracked) != 0 || len(s.trackBcast) != 0 ||
+		s.trackingClients.get() != 0 {
+		t.Fatalf("expected no tracking")
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenUnix listens on a Unix domain socket. A socket file that was left
+// behind by a previous run is removed first. The socket is created in a
+// private directory next to path, which only the server can enter, and it's
+// moved to path once it has its permissions, so that it never has more
+// permissions than perm, not even for a moment.
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".tile38")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	uln := ln.(*net.UnixListener)
+	// the listener would remove the temporary path when closed
+	uln.SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, perm); err != nil {
+		uln.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		uln.Close()
+		return nil, err
+	}
+	return &unixListener{UnixListener: uln, path: path}, nil
+}
+
+// unixListener is a Unix domain socket listener that was moved to path.
+type unixListener struct {
+	*net.UnixListener
+	path string
+}
+
+// Addr returns the path of the socket.
+func (ln *unixListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: ln.path, Net: "unix"}
+}
+
+// Close closes the listener and removes the socket.
+func (ln *unixListener) Close() error {
+	err := ln.UnixListener.Close()
+	os.Remove(ln.path)
+	return err
+}
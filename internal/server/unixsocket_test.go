@@ -0,0 +1,55 @@
+//go:build unix
+
+package server
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tile38-unix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	umask := syscall.Umask(022)
+	defer syscall.Umask(umask)
+	path := filepath.Join(dir, "tile38.sock")
+	ln, err := listenUnix(path, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Fatalf("expected %o, got %o", 0600, perm)
+	}
+	if ln.Addr().String() != path {
+		t.Fatalf("expected %s, got %s", path, ln.Addr())
+	}
+	// only the socket is left in the directory
+	if fis, _ := ioutil.ReadDir(dir); len(fis) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(fis))
+	}
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ln.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket to be removed")
+	}
+}
@@ -1,9 +1,16 @@
 package tests
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/tidwall/gjson"
@@ -12,6 +19,10 @@ import (
 func subTestClient(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "valid json", client_valid_json_test)
 	runStep(t, mc, "valid client count", info_valid_client_count_test)
+	runStep(t, mc, "resp3", client_resp3_test)
+	runStep(t, mc, "unix socket", client_unix_socket_test)
+	runStep(t, mc, "tracking", client_tracking_test)
+	runStep(t, mc, "limits", client_limits_test)
 }
 
 func client_valid_json_test(mc *mockServer) error {
@@ -75,3 +86,399 @@ func info_valid_client_count_test(mc *mockServer) error {
 	}
 	return nil
 }
+
+// readRESP3 reads one RESP3 value and returns it as raw text.
+func readRESP3(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	switch line[0] {
+	case '$':
+		if n < 0 {
+			return line, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return "", err
+		}
+		return line + string(data), nil
+	case '*', '>', '%':
+		if line[0] == '%' {
+			n *= 2
+		}
+		for i := 0; i < n; i++ {
+			child, err := readRESP3(rd)
+			if err != nil {
+				return "", err
+			}
+			line += child
+		}
+	}
+	return line, nil
+}
+
+// doRESP3 sends a command and reads the reply.
+func doRESP3(conn net.Conn, rd *bufio.Reader, args ...string) (string, error) {
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	return readRESP3(rd)
+}
+
+func client_resp3_test(mc *mockServer) error {
+	conn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	do := func(args ...string) (string, error) {
+		return doRESP3(conn, rd, args...)
+	}
+	for i, step := range []struct {
+		args   []string
+		expect string
+	}{
+		{[]string{"SET", "fleet", "truck1", "FIELD", "speed", "50", "POINT", "33", "-115"},
+			"+OK\r\n"},
+		{[]string{"GET", "fleet", "truck1", "WITHFIELDS", "POINT"},
+			"*2\r\n*2\r\n$2\r\n33\r\n$4\r\n-115\r\n*2\r\n$5\r\nspeed\r\n$2\r\n50\r\n"},
+		{[]string{"HELLO", "4"},
+			"-NOPROTO unsupported protocol version\r\n"},
+		{[]string{"HELLO", "3"}, "%7\r\n$6\r\nserver\r\n$6\r\ntile38\r\n"},
+		{[]string{"GET", "fleet", "truck1", "WITHFIELDS", "POINT"},
+			"*2\r\n*2\r\n,33\r\n,-115\r\n%1\r\n$5\r\nspeed\r\n,50\r\n"},
+		{[]string{"GET", "fleet", "truck2"}, "_\r\n"},
+		{[]string{"NEARBY", "fleet", "POINTS", "POINT", "33", "-115", "1000"},
+			"*2\r\n:0\r\n*1\r\n*3\r\n$6\r\ntruck1\r\n*2\r\n,33\r\n,-115\r\n%1\r\n$5\r\nspeed\r\n,50\r\n"},
+		{[]string{"TEST", "POINT", "33", "-115", "WITHIN", "BOUNDS", "32", "-116", "34", "-114"},
+			"#t\r\n"},
+		{[]string{"CONFIG", "GET", "maxmemory"},
+			"%1\r\n$9\r\nmaxmemory\r\n$0\r\n\r\n"},
+		{[]string{"HELLO", "2"}, "*14\r\n$6\r\nserver\r\n$6\r\ntile38\r\n"},
+		{[]string{"TEST", "POINT", "33", "-115", "WITHIN", "BOUNDS", "32", "-116", "34", "-114"},
+			":1\r\n"},
+		{[]string{"HELLO", "3"}, "%7\r\n"},
+		{[]string{"MULTI"}, "+OK\r\n"},
+		{[]string{"GET", "fleet", "truck1", "WITHFIELDS", "POINT"}, "+QUEUED\r\n"},
+		{[]string{"BOUNDS", "fleet"}, "+QUEUED\r\n"},
+		{[]string{"EXEC"},
+			"*2\r\n*2\r\n*2\r\n,33\r\n,-115\r\n%1\r\n$5\r\nspeed\r\n,50\r\n*2\r\n*2\r\n,-115\r\n,33\r\n"},
+		{[]string{"EVAL", "return tile38.call('GET', KEYS[1], ARGV[1], 'WITHFIELDS', 'POINT')", "1", "fleet", "truck1"},
+			"*2\r\n*2\r\n$2\r\n33\r\n$4\r\n-115\r\n*2\r\n$5\r\nspeed\r\n$2\r\n50\r\n"},
+		{[]string{"SUBSCRIBE", "chan1"},
+			">3\r\n$9\r\nsubscribe\r\n$5\r\nchan1\r\n:1\r\n"},
+	} {
+		res, err := do(step.args...)
+		if err != nil {
+			return fmt.Errorf("step[%d]: %v", i, err)
+		}
+		if !strings.HasPrefix(res, step.expect) {
+			return fmt.Errorf("step[%d]: expected '%q', got '%q'",
+				i, step.expect, res)
+		}
+	}
+	if _, err := mc.Do("PUBLISH", "chan1", "hello"); err != nil {
+		return err
+	}
+	res, err := readRESP3(rd)
+	if err != nil {
+		return err
+	}
+	if expect := ">3\r\n$7\r\nmessage\r\n$5\r\nchan1\r\n$5\r\nhello\r\n"; res != expect {
+		return fmt.Errorf("expected '%q', got '%q'", expect, res)
+	}
+
+	// live geofence events are push messages
+	fconn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer fconn.Close()
+	frd := bufio.NewReader(fconn)
+	for _, args := range [][]string{
+		{"HELLO", "3"},
+		{"NEARBY", "fleet", "FENCE", "DETECT", "enter", "POINT", "33", "-115", "1000"},
+	} {
+		if _, err := doRESP3(fconn, frd, args...); err != nil {
+			return err
+		}
+	}
+	if _, err := mc.Do("SET", "fleet", "truck2", "POINT", "33", "-115"); err != nil {
+		return err
+	}
+	res, err = readRESP3(frd)
+	if err != nil {
+		return err
+	}
+	if expect := ">2\r\n$5\r\nfence\r\n$"; !strings.HasPrefix(res, expect) ||
+		!strings.Contains(res, `"detect":"enter"`) {
+		return fmt.Errorf("expected '%q', got '%q'", expect, res)
+	}
+	_, err = mc.Do("DROP", "fleet")
+	return err
+}
+
+func client_unix_socket_test(mc *mockServer) error {
+	fi, err := os.Stat(mc.unixSocket)
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0700 {
+		return fmt.Errorf("unexpected socket mode %v", fi.Mode())
+	}
+	conn, err := redis.Dial("unix", mc.unixSocket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Do("SET", "fleet", "truck1", "POINT", 33, -115); err != nil {
+		return err
+	}
+	res, err := redis.String(conn.Do("GET", "fleet", "truck1"))
+	if err != nil {
+		return err
+	}
+	if expect := `{"type":"Point","coordinates":[-115,33]}`; res != expect {
+		return fmt.Errorf("expected '%s', got '%s'", expect, res)
+	}
+	_, err = conn.Do("DROP", "fleet")
+	return err
+}
+
+func client_tracking_test(mc *mockServer) error {
+	conn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	bconn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer bconn.Close()
+	brd := bufio.NewReader(bconn)
+	for i, step := range []struct {
+		conn   net.Conn
+		rd     *bufio.Reader
+		args   []string
+		expect string
+	}{
+		{conn, rd, []string{"CLIENT", "TRACKING", "ON"},
+			"-ERR client tracking requires RESP3"},
+		{conn, rd, []string{"HELLO", "3"}, "%7\r\n"},
+		{conn, rd, []string{"CLIENT", "TRACKING", "ON", "PREFIX", "fl"},
+			"-ERR PREFIX requires BCAST"},
+		{conn, rd, []string{"CLIENT", "TRACKING", "ON"}, "+OK\r\n"},
+		{bconn, brd, []string{"HELLO", "3"}, "%7\r\n"},
+		{bconn, brd, []string{"CLIENT", "TRACKING", "ON", "BCAST", "PREFIX", "fl"},
+			"+OK\r\n"},
+		{conn, rd, []string{"SET", "fleet", "truck1", "POINT", "33", "-115"},
+			"+OK\r\n"},
+		{conn, rd, []string{"GET", "fleet", "truck1"}, "$"},
+	} {
+		res, err := doRESP3(step.conn, step.rd, step.args...)
+		if err != nil {
+			return fmt.Errorf("step[%d]: %v", i, err)
+		}
+		if !strings.HasPrefix(res, step.expect) {
+			return fmt.Errorf("step[%d]: expected '%q', got '%q'",
+				i, step.expect, res)
+		}
+	}
+	// the SET above is broadcasted
+	invalidate := ">2\r\n$10\r\ninvalidate\r\n*1\r\n*2\r\n$5\r\nfleet\r\n$6\r\ntruck1\r\n"
+	bconn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	if res, err := readRESP3(brd); err != nil {
+		return err
+	} else if res != invalidate {
+		return fmt.Errorf("expected '%q', got '%q'", invalidate, res)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck1", "POINT", 34, -115}, {"OK"},
+		{"SET", "other", "truck1", "POINT", 34, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	for _, rd := range []*bufio.Reader{rd, brd} {
+		if res, err := readRESP3(rd); err != nil {
+			return err
+		} else if res != invalidate {
+			return fmt.Errorf("expected '%q', got '%q'", invalidate, res)
+		}
+	}
+	// the object is forgotten until it's read again, and the other
+	// collection doesn't match the prefix
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck1", "POINT", 35, -115}, {"OK"},
+		{"DROP", "other"}, {1},
+	}); err != nil {
+		return err
+	}
+	if res, err := doRESP3(conn, rd, "GET", "fleet", "truck1"); err != nil {
+		return err
+	} else if !strings.HasPrefix(res, "$") {
+		return fmt.Errorf("expected an object, got '%q'", res)
+	}
+	if res, err := readRESP3(brd); err != nil {
+		return err
+	} else if res != invalidate {
+		return fmt.Errorf("expected '%q', got '%q'", invalidate, res)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"DROP", "fleet"}, {1},
+	}); err != nil {
+		return err
+	}
+	drop := ">2\r\n$10\r\ninvalidate\r\n*1\r\n*1\r\n$5\r\nfleet\r\n"
+	for _, rd := range []*bufio.Reader{rd, brd} {
+		if res, err := readRESP3(rd); err != nil {
+			return err
+		} else if res != drop {
+			return fmt.Errorf("expected '%q', got '%q'", drop, res)
+		}
+	}
+	return nil
+}
+
+func client_limits_test(mc *mockServer) error {
+	conn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+
+	// countLimited sends commands and counts the rejected ones
+	countLimited := func(n int) (int, error) {
+		var limited int
+		for i := 0; i < n; i++ {
+			res, err := doRESP3(conn, rd, "GET", "fleet", "truck1")
+			if err != nil {
+				return 0, err
+			}
+			if strings.HasPrefix(res, "-LIMIT too many commands per second") {
+				limited++
+			} else if res != "$-1\r\n" {
+				return 0, fmt.Errorf("expected '%q', got '%q'", "$-1\r\n", res)
+			}
+		}
+		return limited, nil
+	}
+
+	// the config limits every client
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "client-commands-per-sec", 5}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	limited, err := countLimited(20)
+	if err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "client-commands-per-sec", 0}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if limited == 0 {
+		return errors.New("expected rejected commands")
+	}
+
+	// CLIENT LIMIT overrides the config of one client
+	if res, err := doRESP3(conn, rd, "CLIENT", "SETNAME", "limited"); err != nil {
+		return err
+	} else if res != "+OK\r\n" {
+		return fmt.Errorf("expected '%q', got '%q'", "+OK\r\n", res)
+	}
+	list, err := redis.String(mc.Do("CLIENT", "LIST"))
+	if err != nil {
+		return err
+	}
+	var id string
+	for _, line := range strings.Split(list, "\n") {
+		if strings.Contains(line, " name=limited ") {
+			id = strings.TrimPrefix(strings.Split(line, " ")[0], "id=")
+		}
+	}
+	if id == "" {
+		return fmt.Errorf("expected the client in '%s'", list)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CLIENT", "LIMIT", "999999", "COMMANDS", 1}, {"ERR No such client"},
+		{"CLIENT", "LIMIT", id, "COMMANDS", "x"}, {"ERR invalid argument 'x'"},
+		{"CLIENT", "LIMIT", id, "COMMANDS", 2}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if limited, err = countLimited(20); err != nil {
+		return err
+	} else if limited == 0 {
+		return errors.New("expected rejected commands")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CLIENT", "LIMIT", id, "COMMANDS", 0}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if limited, err = countLimited(20); err != nil {
+		return err
+	} else if limited != 0 {
+		return fmt.Errorf("expected no rejected commands, got %d", limited)
+	}
+
+	// CLIENT LIMIT can't raise a client above the config
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "client-commands-per-sec", 1000}, {"OK"},
+		{"CLIENT", "LIMIT", id, "COMMANDS", 1001}, {"ERR limit is above the configured client-commands-per-sec"},
+		{"CLIENT", "LIMIT", id, "COMMANDS", 2}, {"OK"},
+		{"CONFIG", "SET", "client-bytes-per-sec", 100000}, {"OK"},
+		{"CLIENT", "LIMIT", id, "BYTES", 100001}, {"ERR limit is above the configured client-bytes-per-sec"},
+	}); err != nil {
+		return err
+	}
+	limited, err = countLimited(20)
+	if err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CLIENT", "LIMIT", id, "COMMANDS", 0}, {"OK"},
+		{"CONFIG", "SET", "client-commands-per-sec", 0}, {"OK"},
+		{"CONFIG", "SET", "client-bytes-per-sec", 0}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if limited == 0 {
+		return errors.New("expected rejected commands")
+	}
+
+	// the heavy queries are limited for the whole server
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "max-heavy-queries", "x"}, {"ERR Invalid argument 'x' for CONFIG SET 'max-heavy-queries'"},
+		{"CONFIG", "SET", "max-heavy-queries", 1}, {"OK"},
+		{"CONFIG", "GET", "max-heavy-queries"}, {"[max-heavy-queries 1]"},
+		{"SCAN", "fleet", "COUNT"}, {"0"},
+		{"CONFIG", "SET", "max-heavy-queries", 0}, {"OK"},
+	}); err != nil {
+		return err
+	}
+
+	// the rejected commands are counted in the stats
+	info, err := redis.String(mc.Do("INFO", "stats"))
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(info, "rejected_commands_limit:") ||
+		strings.Contains(info, "rejected_commands_limit:0\r\n") {
+		return fmt.Errorf("expected rejected commands in '%s'", info)
+	}
+	return nil
+}
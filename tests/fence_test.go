@@ -2,12 +2,16 @@ package tests
 
 import (
 	"bufio"
+	"crypto/sha1"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +40,15 @@ func subTestFence(t *testing.T, mc *mockServer) {
 
 	// various
 	runStep(t, mc, "detect eecio", fence_eecio_test)
+
+	// functions
+	runStep(t, mc, "hook function", fence_hook_function_test)
+	runStep(t, mc, "channel function", fence_channel_function_test)
+	runStep(t, mc, "channel script", fence_channel_script_test)
+	runStep(t, mc, "function no block", fence_function_noblock_test)
+
+	// expiration
+	runStep(t, mc, "channel expire", fence_channel_expire_test)
 }
 
 type fenceReader struct {
@@ -517,3 +530,305 @@ func fence_eecio_test(mc *mockServer) error {
 
 	return nil
 }
+
+// fenceTestLibrary returns a library with the fence_transform function for
+// hooks, which sends the "other" events to the endpoint.
+func fenceTestLibrary(endpoint string) string {
+	return `#!lua name=fencetest
+tile38.register_function{function_name='fence_transform', flags={'no-writes'},
+callback=function(keys, args)
+	local event = json.decode(args[1])
+	if event.id == "skip" then
+		return nil
+	end
+	if event.id == "keep" then
+		return true
+	end
+	if event.id == "fail" then
+		error("failed")
+	end
+	local msg = {id = event.id, detect = event.detect, hook = args[2]}
+	if event.id == "other" then
+		return msg, "` + endpoint + `"
+	end
+	return msg
+end}
+tile38.register_function('fence_write', function(keys, args) return true end)
+`
+}
+
+func fence_hook_function_test(mc *mockServer) error {
+	bodies1 := make(chan string, 10)
+	bodies2 := make(chan string, 10)
+	newServer := func(bodies chan string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				bodies <- string(body)
+				fmt.Fprintln(w, "OK!")
+			},
+		))
+	}
+	ts1 := newServer(bodies1)
+	defer ts1.Close()
+	ts2 := newServer(bodies2)
+	defer ts2.Close()
+	if err := mc.DoBatch([][]interface{}{
+		{"FUNCTION", "LOAD", "REPLACE", fenceTestLibrary(ts2.URL)}, {"fencetest"},
+		{"SETHOOK", "scripted", ts1.URL, "FUNCTION", "nofunc", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"ERR function not found"},
+		{"SETHOOK", "scripted", ts1.URL, "FUNCTION", "fence_write", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"ERR function 'fence_write' must have the no-writes flag"},
+		{"SETHOOK", "scripted", ts1.URL, "FUNCTION", "fence_transform", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"1"},
+		{"FUNCTION", "DELETE", "fencetest"}, {"ERR function 'fence_transform' is used by hook 'scripted'"},
+		{"FUNCTION", "FLUSH"}, {"ERR function 'fence_transform' is used by hook 'scripted'"},
+		{"SET", "fleet", "skip", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "fail", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "other", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "keep", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	receive := func(bodies chan string) (string, error) {
+		select {
+		case body := <-bodies:
+			return body, nil
+		case <-time.After(time.Second * 5):
+			return "", errors.New("timeout waiting for hook")
+		}
+	}
+	body, err := receive(bodies1)
+	if err != nil {
+		return err
+	}
+	if gjson.Get(body, "id").String() != "truck" ||
+		gjson.Get(body, "hook").String() != "scripted" ||
+		gjson.Get(body, "object").Exists() {
+		return fmt.Errorf("unexpected message '%s'", body)
+	}
+	body, err = receive(bodies1)
+	if err != nil {
+		return err
+	}
+	if gjson.Get(body, "id").String() != "keep" ||
+		!gjson.Get(body, "object").Exists() {
+		return fmt.Errorf("unexpected message '%s'", body)
+	}
+	body, err = receive(bodies2)
+	if err != nil {
+		return err
+	}
+	if gjson.Get(body, "id").String() != "other" {
+		return fmt.Errorf("unexpected message '%s'", body)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELHOOK", "scripted"}, {"1"},
+		{"FUNCTION", "DELETE", "fencetest"}, {"OK"},
+		{"DROP", "fleet"}, {"1"},
+	})
+}
+
+func fence_channel_function_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"FUNCTION", "LOAD", "REPLACE", fenceTestLibrary("")}, {"fencetest"},
+		{"SETCHAN", "scripted", "FUNCTION", "nofunc", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"ERR function not found"},
+		{"SETCHAN", "scripted", "FUNCTION", "fence_transform", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"1"},
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"CHANS", "scripted"}, {
+			func(v interface{}) (resp, expect interface{}) {
+				return gjson.Get(v.(string), "chans.0.function").String(),
+					"fence_transform"
+			},
+		},
+		{"OUTPUT", "resp"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.Subscribe("scripted"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "skip", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "fail", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+	case redis.Message:
+		if gjson.Get(string(v.Data), "id").String() != "truck" ||
+			gjson.Get(string(v.Data), "object").Exists() {
+			return fmt.Errorf("unexpected message '%s'", v.Data)
+		}
+	case error:
+		return v
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELCHAN", "scripted"}, {"1"},
+		{"FUNCTION", "DELETE", "fencetest"}, {"OK"},
+		{"DROP", "fleet"}, {"1"},
+	})
+}
+
+func fence_channel_script_test(mc *mockServer) error {
+	script := `local event = json.decode(ARGV[1])
+if event.id == "skip" then
+	return nil
+end
+return {id = event.id, hook = ARGV[2], key = KEYS[1]}`
+	sha := fmt.Sprintf("%x", sha1.Sum([]byte(script)))
+	if err := mc.DoBatch([][]interface{}{
+		{"FUNCTION", "LOAD", "REPLACE", fenceTestLibrary("")}, {"fencetest"},
+		{"SETCHAN", "scripted", "SCRIPT", "nosha", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"ERR invalid argument 'nosha'"},
+		{"SETCHAN", "scripted", "FUNCTION", "fence_transform", "SCRIPT", sha,
+			"NEARBY", "fleet", "FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"ERR FUNCTION and SCRIPT can't be used together"},
+		// the script doesn't need to be loaded yet
+		{"SETCHAN", "scripted", "SCRIPT", sha, "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"1"},
+		{"SCRIPT", "LOAD", script}, {sha},
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"CHANS", "scripted"}, {
+			func(v interface{}) (resp, expect interface{}) {
+				return gjson.Get(v.(string), "chans.0.script").String(), sha
+			},
+		},
+		{"OUTPUT", "resp"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.Subscribe("scripted"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "skip", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+	case redis.Message:
+		if gjson.Get(string(v.Data), "id").String() != "truck" ||
+			gjson.Get(string(v.Data), "hook").String() != "scripted" ||
+			gjson.Get(string(v.Data), "key").String() != "fleet" {
+			return fmt.Errorf("unexpected message '%s'", v.Data)
+		}
+	case error:
+		return v
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELCHAN", "scripted"}, {"1"},
+		{"FUNCTION", "DELETE", "fencetest"}, {"OK"},
+		{"DROP", "fleet"}, {"1"},
+	})
+}
+
+// fence_function_noblock_test runs hook functions that use all of their
+// time on each event, which must not hold up the writes.
+func fence_function_noblock_test(mc *mockServer) error {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintln(w, "OK!")
+		},
+	))
+	defer ts.Close()
+	if err := mc.DoBatch([][]interface{}{
+		{"FUNCTION", "LOAD", "REPLACE", `#!lua name=slowtest
+tile38.register_function{function_name='slow', flags={'no-writes'},
+callback=function(keys, args)
+	tile38.call('get', keys[1], 'truck0')
+	while true do end
+end}`}, {"slowtest"},
+		{"SETCHAN", "slowchan", "FUNCTION", "slow", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"1"},
+		{"SETHOOK", "slowhook", ts.URL, "FUNCTION", "slow", "NEARBY", "fleet",
+			"FENCE", "DETECT", "inside", "POINT", 33, -115, 10000}, {"1"},
+	}); err != nil {
+		return err
+	}
+	// each event has 100ms, so the writes would take at least 2 seconds
+	// if they waited on the functions. The functions still share the CPU
+	// with the writes.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := mc.DoBatch([][]interface{}{
+			{"SET", "fleet", fmt.Sprintf("truck%d", i), "POINT", 33, -115}, {"OK"},
+		}); err != nil {
+			return err
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*3/2 {
+		return fmt.Errorf("writes took %s", elapsed)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELCHAN", "slowchan"}, {"1"},
+		{"DELHOOK", "slowhook"}, {"1"},
+		{"FUNCTION", "DELETE", "slowtest"}, {"OK"},
+	})
+}
+
+func fence_channel_expire_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"SETCHAN", "expiring", "NEARBY", "fleet", "FENCE", "DETECT", "exit",
+			"POINT", 33, -115, 10000}, {"1"},
+		{"SET", "fleet", "far", "POINT", 10, 10}, {"OK"},
+		{"SET", "fleet", "truck", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.Subscribe("expiring"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"EXPIRE", "fleet", "far", 0.2}, {1},
+		{"EXPIRE", "fleet", "truck", 0.5}, {1},
+	}); err != nil {
+		return err
+	}
+	switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+	case redis.Message:
+		res := gjson.ParseBytes(v.Data)
+		if res.Get("command").String() != "expire" ||
+			res.Get("detect").String() != "exit" ||
+			res.Get("id").String() != "truck" {
+			return fmt.Errorf("unexpected message '%s'", v.Data)
+		}
+	case error:
+		return v
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELCHAN", "expiring"}, {"1"},
+		{"GET", "fleet", "truck"}, {nil},
+	})
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tidwall/tile38/internal/hservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func subTestGRPC(t *testing.T, mc *mockServer) {
+	runStep(t, mc, "objects", grpc_objects_test)
+	runStep(t, mc, "search", grpc_search_test)
+	runStep(t, mc, "fence", grpc_fence_test)
+	runStep(t, mc, "subscribe", grpc_subscribe_test)
+}
+
+// grpcClient connects to the gRPC API of the mock server.
+func (mc *mockServer) grpcClient() (hservice.Tile38Client, func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", mc.grpcPort),
+		grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, nil, err
+	}
+	return hservice.NewTile38Client(conn), func() { conn.Close() }, nil
+}
+
+func grpcExpectCode(err error, code codes.Code) error {
+	if status.Code(err) != code {
+		return fmt.Errorf("expected code %v, got '%v'", code, err)
+	}
+	return nil
+}
+
+func grpc_objects_test(mc *mockServer) error {
+	c, close, err := mc.grpcClient()
+	if err != nil {
+		return err
+	}
+	defer close()
+	ctx := context.Background()
+	if _, err := c.Set(ctx, &hservice.SetRequest{
+		Key: "fleet", Id: "truck1",
+		Point:  &hservice.Point{Lat: 33, Lon: -115},
+		Fields: []*hservice.Field{{Name: "speed", Value: 50}},
+	}); err != nil {
+		return err
+	}
+	_, err = c.Set(ctx, &hservice.SetRequest{
+		Key: "fleet", Id: "truck1", Object: `{"type":"Point","coordinates":[-115,33]}`,
+		Nx: true,
+	})
+	if err := grpcExpectCode(err, codes.AlreadyExists); err != nil {
+		return err
+	}
+	_, err = c.Set(ctx, &hservice.SetRequest{Key: "fleet", Id: "truck2"})
+	if err := grpcExpectCode(err, codes.InvalidArgument); err != nil {
+		return err
+	}
+	get, err := c.Get(ctx, &hservice.GetRequest{
+		Key: "fleet", Id: "truck1", WithFields: true,
+	})
+	if err != nil {
+		return err
+	}
+	if get.Object.Object != `{"type":"Point","coordinates":[-115,33]}` ||
+		len(get.Object.Fields) != 1 || get.Object.Fields[0].Name != "speed" ||
+		get.Object.Fields[0].Value != 50 {
+		return fmt.Errorf("unexpected object %v", get.Object)
+	}
+	_, err = c.Get(ctx, &hservice.GetRequest{Key: "fleet", Id: "truck2"})
+	if err := grpcExpectCode(err, codes.NotFound); err != nil {
+		return err
+	}
+	del, err := c.Del(ctx, &hservice.DelRequest{Key: "fleet", Id: "truck1"})
+	if err != nil {
+		return err
+	}
+	if !del.Deleted {
+		return fmt.Errorf("expected deleted")
+	}
+	del, err = c.Del(ctx, &hservice.DelRequest{Key: "fleet", Id: "truck1"})
+	if err != nil {
+		return err
+	}
+	if del.Deleted {
+		return fmt.Errorf("expected not deleted")
+	}
+	return nil
+}
+
+func grpc_search_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck1", "FIELD", "speed", 10, "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck2", "FIELD", "speed", 50, "POINT", 33.01, -115.01}, {"OK"},
+		{"SET", "fleet", "truck3", "POINT", 34, -116}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	c, close, err := mc.grpcClient()
+	if err != nil {
+		return err
+	}
+	defer close()
+	ctx := context.Background()
+	ids := func(reply *hservice.SearchReply) string {
+		var ids string
+		for _, obj := range reply.Objects {
+			ids += "[" + obj.Id + "]"
+		}
+		return ids
+	}
+	for i, step := range []struct {
+		search func() (*hservice.SearchReply, error)
+		expect string
+	}{
+		{func() (*hservice.SearchReply, error) {
+			return c.Nearby(ctx, &hservice.NearbyRequest{Key: "fleet",
+				Point: &hservice.Point{Lat: 33, Lon: -115}, Meters: 5000})
+		}, "[truck1][truck2]"},
+		{func() (*hservice.SearchReply, error) {
+			return c.Nearby(ctx, &hservice.NearbyRequest{Key: "fleet",
+				Point: &hservice.Point{Lat: 33, Lon: -115}, Meters: 5000,
+				Options: &hservice.SearchOptions{Where: []*hservice.Where{
+					{Field: "speed", Min: 20, Max: 100},
+				}}})
+		}, "[truck2]"},
+		{func() (*hservice.SearchReply, error) {
+			return c.Within(ctx, &hservice.AreaRequest{Key: "fleet",
+				Bounds: &hservice.Bounds{MinLat: 32.9, MinLon: -115.1,
+					MaxLat: 33.1, MaxLon: -114.9}})
+		}, "[truck1][truck2]"},
+		{func() (*hservice.SearchReply, error) {
+			return c.Intersects(ctx, &hservice.AreaRequest{Key: "fleet",
+				Object: `{"type":"Polygon","coordinates":[[[-117,33.5],[-115.5,33.5],[-115.5,34.5],[-117,34.5],[-117,33.5]]]}`})
+		}, "[truck3]"},
+		{func() (*hservice.SearchReply, error) {
+			return c.Scan(ctx, &hservice.ScanRequest{Key: "fleet",
+				Options: &hservice.SearchOptions{Match: "truck[13]"}})
+		}, "[truck1][truck3]"},
+	} {
+		reply, err := step.search()
+		if err != nil {
+			return fmt.Errorf("step[%d]: %v", i, err)
+		}
+		if ids(reply) != step.expect {
+			return fmt.Errorf("step[%d]: expected '%s', got '%s'",
+				i, step.expect, ids(reply))
+		}
+	}
+	reply, err := c.Scan(ctx, &hservice.ScanRequest{Key: "fleet",
+		Options: &hservice.SearchOptions{Limit: 1}})
+	if err != nil {
+		return err
+	}
+	if reply.Cursor != 1 || len(reply.Objects) != 1 ||
+		len(reply.Objects[0].Fields) != 1 ||
+		reply.Objects[0].Fields[0].Name != "speed" ||
+		reply.Objects[0].Fields[0].Value != 10 {
+		return fmt.Errorf("unexpected reply %v", reply)
+	}
+	_, err = c.Within(ctx, &hservice.AreaRequest{Key: "fleet"})
+	if err := grpcExpectCode(err, codes.InvalidArgument); err != nil {
+		return err
+	}
+	_, err = mc.Do("DROP", "fleet")
+	return err
+}
+
+func grpc_fence_test(mc *mockServer) error {
+	c, close, err := mc.grpcClient()
+	if err != nil {
+		return err
+	}
+	defer close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.Fence(ctx, &hservice.FenceRequest{
+		Key: "fleet", Command: "nearby",
+		Point: &hservice.Point{Lat: 33, Lon: -115}, Meters: 1000,
+		Detect: []string{"enter", "exit"},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Header(); err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck1", "POINT", 34, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	for _, detect := range []string{"enter", "exit"} {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if event.Command != "set" || event.Detect != detect ||
+			event.Key != "fleet" || event.Id != "truck1" || event.Json == "" {
+			return fmt.Errorf("unexpected event %v", event)
+		}
+	}
+	_, err = mc.Do("DROP", "fleet")
+	return err
+}
+
+func grpc_subscribe_test(mc *mockServer) error {
+	c, close, err := mc.grpcClient()
+	if err != nil {
+		return err
+	}
+	defer close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.Subscribe(ctx, &hservice.SubscribeRequest{
+		Channels: []string{"chan1"}, Patterns: []string{"pat*"},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Header(); err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"PUBLISH", "chan1", "hello"}, {1},
+		{"PUBLISH", "pattern", "world"}, {1},
+	}); err != nil {
+		return err
+	}
+	for _, expect := range []string{"chan1::hello", "pattern:pat*:world"} {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		got := msg.Channel + ":" + msg.Pattern + ":" + msg.Message
+		if got != expect {
+			return fmt.Errorf("expected '%s', got '%s'", expect, got)
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"testing"
 )
 
@@ -19,6 +20,11 @@ func subTestSearch(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "SEARCH_CURSOR", keys_SEARCH_CURSOR_test)
 	runStep(t, mc, "MATCH", keys_MATCH_test)
 	runStep(t, mc, "FIELDS", keys_FIELDS_search_test)
+	runStep(t, mc, "ORDER_BY", keys_ORDER_BY_test)
+	runStep(t, mc, "FIELD_INDEX", keys_FIELD_INDEX_test)
+	runStep(t, mc, "WHERE_EXPR", keys_WHERE_EXPR_test)
+	runStep(t, mc, "WHEREJSON", keys_WHEREJSON_test)
+	runStep(t, mc, "BUFFER", keys_BUFFER_test)
 }
 
 func keys_KNN_test(mc *mockServer) error {
@@ -429,3 +435,139 @@ func match(expectIn string) func(org, v interface{}) (resp, expect interface{})
 		return fmt.Sprintf("%v", org), expectIn
 	}
 }
+
+func keys_ORDER_BY_test(mc *mockServer) error {
+	return mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "a", "FIELD", "battery", 40, "POINT", 33, -115}, {"OK"},
+		{"SET", "mykey", "b", "FIELD", "battery", 10, "POINT", 33.1, -115}, {"OK"},
+		{"SET", "mykey", "c", "FIELD", "battery", 90, "POINT", 33.2, -115}, {"OK"},
+		{"SET", "mykey", "d", "FIELD", "battery", 10, "POINT", 33.3, -115}, {"OK"},
+		{"SET", "mykey", "e", "POINT", 40, -100}, {"OK"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "IDS"}, {"[0 [e b d a c]]"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "DESC", "IDS"}, {"[0 [c a d b e]]"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "LIMIT", 2, "IDS"}, {"[2 [e b]]"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "CURSOR", 2, "LIMIT", 2, "IDS"}, {"[4 [d a]]"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "CURSOR", 4, "LIMIT", 2, "IDS"}, {"[0 [c]]"},
+		{"SCAN", "mykey", "ORDER", "BY", "battery", "WHERE", "battery", 20, 100, "IDS"}, {"[0 [a c]]"},
+		{"WITHIN", "mykey", "ORDER", "BY", "battery", "DESC", "IDS", "BOUNDS", 32, -116, 34, -114}, {"[0 [c a d b]]"},
+		{"INTERSECTS", "mykey", "ORDER", "BY", "battery", "LIMIT", 3, "IDS", "BOUNDS", 32, -116, 34, -114}, {"[3 [b d a]]"},
+		{"WITHIN", "mykey", "DESC", "IDS", "BOUNDS", 32, -116, 34, -114}, {"ERR DESC is not allowed for WITHIN"},
+		{"NEARBY", "mykey", "ORDER", "BY", "battery", "IDS", "POINT", 33, -115}, {"ERR ORDER BY is not allowed for NEARBY"},
+		{"SCAN", "mykey", "ORDER", "battery", "IDS"}, {"ERR invalid argument 'battery'"},
+	})
+}
+
+func keys_FIELD_INDEX_test(mc *mockServer) error {
+	var cmds [][]interface{}
+	for i := 0; i < 20; i++ {
+		cmds = append(cmds, []interface{}{"SET", "mykey", fmt.Sprintf("id%02d", 19-i),
+			"FIELD", "battery", i, "POINT", 33, -115}, []interface{}{"OK"})
+	}
+	cmds = append(cmds, [][]interface{}{
+		{"CREATEINDEX", "nokey", "battery"}, {"ERR key not found"},
+		{"CREATEINDEX", "mykey", "battery"}, {"1"},
+		{"CREATEINDEX", "mykey", "battery"}, {"0"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "IDS"}, {"[0 [id16 id17 id18 id19]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, "(3", "DESC", "IDS"}, {"[0 [id19 id18 id17]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "LIMIT", 2, "IDS"}, {"[" + indexCursor(2) + " [id16 id17]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "LIMIT", 2, "IDS"}, {"[" + indexCursor(4) + " [id18 id19]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 10, "LIMIT", 2, "IDS"}, {"[11 [id09 id10]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 10, "CURSOR", 11, "LIMIT", 2, "IDS"}, {"[13 [id11 id12]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", 2, "LIMIT", 2, "IDS"}, {"[18 [id16 id17]]"},
+		{"WITHIN", "mykey", "WHERE", "battery", 0, 3, "LIMIT", 2, "IDS", "BOUNDS", 32, -116, 34, -114}, {"[" + indexCursor(2) + " [id16 id17]]"},
+		{"WITHIN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "IDS", "BOUNDS", 32, -116, 34, -114}, {"[0 [id18 id19]]"},
+		{"INTERSECTS", "mykey", "WHERE", "battery", 0, 3, "IDS", "BOUNDS", 34, -116, 35, -114}, {"[0 []]"},
+		{"NEARBY", "mykey", "WHERE", "battery", 0, 3, "IDS", "POINT", 33, -115}, {"[0 [id16 id17 id18 id19]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "MATCH", "id1[89]", "IDS"}, {"[0 [id18 id19]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "COUNT"}, {"4"},
+		{"SCAN", "mykey", "WHERE", "battery", 10, 19, "COUNT"}, {"10"},
+		{"FSET", "mykey", "id00", "battery", 1}, {"1"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 1, "IDS"}, {"[0 [id00 id18 id19]]"},
+		{"DEL", "mykey", "id19"}, {"1"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 1, "IDS"}, {"[0 [id00 id18]]"},
+		{"DROPINDEX", "mykey", "battery"}, {"1"},
+		{"DROPINDEX", "mykey", "battery"}, {"0"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 1, "IDS"}, {"[0 [id00 id18]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "IDS"}, {"ERR invalid cursor, the search no longer uses a field index"},
+		{"WITHIN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "IDS", "BOUNDS", 32, -116, 34, -114}, {"ERR invalid cursor, the search no longer uses a field index"},
+		{"NEARBY", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "IDS", "POINT", 33, -115}, {"ERR invalid cursor, the search no longer uses a field index"},
+		{"SEARCH", "mykey", "CURSOR", indexCursor(2), "IDS"}, {"ERR invalid cursor, the search no longer uses a field index"},
+	}...)
+	if err := mc.DoBatch(cmds); err != nil {
+		return err
+	}
+
+	// a cursor of the index keeps paging through the index, also when the
+	// range is no longer selective enough to start a new search with it
+	cmds = [][]interface{}{
+		{"CREATEINDEX", "mykey", "battery"}, {"1"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "LIMIT", 2, "IDS"}, {"[" + indexCursor(2) + " [id00 id16]]"},
+	}
+	for i := 0; i < 5; i++ {
+		cmds = append(cmds, []interface{}{"SET", "mykey", fmt.Sprintf("id%02d", 20+i),
+			"FIELD", "battery", 2, "POINT", 33, -115}, []interface{}{"OK"})
+	}
+	cmds = append(cmds, [][]interface{}{
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "CURSOR", indexCursor(2), "LIMIT", 3, "IDS"}, {"[" + indexCursor(5) + " [id17 id18 id20]]"},
+		{"SCAN", "mykey", "WHERE", "battery", 0, 3, "LIMIT", 3, "IDS"}, {"[18 [id00 id16 id17]]"},
+		{"SCAN", "mykey", "CURSOR", indexCursor(2), "LIMIT", 3, "IDS"}, {"ERR invalid cursor, the search no longer uses a field index"},
+	}...)
+	return mc.DoBatch(cmds)
+}
+
+// indexCursor returns the cursor of a search that used a field index.
+func indexCursor(offset int) string {
+	return strconv.FormatUint(1<<62|uint64(offset), 10)
+}
+
+func keys_WHERE_EXPR_test(mc *mockServer) error {
+	return mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "truck1", "FIELD", "speed", 0, "FIELD", "battery", 80, "POINT", 33, -115}, {"OK"},
+		{"SET", "mykey", "truck2", "FIELD", "speed", 55, "FIELD", "battery", 15, "POINT", 33.1, -115}, {"OK"},
+		{"SET", "mykey", "bus1", "FIELD", "speed", 30, "FIELD", "battery", 90, "POINT", 33.2, -115}, {"OK"},
+		{"SET", "mykey", "bus2", "FIELD", "speed", 5, "FIELD", "battery", 10, "POINT", 33.3, -115}, {"OK"},
+		{"SCAN", "mykey", "WHERE", "speed > 10", "IDS"}, {"[0 [bus1 truck2]]"},
+		{"SCAN", "mykey", "WHERE", "speed > 10 && (startsWith(id, 'truck') || battery < 20)", "IDS"}, {"[0 [truck2]]"},
+		{"SCAN", "mykey", "WHERE", "speed < 10 or battery < 20", "WHERE", "id != 'bus2'", "IDS"}, {"[0 [truck1 truck2]]"},
+		{"SCAN", "mykey", "WHERE", "speed in (0, 5)", "COUNT"}, {"2"},
+		{"SCAN", "mykey", "WHERE", "!(speed > 10)", "WHERE", "battery", 50, 100, "IDS"}, {"[0 [truck1]]"},
+		{"WITHIN", "mykey", "WHERE", "battery >= 80", "COUNT", "BOUNDS", 32, -116, 34, -114}, {"2"},
+		{"NEARBY", "mykey", "WHERE", "id == 'bus2'", "IDS", "POINT", 33, -115}, {"[0 [bus2]]"},
+		{"SCAN", "mykey", "WHERE", "speed >", "IDS"}, {"ERR invalid expression: unexpected end of expression"},
+	})
+}
+
+func keys_WHEREJSON_test(mc *mockServer) error {
+	return mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "1", "OBJECT", `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,33]},"properties":{"type":"depot","capacity":40}}`}, {"OK"},
+		{"SET", "mykey", "2", "OBJECT", `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,33.1]},"properties":{"type":"store","capacity":5}}`}, {"OK"},
+		{"SET", "mykey", "3", "OBJECT", `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,33.2]},"properties":{"type":"depot","capacity":100}}`}, {"OK"},
+		{"SET", "mykey", "4", "POINT", 33.3, -115}, {"OK"},
+		{"SCAN", "mykey", "WHEREJSON", "properties.type", "==", "depot", "IDS"}, {"[0 [1 3]]"},
+		{"SCAN", "mykey", "WHEREJSON", "properties.type", "!=", "depot", "IDS"}, {"[0 [2 4]]"},
+		{"SCAN", "mykey", "WHEREJSON", "properties.capacity", ">", 10, "WHEREJSON", "properties.capacity", "<=", 40, "IDS"}, {"[0 [1]]"},
+		{"SCAN", "mykey", "WHEREJSON", "properties.capacity", ">=", 5, "COUNT"}, {"3"},
+		{"INTERSECTS", "mykey", "WHEREJSON", "properties.type", "==", "store", "IDS", "BOUNDS", 32, -116, 34, -114}, {"[0 [2]]"},
+		{"NEARBY", "mykey", "WHEREJSON", "properties.type", "==", "depot", "IDS", "POINT", 33.3, -115}, {"[0 [3 1]]"},
+		{"SCAN", "mykey", "WHEREJSON", "properties.type", "~", "depot", "IDS"}, {"ERR invalid argument '~'"},
+	})
+}
+
+func keys_BUFFER_test(mc *mockServer) error {
+	route := `{"type":"LineString","coordinates":[[-115,33],[-114.99,33],[-114.99,33.01]]}`
+	return mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "1", "POINT", 33.001, -114.995}, {"OK"},
+		{"SET", "mykey", "2", "POINT", 33.003, -114.995}, {"OK"},
+		{"SET", "mykey", "3", "POINT", 33.005, -114.9885}, {"OK"},
+		{"SET", "mykey", "4", "POINT", 32.9985, -115.0005}, {"OK"},
+		{"SET", "routes", "r1", "OBJECT", route}, {"OK"},
+		{"WITHIN", "mykey", "IDS", "OBJECT", route, "BUFFER", 200}, {"[0 [1 3 4]]"},
+		{"WITHIN", "mykey", "IDS", "OBJECT", route, "BUFFER", 400}, {"[0 [1 2 3 4]]"},
+		{"INTERSECTS", "mykey", "IDS", "GET", "routes", "r1", "BUFFER", 200}, {"[0 [1 3 4]]"},
+		{"WITHIN", "mykey", "IDS", "CIRCLE", 33.001, -114.995, 100, "BUFFER", 150}, {"[0 [1 2]]"},
+		{"WITHIN", "mykey", "IDS", "BOUNDS", 33.0005, -114.996, 33.0015, -114.994, "BUFFER", 50}, {"[0 [1]]"},
+		{"WITHIN", "mykey", "IDS", "OBJECT", route, "BUFFER", -1}, {"ERR invalid argument '-1'"},
+		{"WITHIN", "mykey", "IDS", "OBJECT", route, "BUFFER"}, {"ERR wrong number of arguments for 'within' command"},
+		{"NEARBY", "mykey", "IDS", "POINT", 33, -115, "BUFFER", 100}, {"ERR invalid argument 'BUFFER'"},
+	})
+}
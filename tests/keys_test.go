@@ -3,8 +3,11 @@ package tests
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -33,6 +36,14 @@ func subTestKeys(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "FIELDS", keys_FIELDS_test)
 	runStep(t, mc, "WHEREIN", keys_WHEREIN_test)
 	runStep(t, mc, "WHEREEVAL", keys_WHEREEVAL_test)
+	runStep(t, mc, "IMPORT", keys_IMPORT_test)
+	runStep(t, mc, "EXPORT", keys_EXPORT_test)
+	runStep(t, mc, "MULTI", keys_MULTI_test)
+	runStep(t, mc, "IFNEWER", keys_IFNEWER_test)
+	runStep(t, mc, "NOTIFY", keys_NOTIFY_test)
+	runStep(t, mc, "EVICT", keys_EVICT_test)
+	runStep(t, mc, "KEYCONFIG", keys_KEYCONFIG_test)
+	runStep(t, mc, "HISTORY", keys_HISTORY_test)
 }
 
 func keys_BOUNDS_test(mc *mockServer) error {
@@ -65,6 +76,33 @@ func keys_DROP_test(mc *mockServer) error {
 		{"SCAN", "mykey", "COUNT"}, {0},
 		{"DROP", "mykey"}, {0},
 		{"SCAN", "mykey", "COUNT"}, {0},
+		{"SET", "mykey", "myid1", "HASH", "9my5xp7"}, {"OK"},
+		{"SET", "mykey", "myid2", "HASH", "9my5xp8"}, {"OK"},
+		{"DROP", "mykey", "ASYNC"}, {1},
+		{"SCAN", "mykey", "COUNT"}, {0},
+		{"DROP", "mykey", "ASYNC"}, {0},
+		{"DROP", "mykey", "ASYNC", "NOW"}, {"ERR wrong number of arguments for 'drop' command"},
+		{"SET", "mykey1", "myid1", "HASH", "9my5xp7"}, {"OK"},
+		{"SET", "mykey2", "myid1", "HASH", "9my5xp7"}, {"OK"},
+		{"FLUSHDB", "ASYNC"}, {"OK"},
+		{"KEYS", "*"}, {"[]"},
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"SERVER"}, {
+			func(v interface{}) (resp, expect interface{}) {
+				// the objects are freed in the background
+				for i := 0; i < 100; i++ {
+					stats := gjson.Get(v.(string), "stats")
+					if stats.Get("lazyfree_pending_objects").Int() == 0 &&
+						stats.Get("lazyfreed_objects").Int() >= 4 {
+						return "freed", "freed"
+					}
+					time.Sleep(time.Millisecond * 10)
+					v, _ = redis.String(mc.Do("SERVER"))
+				}
+				return v, "all objects freed"
+			},
+		},
+		{"OUTPUT", "resp"}, {"OK"},
 	})
 }
 func keys_RENAME_test(mc *mockServer) error {
@@ -408,3 +446,578 @@ func keys_WHEREEVAL_test(mc *mockServer) error {
 		{"WITHIN", "mykey", "WHEREEVAL", "return FIELDS.a > tonumber(ARGV[1]) and FIELDS.a ~= tonumber(ARGV[2])", 2, 0.5, 3, "BOUNDS", 32.8, -115.2, 33.2, -114.8}, {`[0 [[myid_a1 {"type":"Point","coordinates":[-115,33]} [a 1]] [myid_a2 {"type":"Point","coordinates":[-115,32.99]} [a 2]]]]`},
 	})
 }
+
+func keys_IMPORT_test(mc *mockServer) error {
+	dir := filepath.Join(mc.dir, "files")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(mc.dir, "outside.csv"), []byte("id,lat,lon\no1,33,-115\n"), 0666); err != nil {
+		return err
+	}
+	defer os.Remove(filepath.Join(mc.dir, "outside.csv"))
+	outside, err := filepath.Abs(filepath.Join(mc.dir, "outside.csv"))
+	if err != nil {
+		return err
+	}
+	files := map[string]string{
+		"fc.geojson": `{
+			"type": "FeatureCollection",
+			"features": [
+				{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[-115,33]},"properties":{"speed":10}},
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,34]},"properties":{"speed":20}},
+				{"type":"Feature","id":"c","geometry":{"type":"Point","coordinates":[-115,35]},"properties":{"speed":"fast"}},
+				{"type":"Feature","id":"d","geometry":{"type":"Point","coordinates":[-115,36]},"properties":{"name":"x]}"}}
+			]
+		}`,
+		"lines.ndjson": `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,33]},"properties":{"ref":"n1"}}
+
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,34]},"properties":{"ref":"n2","speed":5}}
+{"type":"Feature","geometry":{"type":"Nope"},"properties":{"ref":"n3"}}
+`,
+		"rows.csv": "id,lat,lon,speed,name\nr1,33,-115,10,\nr2,34,-115,20,truck\nr3,x,-115,30,\n",
+		"data.txt": "",
+		"broken.geojson": `{"type":"FeatureCollection","features":[
+			{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[-115,33]}},
+			{"type":"Feature","id":"b","geometry":{"type":"Point","coordinates":[-115,34]}},
+			x`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666); err != nil {
+			return err
+		}
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"IMPORT", "fc", "FROM", "fc.geojson", "FIELDS", "speed"}, {"[2 2 [line 5: missing id line 6: invalid field 'speed']]"},
+		{"SCAN", "fc", "IDS"}, {"[0 [a d]]"},
+		{"GET", "fc", "a", "WITHFIELDS", "POINT"}, {"[[33 -115] [speed 10]]"},
+		{"IMPORT", "nd", "FROM", "lines.ndjson", "IDFIELD", "ref", "FIELDS", "speed"}, {"[2 1 [line 4: type 'Nope' is unknown]]"},
+		{"SCAN", "nd", "IDS"}, {"[0 [n1 n2]]"},
+		{"GET", "nd", "n2", "WITHFIELDS", "POINT"}, {"[[34 -115] [speed 5]]"},
+		{"IMPORT", "rows", "FROM", "rows.csv", "FIELDS", "speed"}, {"[2 1 [line 4: invalid argument 'x']]"},
+		{"GET", "rows", "r1", "WITHFIELDS"}, {`[{"type":"Point","coordinates":[-115,33]} [speed 10]]`},
+		{"GET", "rows", "r2"}, {`{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,34]},"properties":{"name":"truck"}}`},
+		{"IMPORT", "x", "FROM", "data.txt"}, {"ERR unknown file format"},
+		{"IMPORT", "x", "FROM", "missing.csv"}, {"ERR file not found"},
+		{"IMPORT", "x", "FROM", outside}, {"ERR path must be relative to the files directory"},
+		{"IMPORT", "x", "FROM", "../outside.csv"}, {"ERR path must be relative to the files directory"},
+		{"SCAN", "x", "IDS"}, {"[0 []]"},
+		// the objects before the error stay imported
+		{"IMPORT", "x", "FROM", "broken.geojson"}, {"ERR import stopped after 2 objects: unexpected EOF"},
+		{"SCAN", "x", "IDS"}, {"[0 [a b]]"},
+		{"IMPORT", "x", "FROM"}, {"ERR wrong number of arguments for 'import' command"},
+	}); err != nil {
+		return err
+	}
+
+	// the progress is in the stats
+	info, err := redis.String(mc.Do("INFO", "stats"))
+	if err != nil {
+		return err
+	}
+	for _, stat := range []string{
+		"imports_in_progress:0\r\n", "imported_lines:", "imported_objects:",
+	} {
+		if !strings.Contains(info, stat) {
+			return fmt.Errorf("expected '%s' in '%s'", stat, info)
+		}
+	}
+	if strings.Contains(info, "imported_objects:0\r\n") {
+		return fmt.Errorf("expected imported objects in '%s'", info)
+	}
+	return nil
+}
+
+func keys_EXPORT_test(mc *mockServer) error {
+	dir, err := filepath.Abs(filepath.Join(mc.dir, "files"))
+	if err != nil {
+		return err
+	}
+	file := func(name string) string { return filepath.Join(dir, name) }
+	outside := filepath.Join(filepath.Dir(dir), "outside.csv")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Symlink("..", file("up")); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	err = mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "1", "FIELD", "speed", 10, "POINT", 33, -115}, {"OK"},
+		{"SET", "mykey", "2", "OBJECT", `{"type":"LineString","coordinates":[[-115,33],[-116,34]]}`}, {"OK"},
+		{"SET", "mykey", "3", "FIELD", "speed", 30, "OBJECT", `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,35]},"properties":{"name":"x"}}`}, {"OK"},
+		{"SET", "mykey", "4", "STRING", "hello"}, {"OK"},
+		{"EXPORT", "mykey", "TO", "all.geojson", "FORMAT", "geojson"}, {3},
+		{"EXPORT", "mykey", "TO", "fast.ndjson", "FORMAT", "ndjson", "WHERE", "speed", 20, "+inf"}, {1},
+		{"EXPORT", "mykey", "TO", "all.csv", "FORMAT", "csv"}, {3},
+		{"EXPORT", "mykey", "TO", "x.kml", "FORMAT", "kml"}, {"ERR invalid argument 'kml'"},
+		{"EXPORT", "mykey", "TO", "x.csv", "FORMAT", "csv", "LIMIT", 1}, {"ERR only WHERE and MATCH filters are allowed for EXPORT"},
+		{"EXPORT", "nokey", "TO", "x.csv", "FORMAT", "csv"}, {"ERR key not found"},
+		{"EXPORT", "mykey", "TO", outside, "FORMAT", "csv"}, {"ERR path must be relative to the files directory"},
+		{"EXPORT", "mykey", "TO", "../outside.csv", "FORMAT", "csv"}, {"ERR path must be relative to the files directory"},
+		{"EXPORT", "mykey", "TO", "a/../../outside.csv", "FORMAT", "csv"}, {"ERR path must be relative to the files directory"},
+		{"EXPORT", "mykey", "TO", "up/outside.csv", "FORMAT", "csv"}, {"ERR path must be relative to the files directory"},
+	})
+	if err != nil {
+		return err
+	}
+	expect := map[string]string{
+		"all.geojson": `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,33]},"properties":{"speed":10},"id":"1"},
+{"type":"Feature","geometry":{"type":"LineString","coordinates":[[-115,33],[-116,34]]},"properties":{},"id":"2"},
+{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,35]},"properties":{"name":"x","speed":30},"id":"3"}
+]}
+`,
+		"fast.ndjson": `{"type":"Feature","geometry":{"type":"Point","coordinates":[-115,35]},"properties":{"name":"x","speed":30},"id":"3"}
+`,
+		"all.csv": `id,lat,lon,speed,geojson
+1,33,-115,10,
+2,,,,"{""type"":""LineString"",""coordinates"":[[-115,33],[-116,34]]}"
+3,,,30,"{""type"":""Feature"",""geometry"":{""type"":""Point"",""coordinates"":[-115,35]},""properties"":{""name"":""x""}}"
+`,
+	}
+	for name, data := range expect {
+		b, err := ioutil.ReadFile(file(name))
+		if err != nil {
+			return err
+		}
+		if string(b) != data {
+			return fmt.Errorf("%s: expected '%s', got '%s'", name, data, b)
+		}
+	}
+	if _, err := os.Stat(outside); !os.IsNotExist(err) {
+		return fmt.Errorf("expected no file outside of the files directory")
+	}
+	return mc.DoBatch([][]interface{}{
+		{"IMPORT", "copy", "FROM", "all.csv", "FIELDS", "speed"}, {"[3 0 []]"},
+		{"SCAN", "copy", "WHERE", "speed", 20, "+inf", "IDS"}, {"[0 [3]]"},
+		{"IMPORT", "copy2", "FROM", "all.geojson", "FIELDS", "speed"}, {"[3 0 []]"},
+		{"GET", "copy2", "1", "WITHFIELDS", "POINT"}, {"[[33 -115] [speed 10]]"},
+	})
+}
+
+func keys_MULTI_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "a", "STRING", "1"}, {"OK"},
+		{"MULTI"}, {"OK"},
+		{"SET", "mykey", "b", "STRING", "2"}, {"QUEUED"},
+		{"DEL", "mykey", "a"}, {"QUEUED"},
+		{"GET", "mykey", "b"}, {"QUEUED"},
+		{"GET", "mykey", "a"}, {"QUEUED"},
+		{"GET", "mykey"}, {"QUEUED"},
+		{"EXEC"}, {"[OK 1 2 nil ERR wrong number of arguments for 'get' command]"},
+		{"GET", "mykey", "a"}, {nil},
+		{"GET", "mykey", "b"}, {"2"},
+
+		{"MULTI"}, {"OK"},
+		{"MULTI"}, {"ERR MULTI calls can not be nested"},
+		{"SET", "mykey", "c", "STRING", "3"}, {"QUEUED"},
+		{"DISCARD"}, {"OK"},
+		{"GET", "mykey", "c"}, {nil},
+		{"EXEC"}, {"ERR EXEC without MULTI"},
+		{"DISCARD"}, {"ERR DISCARD without MULTI"},
+
+		{"MULTI"}, {"OK"},
+		{"SET", "mykey", "c", "STRING", "3"}, {"QUEUED"},
+		{"NEARBY", "mykey", "POINT", 33, -115}, {"ERR 'nearby' is not allowed in MULTI"},
+		{"EXEC"}, {"ERR transaction discarded because of previous errors"},
+		{"GET", "mykey", "c"}, {nil},
+
+		{"WATCH", "mykey", "b"}, {"OK"},
+		{"MULTI"}, {"OK"},
+		{"WATCH", "mykey", "b"}, {"ERR WATCH inside MULTI is not allowed"},
+		{"SET", "mykey", "b", "STRING", "3"}, {"QUEUED"},
+		{"EXEC"}, {"[OK]"},
+		{"GET", "mykey", "b"}, {"3"},
+		{"WATCH", "mykey", "b"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	// change the watched object from another connection
+	conn, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Do("FSET", "mykey", "b", "speed", 10); err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"MULTI"}, {"OK"},
+		{"SET", "mykey", "b", "STRING", "4"}, {"QUEUED"},
+		{"EXEC"}, {nil},
+		{"GET", "mykey", "b"}, {"3"},
+		{"WATCH", "mykey", "b"}, {"OK"},
+		{"UNWATCH"}, {"OK"},
+		{"MULTI"}, {"OK"},
+		{"SET", "mykey", "b", "STRING", "4"}, {"QUEUED"},
+		{"EXEC"}, {"[OK]"},
+		{"GET", "mykey", "b"}, {"4"},
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"MULTI"}, {`{"ok":true}`},
+		{"SET", "mykey", "b", "STRING", "5"}, {`{"ok":true,"queued":true}`},
+		{"GET", "mykey", "b"}, {`{"ok":true,"queued":true}`},
+	}); err != nil {
+		return err
+	}
+	res, err := redis.String(mc.Do("EXEC"))
+	if err != nil {
+		return err
+	}
+	if gjson.Get(res, "results.#").Int() != 2 ||
+		gjson.Get(res, "results.1.object").String() != "5" {
+		return fmt.Errorf("unexpected EXEC response '%s'", res)
+	}
+	if _, err = mc.Do("OUTPUT", "resp"); err != nil {
+		return err
+	}
+
+	// the writes of EXEC are notified together, after every command ran
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.PSubscribe("__keyspace__:fleet"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "notify-keyspace-events", "Kgo"}, {"OK"},
+		{"MULTI"}, {"OK"},
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"QUEUED"},
+		{"SET", "fleet", "truck2", "POINT", 33, -115}, {"QUEUED"},
+		{"DEL", "fleet", "truck3"}, {"QUEUED"},
+		{"PDEL", "fleet", "truck*"}, {"QUEUED"},
+		{"EXEC"}, {"[OK OK 0 2]"},
+		{"CONFIG", "SET", "notify-keyspace-events", ""}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	for _, expect := range []string{
+		`{"event":"set","key":"fleet","id":"truck1"}`,
+		`{"event":"set","key":"fleet","id":"truck2"}`,
+		`{"event":"del","key":"fleet","id":"truck1"}`,
+		`{"event":"del","key":"fleet","id":"truck2"}`,
+	} {
+		switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+		case redis.Message:
+			if string(v.Data) != expect {
+				return fmt.Errorf("expected '%s', got '%s'", expect, v.Data)
+			}
+		case error:
+			return v
+		}
+	}
+	return nil
+}
+
+func keys_IFNEWER_test(mc *mockServer) error {
+	return mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck", "FIELD", "ts", 100, "IFNEWER", "ts", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck", "FIELD", "ts", 90, "IFNEWER", "ts", "POINT", 34, -116}, {"SKIPPED"},
+		{"GET", "fleet", "truck", "WITHFIELDS", "POINT"}, {"[[33 -115] [ts 100]]"},
+		{"SET", "fleet", "truck", "FIELD", "ts", 100, "IFNEWER", "ts", "POINT", 34, -116}, {"SKIPPED"},
+		{"SET", "fleet", "truck", "FIELD", "ts", 110, "IFNEWER", "ts", "POINT", 34, -116}, {"OK"},
+		{"GET", "fleet", "truck", "WITHFIELDS", "POINT"}, {"[[34 -116] [ts 110]]"},
+		{"SET", "fleet", "truck", "IFNEWER", "ts", "POINT", 34, -116}, {"ERR invalid argument 'ts'"},
+		{"SET", "fleet", "truck", "IFGT", "ts", 105, "POINT", 35, -117}, {"SKIPPED"},
+		{"SET", "fleet", "truck", "IFGT", "ts", 120, "POINT", 35, -117}, {"OK"},
+		{"SET", "fleet", "truck", "IFGT", "ts", "x", "POINT", 35, -117}, {"ERR invalid argument 'x'"},
+		{"GET", "fleet", "truck", "WITHFIELDS", "POINT"}, {"[[35 -117] [ts 110]]"},
+
+		{"FSET", "fleet", "truck", "IF", "ts", "<", 110, "speed", 10}, {"SKIPPED"},
+		{"FSET", "fleet", "truck", "IF", "ts", "<=", 110, "speed", 10}, {1},
+		{"FSET", "fleet", "truck", "IF", "ts", "<", 200, "IF", "speed", "==", 0, "speed", 20}, {"SKIPPED"},
+		{"FSET", "fleet", "truck", "IF", "ts", "<", 200, "IF", "speed", "!=", 0, "speed", 20}, {1},
+		{"FSET", "fleet", "truck", "IF", "ts", "~", 200, "speed", 30}, {"ERR invalid argument '~'"},
+		{"FSET", "fleet", "truck", "IF", "ts", ">"}, {"ERR wrong number of arguments for 'fset' command"},
+		{"GET", "fleet", "truck", "WITHFIELDS", "POINT"}, {"[[35 -117] [speed 20 ts 110]]"},
+		{"FSET", "fleet", "bus", "XX", "IF", "ts", "<", 200, "speed", 20}, {0},
+
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"SET", "fleet", "truck", "FIELD", "ts", 1, "IFNEWER", "ts", "POINT", 33, -115}, {`{"ok":true,"skipped":true}`},
+		{"FSET", "fleet", "truck", "IF", "ts", ">", 200, "speed", 30}, {`{"ok":true,"skipped":true}`},
+		{"OUTPUT", "resp"}, {"OK"},
+		{"DROP", "fleet"}, {1},
+	})
+}
+
+func keys_NOTIFY_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "notify-keyspace-events", "gozK"}, {"ERR Invalid argument 'gozK' for CONFIG SET 'notify-keyspace-events'"},
+		{"CONFIG", "SET", "notify-keyspace-events", "KEgox"}, {"OK"},
+		{"CONFIG", "GET", "notify-keyspace-events"}, {"[notify-keyspace-events AKE]"},
+		{"CONFIG", "SET", "notify-keyspace-events", "Kgo"}, {"OK"},
+		{"CONFIG", "GET", "notify-keyspace-events"}, {"[notify-keyspace-events goK]"},
+	}); err != nil {
+		return err
+	}
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.PSubscribe("__keyspace__:*"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck2", "POINT", 33, -115}, {"OK"},
+		{"FSET", "fleet", "truck1", "speed", 10}, {1},
+		{"DEL", "fleet", "truck1"}, {1},
+		{"DEL", "fleet", "truck1"}, {0},
+		{"RENAME", "fleet", "trucks"}, {"OK"},
+		{"DROP", "trucks"}, {1},
+		{"CONFIG", "SET", "notify-keyspace-events", ""}, {"OK"},
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"DROP", "fleet"}, {1},
+	}); err != nil {
+		return err
+	}
+	expect := []string{
+		`__keyspace__:fleet {"event":"set","key":"fleet","id":"truck1"}`,
+		`__keyspace__:fleet {"event":"set","key":"fleet","id":"truck2"}`,
+		`__keyspace__:fleet {"event":"fset","key":"fleet","id":"truck1"}`,
+		`__keyspace__:fleet {"event":"del","key":"fleet","id":"truck1"}`,
+		`__keyspace__:fleet {"event":"rename","key":"fleet","new_key":"trucks"}`,
+		`__keyspace__:trucks {"event":"rename","key":"fleet","new_key":"trucks"}`,
+		`__keyspace__:trucks {"event":"drop","key":"trucks"}`,
+	}
+	for _, expect := range expect {
+		switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+		case redis.Message:
+			if s := v.Channel + " " + string(v.Data); s != expect {
+				return fmt.Errorf("expected '%s', got '%s'", expect, s)
+			}
+		case error:
+			return v
+		default:
+			return fmt.Errorf("unexpected %v", v)
+		}
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "notify-keyspace-events", "Kx"}, {"OK"},
+		{"SET", "fleet", "truck1", "EX", 0.5, "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	switch v := psc.ReceiveWithTimeout(time.Second * 5).(type) {
+	case redis.Message:
+		expect := `__keyspace__:fleet {"event":"expired","key":"fleet","id":"truck1"}`
+		if s := v.Channel + " " + string(v.Data); s != expect {
+			return fmt.Errorf("expected '%s', got '%s'", expect, s)
+		}
+	case error:
+		return v
+	default:
+		return fmt.Errorf("unexpected %v", v)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "notify-keyspace-events", ""}, {"OK"},
+	})
+}
+
+func keys_EVICT_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "GET", "maxmemory-policy"}, {"[maxmemory-policy noeviction]"},
+		{"CONFIG", "SET", "maxmemory-policy", "lru"}, {"ERR Invalid argument 'lru' for CONFIG SET 'maxmemory-policy'"},
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck2", "EX", 1000, "POINT", 33, -115}, {"OK"},
+		{"SET", "other", "truck3", "EX", 2000, "POINT", 33, -115}, {"OK"},
+		{"CONFIG", "SET", "notify-keyspace-events", "Kx"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	sc, err := redis.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+	psc := redis.PubSubConn{Conn: sc}
+	if err := psc.PSubscribe("__keyspace__:*"); err != nil {
+		return err
+	}
+	if _, ok := psc.Receive().(redis.Subscription); !ok {
+		return errors.New("expected subscription")
+	}
+	// expect receives evicted events, in any order
+	expect := func(events ...string) error {
+		want := make(map[string]bool)
+		for _, event := range events {
+			want[event] = true
+		}
+		for len(want) > 0 {
+			switch v := psc.ReceiveWithTimeout(time.Second * 10).(type) {
+			case redis.Message:
+				s := v.Channel + " " + string(v.Data)
+				if !want[s] {
+					return fmt.Errorf("unexpected '%s'", s)
+				}
+				delete(want, s)
+			case error:
+				return v
+			default:
+				return fmt.Errorf("unexpected %v", v)
+			}
+		}
+		return nil
+	}
+
+	// the heap is always over 1kb, so every object of the policy is evicted
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "maxmemory-policy", "volatile-ttl"}, {"OK"},
+		{"CONFIG", "SET", "maxmemory", "1kb"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if err := expect(
+		`__keyspace__:fleet {"event":"evicted","key":"fleet","id":"truck2"}`,
+		`__keyspace__:other {"event":"evicted","key":"other","id":"truck3"}`,
+	); err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"GET", "fleet", "truck1"}, {`{"type":"Point","coordinates":[-115,33]}`},
+		{"CONFIG", "SET", "maxmemory-policy", "allkeys-lru"}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	if err := expect(
+		`__keyspace__:fleet {"event":"evicted","key":"fleet","id":"truck1"}`,
+	); err != nil {
+		return err
+	}
+	info, err := redis.String(mc.Do("INFO", "stats"))
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(info, "evicted_keys:") ||
+		strings.Contains(info, "evicted_keys:0\r\n") {
+		return fmt.Errorf("expected evicted keys in '%s'", info)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "maxmemory", ""}, {"OK"},
+		{"CONFIG", "SET", "maxmemory-policy", "noeviction"}, {"OK"},
+		{"CONFIG", "SET", "notify-keyspace-events", ""}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	// wait for the server to leave the out of memory state
+	for start := time.Now(); ; {
+		_, err := mc.Do("SET", "fleet", "truck1", "POINT", 33, -115)
+		if err == nil {
+			return nil
+		}
+		if time.Since(start) > time.Second*10 {
+			return err
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+}
+
+func keys_KEYCONFIG_test(mc *mockServer) error {
+	zone := `{"type":"Polygon","coordinates":[[[-115,33],[-114,33],[-114,34],[-115,34],[-115,33]]]}`
+	return mc.DoBatch([][]interface{}{
+		{"KEYCONFIG", "fleet", "GET"}, {"[default-ttl 0 history off index  max-objects 0]"},
+		{"KEYCONFIG", "fleet", "SET", "versions", 10}, {"ERR Unsupported KEYCONFIG parameter: versions"},
+		{"KEYCONFIG", "fleet", "SET", "history", "yes"}, {"ERR Invalid argument 'yes' for KEYCONFIG SET 'history'"},
+		{"KEYCONFIG", "fleet", "SET", "index", "btree"}, {"ERR Invalid argument 'btree' for KEYCONFIG SET 'index'"},
+		{"KEYCONFIG", "fleet", "SET", "max-objects"}, {"ERR wrong number of arguments for 'keyconfig' command"},
+		{"KEYCONFIG", "fleet", "SET", "default-ttl", 3600, "max-objects", 2, "index", "RTree"}, {"OK"},
+		{"KEYCONFIG", "fleet", "GET"}, {"[default-ttl 3600 history off index rtree max-objects 2]"},
+
+		// objects without EX get the default ttl
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"TTL", "fleet", "truck1"}, {3599},
+		{"SET", "fleet", "truck2", "EX", 10, "POINT", 33, -115}, {"OK"},
+		{"TTL", "fleet", "truck2"}, {9},
+
+		// new objects are rejected over max-objects, but may be replaced
+		{"SET", "fleet", "truck3", "POINT", 33, -115}, {"ERR key has reached max-objects"},
+		{"JSET", "fleet", "truck3", "speed", 10}, {"ERR key has reached max-objects"},
+		{"SET", "fleet", "truck1", "POINT", 34, -115}, {"OK"},
+		{"STATS", "fleet"}, {"[[default_ttl 3600 geometry_index rtree in_memory_size 44 max_objects 2 num_objects 2 num_points 2 num_strings 0]]"},
+
+		// the config stays with the key
+		{"DEL", "fleet", "truck1"}, {1},
+		{"DEL", "fleet", "truck2"}, {1},
+		{"KEYCONFIG", "fleet", "GET"}, {"[default-ttl 3600 history off index rtree max-objects 2]"},
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"RENAME", "fleet", "trucks"}, {"OK"},
+		{"KEYCONFIG", "fleet", "GET"}, {"[default-ttl 0 history off index  max-objects 0]"},
+		{"KEYCONFIG", "trucks", "GET"}, {"[default-ttl 3600 history off index rtree max-objects 2]"},
+		{"KEYCONFIG", "trucks", "SET", "default-ttl", 0, "index", ""}, {"OK"},
+		{"KEYCONFIG", "trucks", "GET"}, {"[default-ttl 0 history off index  max-objects 2]"},
+		{"DROP", "trucks"}, {1},
+		{"KEYCONFIG", "trucks", "GET"}, {"[default-ttl 0 history off index  max-objects 0]"},
+
+		// changing the index of a key with objects indexes them again
+		{"SET", "zones", "z1", "FIELD", "speed", 5, "EX", 100, "OBJECT", zone}, {"OK"},
+		{"SET", "zones", "p1", "POINT", 33.5, -114.5}, {"OK"},
+		{"KEYCONFIG", "zones", "SET", "index", "none"}, {"OK"},
+		{"GET", "zones", "z1", "WITHFIELDS"}, {"[" + zone + " [speed 5]]"},
+		{"TTL", "zones", "z1"}, {99},
+		{"INTERSECTS", "zones", "IDS", "BOUNDS", 33.4, -114.6, 33.6, -114.4}, {"[0 [p1 z1]]"},
+		{"KEYCONFIG", "zones", "SET", "index", "rtree"}, {"OK"},
+		{"INTERSECTS", "zones", "IDS", "BOUNDS", 33.4, -114.6, 33.6, -114.4}, {"[0 [p1 z1]]"},
+		{"WITHIN", "zones", "IDS", "BOUNDS", 32, -116, 35, -113}, {"[0 [p1 z1]]"},
+	})
+}
+
+func keys_HISTORY_test(mc *mockServer) error {
+	p1 := `{"type":"Point","coordinates":[-115,33]}`
+	p2 := `{"type":"Point","coordinates":[-115,34]}`
+	p3 := `{"type":"Point","coordinates":[-115,35]}`
+	var cmds [][]interface{}
+	cmds = append(cmds, [][]interface{}{
+		{"HISTORY", "fleet", "truck1"}, {"ERR key has no history"},
+		{"KEYCONFIG", "fleet", "SET", "history", "on"}, {"OK"},
+		{"KEYCONFIG", "fleet", "GET"}, {"[default-ttl 0 history on index  max-objects 0]"},
+		{"HISTORY", "fleet", "truck1"}, {"[]"},
+
+		// replaced and deleted objects are kept, newest first
+		{"SET", "fleet", "truck1", "POINT", 33, -115}, {"OK"},
+		{"SET", "fleet", "truck1", "POINT", 34, -115}, {"OK"},
+		{"SET", "fleet", "truck1", "POINT", 35, -115}, {"OK"},
+		{"HISTORY", "fleet", "truck1"}, {"[" + p2 + " " + p1 + "]"},
+		{"JSET", "fleet", "truck2", "speed", 10}, {"OK"},
+		{"JSET", "fleet", "truck2", "speed", 20}, {"OK"},
+		{"JDEL", "fleet", "truck2", "speed"}, {1},
+		{"HISTORY", "fleet", "truck2"}, {`[{"speed":20} {"speed":10}]`},
+		{"DEL", "fleet", "truck1"}, {1},
+		{"HISTORY", "fleet", "truck1"}, {"[" + p3 + " " + p2 + " " + p1 + "]"},
+		{"STATS", "fleet"}, {"[[default_ttl 0 history on in_memory_size 8 max_objects 0 num_objects 1 num_points 0 num_strings 1]]"},
+		{"OUTPUT", "json"}, {`{"ok":true}`},
+		{"HISTORY", "fleet", "truck1"}, {`{"ok":true,"objects":[` + p3 + `,` + p2 + `,` + p1 + `]}`},
+		{"OUTPUT", "resp"}, {"OK"},
+
+		// the history moves with the key
+		{"RENAME", "fleet", "trucks"}, {"OK"},
+		{"HISTORY", "fleet", "truck1"}, {"ERR key has no history"},
+		{"HISTORY", "trucks", "truck1"}, {"[" + p3 + " " + p2 + " " + p1 + "]"},
+	}...)
+	// only the last 10 objects are kept
+	for i := 0; i < 12; i++ {
+		cmds = append(cmds, []interface{}{"SET", "trucks", "truck3", "STRING", i}, []interface{}{"OK"})
+	}
+	cmds = append(cmds, [][]interface{}{
+		{"HISTORY", "trucks", "truck3"}, {"[10 9 8 7 6 5 4 3 2 1]"},
+
+		// turning the history off forgets it
+		{"KEYCONFIG", "trucks", "SET", "history", "off"}, {"OK"},
+		{"KEYCONFIG", "trucks", "SET", "history", "on"}, {"OK"},
+		{"HISTORY", "trucks", "truck3"}, {"[]"},
+		{"DROP", "trucks"}, {1},
+		{"HISTORY", "trucks", "truck3"}, {"ERR key has no history"},
+	}...)
+	return mc.DoBatch(cmds)
+}
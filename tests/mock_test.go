@@ -7,6 +7,7 @@ import (
 	"log"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -32,7 +33,12 @@ func mockCleanup() {
 }
 
 type mockServer struct {
-	port int
+	port     int
+	dir      string
+	restPort int
+	grpcPort int
+	// unix domain socket path
+	unixSocket string
 	//join string
 	//n    *finn.Node
 	//m    *Machine
@@ -49,7 +55,11 @@ func mockOpenServer() (*mockServer, error) {
 		logOutput = os.Stderr
 	}
 	core.DevMode = true
-	s := &mockServer{port: port}
+	core.RESTPort = port + 1
+	core.GRPCPort = port + 2
+	core.UnixSocket = filepath.Join(dir, "tile38.sock")
+	s := &mockServer{port: port, dir: dir, restPort: core.RESTPort,
+		grpcPort: core.GRPCPort, unixSocket: core.UnixSocket}
 	tlog.SetOutput(logOutput)
 	go func() {
 		if err := server.Serve("localhost", port, dir, true); err != nil {
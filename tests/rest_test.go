@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func subTestREST(t *testing.T, mc *mockServer) {
+	runStep(t, mc, "objects", rest_objects_test)
+	runStep(t, mc, "search", rest_search_test)
+	runStep(t, mc, "errors", rest_errors_test)
+}
+
+// restDo sends a request to the REST API and returns the status code and the
+// response body with the "elapsed" member removed.
+func (mc *mockServer) restDo(method, path, body string) (int, string, error) {
+	req, err := http.NewRequest(method,
+		fmt.Sprintf("http://localhost:%d%s", mc.restPort, path),
+		strings.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	var resp *http.Response
+	for i := 0; ; i++ {
+		// the REST listener may still be starting up
+		resp, err = http.DefaultClient.Do(req)
+		if err == nil || i == 10 {
+			break
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", err
+	}
+	res := string(data)
+	if gjson.Get(res, "elapsed").Exists() {
+		res = strings.Replace(res, `,"elapsed":"`+
+			gjson.Get(res, "elapsed").String()+`"`, "", 1)
+	}
+	return resp.StatusCode, res, nil
+}
+
+// restBatch runs method, path, body, status, response tuples.
+func (mc *mockServer) restBatch(batch [][]interface{}) error {
+	for i, step := range batch {
+		status, res, err := mc.restDo(step[0].(string), step[1].(string),
+			step[2].(string))
+		if err != nil {
+			return fmt.Errorf("batch[%d]: %v", i, err)
+		}
+		if status != step[3].(int) || res != step[4].(string) {
+			return fmt.Errorf("batch[%d]: expected '%d %s', got '%d %s'",
+				i, step[3], step[4], status, res)
+		}
+	}
+	return nil
+}
+
+func rest_objects_test(mc *mockServer) error {
+	return mc.restBatch([][]interface{}{
+		{"PUT", "/keys/fleet/objects/truck1", `{"type":"Point","coordinates":[-115,33]}`,
+			200, `{"ok":true}`},
+		{"PUT", "/keys/fleet/objects/truck2", `{"point":[33.5,-115.5],"fields":{"speed":50}}`,
+			200, `{"ok":true}`},
+		{"PUT", "/keys/fleet/objects/truck3", `{"string":"hello","nx":true}`,
+			200, `{"ok":true}`},
+		{"GET", "/keys/fleet/objects/truck1", "",
+			200, `{"ok":true,"object":{"type":"Point","coordinates":[-115,33]}}`},
+		{"GET", "/keys/fleet/objects/truck2?withfields=true&output=point", "",
+			200, `{"ok":true,"point":{"lat":33.5,"lon":-115.5},"fields":{"speed":50}}`},
+		{"GET", "/keys/fleet/objects/truck3", "",
+			200, `{"ok":true,"object":"hello"}`},
+		{"GET", "/keys", "",
+			200, `{"ok":true,"keys":["fleet"]}`},
+		{"GET", "/keys/fleet/objects?output=ids", "",
+			200, `{"ok":true,"ids":["truck1","truck2","truck3"],"count":3,"cursor":0}`},
+		{"DELETE", "/keys/fleet/objects/truck3", "",
+			200, `{"ok":true}`},
+		{"GET", "/keys/fleet/objects/truck3", "",
+			404, `{"ok":false,"err":"id not found"}`},
+		{"GET", "/keys/nokey/objects/truck3", "",
+			404, `{"ok":false,"err":"key not found"}`},
+		{"PUT", "/keys/fleet/objects/truck%2F4", `{"hash":"9my5xp7"}`,
+			200, `{"ok":true}`},
+		{"GET", "/keys/fleet/objects/truck%2F4?output=hash&precision=7", "",
+			200, `{"ok":true,"hash":"9my5xp7"}`},
+		{"DELETE", "/keys/fleet", "",
+			200, `{"ok":true}`},
+		{"GET", "/keys", "",
+			200, `{"ok":true,"keys":[]}`},
+	})
+}
+
+func rest_search_test(mc *mockServer) error {
+	return mc.restBatch([][]interface{}{
+		{"PUT", "/keys/fleet/objects/truck1", `{"point":[33,-115],"fields":{"speed":10}}`,
+			200, `{"ok":true}`},
+		{"PUT", "/keys/fleet/objects/truck2", `{"point":[33.01,-115.01],"fields":{"speed":50}}`,
+			200, `{"ok":true}`},
+		{"PUT", "/keys/fleet/objects/truck3", `{"point":[34,-116]}`,
+			200, `{"ok":true}`},
+		{"POST", "/keys/fleet/search", `{"nearby":{"lat":33,"lon":-115,"meters":5000},"output":"ids"}`,
+			200, `{"ok":true,"ids":["truck1","truck2"],"count":2,"cursor":0}`},
+		{"POST", "/keys/fleet/search", `{"nearby":{"lat":33,"lon":-115,"meters":5000},"where":[{"field":"speed","min":20}],"output":"ids"}`,
+			200, `{"ok":true,"ids":["truck2"],"count":1,"cursor":0}`},
+		{"POST", "/keys/fleet/search", `{"within":{"bounds":[32.9,-115.1,33.1,-114.9]},"output":"count"}`,
+			200, `{"ok":true,"count":2,"cursor":0}`},
+		{"POST", "/keys/fleet/search", `{"intersects":{"type":"Polygon","coordinates":[[[-117,33.5],[-115.5,33.5],[-115.5,34.5],[-117,34.5],[-117,33.5]]]},"output":"ids"}`,
+			200, `{"ok":true,"ids":["truck3"],"count":1,"cursor":0}`},
+		{"POST", "/keys/fleet/search", `{"match":"truck[13]","output":"ids"}`,
+			200, `{"ok":true,"ids":["truck1","truck3"],"count":2,"cursor":0}`},
+		{"POST", "/keys/fleet/search", `{"within":{},"nearby":{}}`,
+			400, `{"ok":false,"err":"only one of nearby, within or intersects is allowed"}`},
+		{"POST", "/keys/fleet/search", `{"output":"shapes"}`,
+			400, `{"ok":false,"err":"invalid argument 'shapes'"}`},
+		{"DELETE", "/keys/fleet", "",
+			200, `{"ok":true}`},
+	})
+}
+
+func rest_errors_test(mc *mockServer) error {
+	if err := mc.restBatch([][]interface{}{
+		{"GET", "/nope", "",
+			404, `{"ok":false,"err":"not found"}`},
+		{"POST", "/keys/fleet/objects/truck1", `{}`,
+			405, `{"ok":false,"err":"method not allowed"}`},
+		{"PUT", "/keys/fleet/objects/truck1", `{"point":`,
+			400, `{"ok":false,"err":"invalid json"}`},
+		{"PUT", "/keys/fleet/objects/truck1", `{"fields":{"speed":10}}`,
+			400, `{"ok":false,"err":"missing object"}`},
+		{"PUT", "/keys/fleet/objects/truck1", `{"point":[33]}`,
+			400, `{"ok":false,"err":"invalid number of arguments"}`},
+	}); err != nil {
+		return err
+	}
+	// CORS preflight
+	req, err := http.NewRequest("OPTIONS",
+		fmt.Sprintf("http://localhost:%d/keys/fleet/search", mc.restPort), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 204 ||
+		resp.Header.Get("Access-Control-Allow-Origin") != "*" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Allow-Methods"), "PUT") {
+		return fmt.Errorf("unexpected preflight response %d %v",
+			resp.StatusCode, resp.Header)
+	}
+	return nil
+}
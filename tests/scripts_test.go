@@ -11,6 +11,8 @@ func subTestScripts(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "ATOMIC", scripts_ATOMIC_test)
 	runStep(t, mc, "READONLY", scripts_READONLY_test)
 	runStep(t, mc, "NONATOMIC", scripts_NONATOMIC_test)
+	runStep(t, mc, "GEO", scripts_GEO_test)
+	runStep(t, mc, "FUNCTION", scripts_FUNCTION_test)
 }
 
 func scripts_BASIC_test(mc *mockServer) error {
@@ -62,3 +64,87 @@ func scripts_NONATOMIC_test(mc *mockServer) error {
 		{"EVALNA", "return tile38.call('get', KEYS[1], ARGV[1], ARGV[2])", "1", "mykey", "myid1", "point"}, {"[33 -115]"},
 	})
 }
+
+func scripts_GEO_test(mc *mockServer) error {
+	poly := `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`
+	return mc.DoBatch([][]interface{}{
+		{"EVAL", "return tile38.geo.parse(ARGV[1])", 0, `{"type":"Point","coordinates":[1,2]}`}, {`{"type":"Point","coordinates":[1,2]}`},
+		{"EVAL", "return tile38.geo.parse('nope')", 0}, {
+			func(v interface{}) (resp, expect interface{}) {
+				if strings.Contains(fmt.Sprintf("%v", v), "invalid data") {
+					return v, v
+				}
+				return v, "A lua stack containing 'invalid data'"
+			},
+		},
+		{"EVAL", "return tile38.geo.contains(ARGV[1], {type='Point',coordinates={0.5,0.5}})", 0, poly}, {"1"},
+		{"EVAL", "return tile38.geo.contains(ARGV[1], {type='Point',coordinates={1.5,0.5}})", 0, poly}, {nil},
+		{"EVAL", "return tile38.geo.within(ARGV[2], ARGV[1])", 0, poly, `{"type":"Point","coordinates":[0.5,0.5]}`}, {"1"},
+		{"EVAL", "return tile38.geo.intersects(ARGV[1], ARGV[2])", 0, poly, `{"type":"LineString","coordinates":[[-1,0.5],[2,0.5]]}`}, {"1"},
+		{"EVAL", "return tile38.geo.distance(37.7341129, -122.4408378, 37.733, -122.43)", 0}, {"961"},
+		{"EVAL", "return tile38.geo.bearing(0, 0, 0, 1)", 0}, {"90"},
+		{"EVAL", "local lat, lon = tile38.geo.destination(0, 0, 111195, 0) return {math.floor(lat*1000+0.5), math.floor(lon*1000+0.5)}", 0}, {"[1000 0]"},
+		{"EVAL", "return math.floor(tile38.geo.area(ARGV[1])/1e6)", 0, poly}, {"12363"},
+		{"EVAL", "return {tile38.geo.bbox(ARGV[1])}", 0, `{"type":"LineString","coordinates":[[1,2],[3,4]]}`}, {"[2 1 4 3]"},
+		{"EVAL", "return {tile38.geo.center(ARGV[1])}", 0, `{"type":"LineString","coordinates":[[2,2],[4,4]]}`}, {"[3 3]"},
+		{"EVAL", "return tile38.geo.within(ARGV[1], tile38.geo.buffer(ARGV[2], 1000))", 0, `{"type":"Point","coordinates":[0.005,0]}`, `{"type":"Point","coordinates":[0,0]}`}, {"1"},
+		{"EVAL", "return tile38.geo.geohash_encode(33, -115, 7)", 0}, {"9my5xp7"},
+		{"EVAL", "local lat, lon = tile38.geo.geohash_decode('9my5xp7') return {math.floor(lat+0.5), math.floor(lon+0.5)}", 0}, {"[33 -115]"},
+		{"EVAL", "return tile38.geo.simplify(ARGV[1], 100)", 0, `{"type":"LineString","coordinates":[[0,0],[0.5,0.0001],[1,0],[1,1]]}`}, {`{"type":"LineString","coordinates":[[0,0],[1,0],[1,1]]}`},
+	})
+}
+
+func scripts_FUNCTION_test(mc *mockServer) error {
+	lib1 := "#!lua name=fleet\n" +
+		"tile38.register_function('park', function(keys, args)\n" +
+		"  return tile38.call('set', keys[1], args[1], 'point', 33, -115)\n" +
+		"end)\n" +
+		"tile38.register_function{function_name='where', flags={'no-writes'},\n" +
+		"  callback=function(keys, args)\n" +
+		"    return tile38.call('get', keys[1], args[1], 'point')\n" +
+		"  end}\n"
+	lib2 := "#!lua name=other\n" +
+		"tile38.register_function('park', function() return 1 end)\n"
+	lib3 := "#!lua name=other\n" +
+		"tile38.register_function('hello', function(keys, args) return 'hello ' .. args[1] end)\n"
+	// escape the newlines for the JSON payloads of DUMP and RESTORE
+	esc := func(s string) string { return strings.Replace(s, "\n", `\n`, -1) }
+	return mc.DoBatch([][]interface{}{
+		{"FUNCTION", "LOAD", lib1}, {"fleet"},
+		{"FUNCTION", "LOAD", lib1}, {"ERR library 'fleet' already exists"},
+		{"FUNCTION", "LOAD", "REPLACE", lib1}, {"fleet"},
+		{"FUNCTION", "LOAD", lib2}, {"ERR function 'park' already exists"},
+		{"FUNCTION", "LOAD", "return 1"}, {"ERR missing library metadata"},
+		{"FUNCTION", "LOAD", "#!lua name=empty\nlocal x = 1"}, {"ERR no functions registered"},
+		{"FUNCTION", "LOAD", lib3}, {"other"},
+		{"FUNCTION", "LIST"}, {"[[library_name fleet functions [[name park flags []] [name where flags [no-writes]]]] [library_name other functions [[name hello flags []]]]]"},
+		{"FUNCTION", "LIST", "LIBRARYNAME", "oth*"}, {"[[library_name other functions [[name hello flags []]]]]"},
+		{"FCALL", "park", 1, "fleet", "truck1"}, {"OK"},
+		{"FCALL", "where", 1, "fleet", "truck1"}, {"[33 -115]"},
+		{"FCALL_RO", "where", 1, "fleet", "truck1"}, {"[33 -115]"},
+		{"FCALL_RO", "park", 1, "fleet", "truck2"}, {"ERR can not call a function that may write with FCALL_RO"},
+		{"FCALL", "hello", 0, "world"}, {"hello world"},
+		{"FCALL", "nope", 0}, {"ERR function not found"},
+		{"EVAL", "return tile38.register_function('x', function() end)", 0}, {
+			func(v interface{}) (resp, expect interface{}) {
+				s := fmt.Sprintf("%v", v)
+				if strings.Contains(s, "register_function can only be called when loading a library") {
+					return v, v
+				}
+				return v, "A lua stack containing 'register_function can only be called when loading a library'"
+			},
+		},
+		{"FUNCTION", "DUMP"}, {`["` + esc(lib1) + `","` + esc(lib3) + `"]`},
+		{"FUNCTION", "DELETE", "other"}, {"OK"},
+		{"FUNCTION", "DELETE", "other"}, {"ERR library not found"},
+		{"FCALL", "hello", 0, "world"}, {"ERR function not found"},
+		{"FUNCTION", "RESTORE", `["` + esc(lib3) + `"]`}, {"OK"},
+		{"FCALL", "hello", 0, "again"}, {"hello again"},
+		{"FUNCTION", "RESTORE", `["` + esc(lib3) + `"]`}, {"ERR library 'other' already exists"},
+		{"FUNCTION", "RESTORE", `["` + esc(lib3) + `"]`, "FLUSH"}, {"OK"},
+		{"FCALL", "park", 1, "fleet", "truck1"}, {"ERR function not found"},
+		{"FUNCTION", "FLUSH"}, {"OK"},
+		{"FUNCTION", "LIST"}, {"[]"},
+		{"DROP", "fleet"}, {"1"},
+	})
+}
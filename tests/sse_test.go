@@ -0,0 +1,255 @@
+package tests
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+func subTestSSE(t *testing.T, mc *mockServer) {
+	runStep(t, mc, "fence", sse_fence_test)
+	runStep(t, mc, "subscribe", sse_subscribe_test)
+	runStep(t, mc, "resume", sse_resume_test)
+}
+
+type sseEvent struct {
+	id   string
+	data string
+}
+
+type sseReader struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// dialSSE sends the command as an HTTP request that accepts an event stream
+// and reads the response header.
+func dialSSE(mc *mockServer, lastEventID string, args ...string) (*sseReader, error) {
+	conn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return nil, err
+	}
+	req := "GET /" + url.PathEscape(strings.Join(args, " ")) + " HTTP/1.1\r\n" +
+		"Accept: text/event-stream\r\n"
+	if lastEventID != "" {
+		req += "Last-Event-ID: " + lastEventID + "\r\n"
+	}
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	sr := &sseReader{conn: conn, rd: bufio.NewReader(conn)}
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	var header []string
+	for {
+		line, err := sr.rd.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		header = append(header, line)
+	}
+	if len(header) == 0 || header[0] != "HTTP/1.1 200 OK" {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected header '%v'", header)
+	}
+	var stream bool
+	for _, line := range header[1:] {
+		if line == "Content-Type: text/event-stream" {
+			stream = true
+		}
+	}
+	if !stream {
+		conn.Close()
+		return nil, errors.New("expected an event stream")
+	}
+	return sr, nil
+}
+
+func (sr *sseReader) receive() (sseEvent, error) {
+	var ev sseEvent
+	var data []string
+	sr.conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for {
+		line, err := sr.rd.ReadString('\n')
+		if err != nil {
+			return ev, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if data == nil {
+				continue
+			}
+			ev.data = strings.Join(data, "\n")
+			return ev, nil
+		case strings.HasPrefix(line, ":"):
+			// heartbeat
+		case strings.HasPrefix(line, "id: "):
+			ev.id = line[4:]
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, line[6:])
+		}
+	}
+}
+
+func (sr *sseReader) Close() error {
+	return sr.conn.Close()
+}
+
+func sse_fence_test(mc *mockServer) error {
+	sr, err := dialSSE(mc, "", "NEARBY", "ssefleet", "FENCE",
+		"POINT", "33", "-115", "10000")
+	if err != nil {
+		return err
+	}
+	defer sr.Close()
+	ev, err := sr.receive()
+	if err != nil {
+		return err
+	}
+	if ev.data != `{"ok":true,"live":true}` {
+		return fmt.Errorf("expected live, got '%s'", ev.data)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "ssefleet", "truck", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	ev, err = sr.receive()
+	if err != nil {
+		return err
+	}
+	if gjson.Get(ev.data, "id").String() != "truck" ||
+		gjson.Get(ev.data, "detect").String() != "enter" {
+		return fmt.Errorf("unexpected event '%s'", ev.data)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DROP", "ssefleet"}, {1},
+	})
+}
+
+func sse_subscribe_test(mc *mockServer) error {
+	sr, err := dialSSE(mc, "", "SUBSCRIBE", "ssechan")
+	if err != nil {
+		return err
+	}
+	defer sr.Close()
+	ev, err := sr.receive()
+	if err != nil {
+		return err
+	}
+	if gjson.Get(ev.data, "command").String() != "subscribe" ||
+		gjson.Get(ev.data, "channel").String() != "ssechan" {
+		return fmt.Errorf("unexpected event '%s'", ev.data)
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"PUBLISH", "ssechan", "hello\nworld"}, {1},
+	}); err != nil {
+		return err
+	}
+	ev, err = sr.receive()
+	if err != nil {
+		return err
+	}
+	if ev.id != "" || ev.data != `"hello\nworld"` {
+		return fmt.Errorf("unexpected event '%v'", ev)
+	}
+	return nil
+}
+
+func sse_resume_test(mc *mockServer) error {
+	if err := mc.DoBatch([][]interface{}{
+		{"SETCHAN", "ssegeo", "NEARBY", "ssefleet", "FENCE", "DETECT", "enter",
+			"POINT", 33, -115, 10000}, {"1"},
+	}); err != nil {
+		return err
+	}
+	sr, err := dialSSE(mc, "", "SUBSCRIBE", "ssegeo")
+	if err != nil {
+		return err
+	}
+	defer sr.Close()
+	if _, err := sr.receive(); err != nil {
+		return err
+	}
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "ssefleet", "truck1", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	ev, err := sr.receive()
+	if err != nil {
+		return err
+	}
+	if ev.id == "" || gjson.Get(ev.data, "id").String() != "truck1" {
+		return fmt.Errorf("unexpected event '%v'", ev)
+	}
+	lastID, err := strconv.ParseUint(ev.id, 10, 64)
+	if err != nil {
+		return err
+	}
+	sr.Close()
+
+	// missed while disconnected
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "ssefleet", "truck2", "POINT", 33, -115}, {"OK"},
+		{"SET", "ssefleet", "truck3", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	sr, err = dialSSE(mc, strconv.FormatUint(lastID, 10), "SUBSCRIBE", "ssegeo")
+	if err != nil {
+		return err
+	}
+	defer sr.Close()
+	if _, err := sr.receive(); err != nil {
+		return err
+	}
+	var ids []string
+	for i := 0; i < 2; i++ {
+		ev, err := sr.receive()
+		if err != nil {
+			return err
+		}
+		id, _ := strconv.ParseUint(ev.id, 10, 64)
+		if id <= lastID {
+			return fmt.Errorf("expected an id after %d, got '%s'", lastID, ev.id)
+		}
+		ids = append(ids, gjson.Get(ev.data, "id").String())
+	}
+	if strings.Join(ids, ",") != "truck2,truck3" {
+		return fmt.Errorf("expected 'truck2,truck3', got '%s'",
+			strings.Join(ids, ","))
+	}
+
+	// live messages follow the missed ones
+	if err := mc.DoBatch([][]interface{}{
+		{"SET", "ssefleet", "truck4", "POINT", 33, -115}, {"OK"},
+	}); err != nil {
+		return err
+	}
+	ev, err = sr.receive()
+	if err != nil {
+		return err
+	}
+	if gjson.Get(ev.data, "id").String() != "truck4" {
+		return fmt.Errorf("unexpected event '%v'", ev)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"DELCHAN", "ssegeo"}, {1},
+		{"DROP", "ssefleet"}, {1},
+	})
+}
@@ -10,6 +10,7 @@ func subTestTestCmd(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "INTERSECTS_CLIP", testcmd_INTERSECTS_CLIP_test)
 	runStep(t, mc, "ExpressionErrors", testcmd_expressionErrors_test)
 	runStep(t, mc, "Expressions", testcmd_expression_test)
+	runStep(t, mc, "BUFFER", testcmd_BUFFER_test)
 }
 
 func testcmd_WITHIN_test(mc *mockServer) error {
@@ -211,3 +212,15 @@ func testcmd_expression_test(mc *mockServer) error {
 		{"TEST", "OBJECT", poly9, "WITHIN", "NOT", "GET", "mykey", "line3"}, {"1"},
 	})
 }
+
+func testcmd_BUFFER_test(mc *mockServer) error {
+	line := `{"type":"LineString","coordinates":[[-115,33],[-114.99,33]]}`
+	return mc.DoBatch([][]interface{}{
+		{"SET", "mykey", "line1", "OBJECT", line}, {"OK"},
+		{"TEST", "POINT", 33.001, -114.995, "WITHIN", "OBJECT", line, "BUFFER", 200}, {"1"},
+		{"TEST", "POINT", 33.003, -114.995, "WITHIN", "OBJECT", line, "BUFFER", 200}, {"0"},
+		{"TEST", "POINT", 33.001, -114.995, "INTERSECTS", "GET", "mykey", "line1", "BUFFER", 200}, {"1"},
+		{"TEST", "POINT", 33.001, -114.995, "BUFFER", 150, "INTERSECTS", "OBJECT", line}, {"1"},
+		{"TEST", "POINT", 33.001, -114.995, "BUFFER", 150, "WITHIN", "OBJECT", line}, {"0"},
+	})
+}
@@ -48,6 +48,9 @@ func TestAll(t *testing.T) {
 	runSubTest(t, "info", mc, subTestInfo)
 	runSubTest(t, "client", mc, subTestClient)
 	runSubTest(t, "timeouts", mc, subTestTimeout)
+	runSubTest(t, "rest", mc, subTestREST)
+	runSubTest(t, "grpc", mc, subTestGRPC)
+	runSubTest(t, "sse", mc, subTestSSE)
 }
 
 func runSubTest(t *testing.T, name string, mc *mockServer, test func(t *testing.T, mc *mockServer)) {
@@ -3,6 +3,7 @@ package tests
 import (
 	"fmt"
 	"math/rand"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +14,8 @@ import (
 func subTestTimeout(t *testing.T, mc *mockServer) {
 	runStep(t, mc, "spatial", timeout_spatial_test)
 	runStep(t, mc, "search", timeout_search_test)
+	runStep(t, mc, "default", timeout_default_test)
+	runStep(t, mc, "disconnect", timeout_disconnect_test)
 	runStep(t, mc, "scripts", timeout_scripts_test)
 	runStep(t, mc, "no writes", timeout_no_writes_test)
 	runStep(t, mc, "within scripts", timeout_within_scripts_test)
@@ -77,6 +80,131 @@ func timeout_search_test(mc *mockServer) (err error) {
 	})
 }
 
+func timeout_default_test(mc *mockServer) (err error) {
+	if err := setup(mc, 10000, false); err != nil {
+		return err
+	}
+	return mc.DoBatch([][]interface{}{
+		{"CONFIG", "SET", "default-timeout", "-1"}, {"ERR Invalid argument '-1' for CONFIG SET 'default-timeout'"},
+		{"CONFIG", "SET", "default-timeout", "0.000001"}, {"OK"},
+		{"CONFIG", "GET", "default-timeout"}, {"[default-timeout 0.000001]"},
+		{"SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"ERR timeout"},
+		{"CLIENT", "SETTIMEOUT", "10"}, {"OK"},
+		{"SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"10000"},
+		{"TIMEOUT", "0.000001", "SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"ERR timeout"},
+		{"CLIENT", "SETTIMEOUT", "0"}, {"OK"},
+		{"SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"ERR timeout"},
+		{"CONFIG", "SET", "default-timeout", "0"}, {"OK"},
+		{"SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"10000"},
+	})
+}
+
+// startSlowScan sends a SCAN that runs for about loop*10000 iterations of a
+// script on a new connection, and waits until the server is running it.
+func startSlowScan(mc *mockServer, name string, loop int) (*net.TCPConn, error) {
+	nconn, err := net.Dial("tcp", fmt.Sprintf(":%d", mc.port))
+	if err != nil {
+		return nil, err
+	}
+	tcp := nconn.(*net.TCPConn)
+	conn := redis.NewConn(tcp, 0, 0)
+	if _, err := conn.Do("CLIENT", "SETNAME", name); err != nil {
+		tcp.Close()
+		return nil, err
+	}
+	if err := conn.Send("SCAN", "mykey", "LIMIT", 100000, "WHEREEVAL",
+		fmt.Sprintf("local i = 0 while i < %d do i = i + 1 end return true", loop),
+		0, "IDS"); err != nil {
+		tcp.Close()
+		return nil, err
+	}
+	if err := conn.Flush(); err != nil {
+		tcp.Close()
+		return nil, err
+	}
+	start := time.Now()
+	for {
+		line, err := clientLine(mc, name)
+		if err != nil {
+			tcp.Close()
+			return nil, err
+		}
+		if strings.Contains(line, "cmd=scan ") {
+			return tcp, nil
+		}
+		if time.Since(start) > time.Second*5 {
+			tcp.Close()
+			return nil, fmt.Errorf("expected a running scan, got '%s'", line)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// clientLine returns the CLIENT LIST line of a named client.
+func clientLine(mc *mockServer, name string) (string, error) {
+	list, err := redis.String(mc.Do("CLIENT", "LIST"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(list, "\n") {
+		if strings.Contains(line, "name="+name+" ") {
+			return line, nil
+		}
+	}
+	return "", nil
+}
+
+func timeout_disconnect_test(mc *mockServer) (err error) {
+	if err := setup(mc, 10000, false); err != nil {
+		return err
+	}
+
+	// a search that would run for minutes is canceled by a reset
+	tcp, err := startSlowScan(mc, "slowscan", 100000)
+	if err != nil {
+		return err
+	}
+	tcp.SetLinger(0)
+	tcp.Close()
+	start := time.Now()
+	for {
+		line, err := clientLine(mc, "slowscan")
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			break
+		}
+		if time.Since(start) > time.Second*5 {
+			return fmt.Errorf("expected the scan to be canceled, got '%s'", line)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// a half-closed client still gets the whole reply
+	tcp, err = startSlowScan(mc, "halfclosed", 100)
+	if err != nil {
+		return err
+	}
+	defer tcp.Close()
+	if err := tcp.CloseWrite(); err != nil {
+		return err
+	}
+	ids, err := redis.Values(redis.NewConn(tcp, 0, 0).Receive())
+	if err != nil {
+		return err
+	}
+	if len(ids) != 2 {
+		return fmt.Errorf("expected a cursor and ids, got %d values", len(ids))
+	}
+	if n := len(ids[1].([]interface{})); n != 10000 {
+		return fmt.Errorf("expected 10000 ids, got %d", n)
+	}
+	return mc.DoBatch([][]interface{}{
+		{"SEARCH", "mykey", "MATCH", "val:*", "COUNT"}, {"10000"},
+	})
+}
+
 func timeout_scripts_test(mc *mockServer) (err error) {
 	script := `
 		local clock = os.clock